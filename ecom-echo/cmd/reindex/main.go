@@ -0,0 +1,38 @@
+// cmd/reindex reconstruye el índice de búsqueda del catálogo desde la base de datos.
+// Útil tras restaurar una copia de la base de datos o cambiar el archivo de sinónimos.
+// La API debe estar detenida: mantiene el índice en memoria y al guardarlo sobrescribiría
+// la reconstrucción. Con la API en marcha se usa POST /api/v1/search/rebuild
+package main
+
+import (
+	"context"
+	"ecom/config"
+	"ecom/internal/di"
+	"ecom/internal/shared/money"
+	"ecom/internal/shared/searchindex"
+	"errors"
+	"log"
+)
+
+func main() {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatal("Error al cargar la configuración: ", err)
+	}
+	money.SetDefaultRounding(cfg.Money.Rounding)
+
+	container, err := di.NewContainer(cfg)
+	if errors.Is(err, searchindex.ErrIndexLocked) {
+		log.Fatal("El índice de búsqueda está en uso: detén la API o usa POST /api/v1/search/rebuild")
+	}
+	if err != nil {
+		log.Fatal("Error al inicializar el contenedor: ", err)
+	}
+	defer container.Cleanup()
+
+	result, err := container.SearchService().Rebuild(context.Background())
+	if err != nil {
+		log.Fatal("Error al reconstruir el índice de búsqueda: ", err)
+	}
+	log.Printf("Índice de búsqueda reconstruido: %d productos en %s", result.Indexed, result.Duration)
+}
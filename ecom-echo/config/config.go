@@ -1,8 +1,10 @@
 package config
 
 import (
+	"ecom/internal/shared/money"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -18,6 +20,26 @@ type Config struct {
 		Password string
 		Name     string
 	}
+	Money struct {
+		Rounding money.RoundingMode
+	}
+	ExchangeRates struct {
+		Provider        string        // ecb | csv; vacío desactiva la actualización automática
+		Source          string        // URL o ruta local de la fuente
+		RefreshInterval time.Duration
+		MaxChange       money.Decimal // Variación máxima aceptada en porcentaje
+	}
+	Search struct {
+		IndexPath    string // Directorio del índice local
+		SynonymsFile string // Archivo opcional de sinónimos
+	}
+	Publication struct {
+		SchedulerInterval time.Duration // 0 desactiva la publicación programada
+	}
+	Reservations struct {
+		TTL             time.Duration // Tiempo que una línea de carrito retiene el stock
+		SweeperInterval time.Duration // 0 desactiva la liberación periódica de reservas caducadas
+	}
 }
 
 func LoadConfig() (*Config, error) {
@@ -41,6 +63,68 @@ func LoadConfig() (*Config, error) {
 	config.Database.Password = os.Getenv("DB_PASSWORD")
 	config.Database.Name = os.Getenv("DB_NAME")
 
+	// Money
+	rounding, err := money.ParseRoundingMode(os.Getenv("MONEY_ROUNDING"))
+	if err != nil {
+		return nil, err
+	}
+	config.Money.Rounding = rounding
+
+	// Exchange rates
+	config.ExchangeRates.Provider = os.Getenv("EXCHANGE_RATES_PROVIDER")
+	config.ExchangeRates.Source = os.Getenv("EXCHANGE_RATES_SOURCE")
+	config.ExchangeRates.RefreshInterval = 24 * time.Hour
+	if v := os.Getenv("EXCHANGE_RATES_INTERVAL"); v != "" {
+		interval, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("EXCHANGE_RATES_INTERVAL is invalid: %w", err)
+		}
+		config.ExchangeRates.RefreshInterval = interval
+	}
+	config.ExchangeRates.MaxChange = money.NewFromInt(10)
+	if v := os.Getenv("EXCHANGE_RATES_MAX_CHANGE"); v != "" {
+		maxChange, err := money.NewFromString(v)
+		if err != nil {
+			return nil, fmt.Errorf("EXCHANGE_RATES_MAX_CHANGE is invalid: %w", err)
+		}
+		config.ExchangeRates.MaxChange = maxChange
+	}
+
+	// Search
+	config.Search.IndexPath = os.Getenv("SEARCH_INDEX_PATH")
+	if config.Search.IndexPath == "" {
+		config.Search.IndexPath = "./data/search"
+	}
+	config.Search.SynonymsFile = os.Getenv("SEARCH_SYNONYMS_FILE")
+
+	// Publication
+	config.Publication.SchedulerInterval = time.Minute
+	if v := os.Getenv("PUBLICATION_SCHEDULER_INTERVAL"); v != "" {
+		interval, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("PUBLICATION_SCHEDULER_INTERVAL is invalid: %w", err)
+		}
+		config.Publication.SchedulerInterval = interval
+	}
+
+	// Reservations
+	config.Reservations.TTL = 15 * time.Minute
+	if v := os.Getenv("RESERVATION_TTL"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil || ttl <= 0 {
+			return nil, fmt.Errorf("RESERVATION_TTL is invalid: %q", v)
+		}
+		config.Reservations.TTL = ttl
+	}
+	config.Reservations.SweeperInterval = time.Minute
+	if v := os.Getenv("RESERVATION_SWEEPER_INTERVAL"); v != "" {
+		interval, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("RESERVATION_SWEEPER_INTERVAL is invalid: %w", err)
+		}
+		config.Reservations.SweeperInterval = interval
+	}
+
 	// Validaciones
 	if config.Database.Host == "" {
 		return nil, fmt.Errorf("DB_HOST is required")
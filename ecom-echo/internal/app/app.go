@@ -2,10 +2,12 @@
 package app
 
 import (
+	"context"
 	"ecom/config"
 	"ecom/internal/di"
 	"ecom/internal/migrations"
 	"ecom/internal/server"
+	"ecom/internal/shared/money"
 	"fmt"
 	"log"
 )
@@ -28,6 +30,9 @@ func (a *App) Initialize() error {
     }
     a.config = cfg
 
+    // Redondeo de importes (half_up, half_even, up, down)
+    money.SetDefaultRounding(cfg.Money.Rounding)
+
     // Inicializar contenedor de dependencias
     container, err := di.NewContainer(cfg)
     if err != nil {
@@ -49,6 +54,10 @@ func (a *App) Initialize() error {
 }
 
 func (a *App) Start() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a.container.StartBackgroundJobs(ctx)
+
 	defer func() {
 		if err := a.container.Cleanup(); err != nil {
 			log.Printf("Error during cleanup: %v", err)
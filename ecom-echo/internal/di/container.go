@@ -2,23 +2,33 @@
 package di
 
 import (
+	"context"
 	"database/sql"
 	"ecom/config"
 	"ecom/internal/shared/database"
+	"ecom/internal/shared/searchindex"
+	"ecom/internal/shared/slug"
 	attributeValue "ecom/internal/use_cases/attributeValue"
+	"ecom/internal/use_cases/catalog"
 	"ecom/internal/use_cases/category"
 	currencies "ecom/internal/use_cases/currencies"
 	customer "ecom/internal/use_cases/customer"
+	"ecom/internal/use_cases/inventory"
 	"ecom/internal/use_cases/media"
 	prices "ecom/internal/use_cases/prices"
 	product "ecom/internal/use_cases/product"
 	productattribute "ecom/internal/use_cases/productAttribute"
+	"ecom/internal/use_cases/reservation"
+	"ecom/internal/use_cases/resolver"
+	"ecom/internal/use_cases/review"
+	search "ecom/internal/use_cases/search"
 	"ecom/internal/use_cases/tag"
 	taxcategory "ecom/internal/use_cases/taxes/taxCategory"
 	taxrate "ecom/internal/use_cases/taxes/taxRate"
 	taxrule "ecom/internal/use_cases/taxes/taxRule"
 	taxzone "ecom/internal/use_cases/taxes/taxZone"
 	"ecom/internal/use_cases/zone"
+	"log"
 	// otros imports
 )
 
@@ -40,6 +50,11 @@ type Container struct {
     pricesRepo prices.Repository
     customerRepo customer.Repository
     currenciesRepo currencies.Repository
+    catalogRepo catalog.Repository
+    resolverRepo resolver.Repository
+    reviewRepo review.Repository
+    inventoryRepo inventory.Repository
+    reservationRepo reservation.Repository
     // Services
     mediaService *media.Service
     tagService *tag.Service
@@ -55,6 +70,18 @@ type Container struct {
     pricesService *prices.Service
     customerService *customer.Service
     currenciesService *currencies.Service
+    searchIndex searchindex.SearchIndex
+    searchService *search.Service
+    catalogService *catalog.Service
+    resolverService *resolver.Service
+    reviewService *review.Service
+    inventoryService *inventory.Service
+    reservationService *reservation.Service
+
+    // Background jobs
+    rateRefresher *currencies.RateRefresher
+    publicationScheduler *product.PublicationScheduler
+    reservationSweeper *reservation.Sweeper
 }
 
 func NewContainer(cfg *config.Config) (*Container, error) {
@@ -68,7 +95,7 @@ func NewContainer(cfg *config.Config) (*Container, error) {
         return nil, err
     }
     
-    if err := c.initServices(); err != nil {
+    if err := c.initServices(cfg); err != nil {
         return nil, err
     }
     
@@ -87,12 +114,25 @@ func (c *Container) initDB(cfg *config.Config) error {
 
 // Cleanup método para cerrar conexiones
 func (c *Container) Cleanup() error {
+    if c.searchIndex != nil {
+        if err := c.searchIndex.Close(); err != nil {
+            log.Printf("Error al guardar el índice de búsqueda: %v", err)
+        }
+    }
     if c.db != nil {
         return c.db.Close()
     }
     return nil
 }
 
+// StartBackgroundJobs lanza las tareas periódicas; se detienen al cancelar el contexto
+func (c *Container) StartBackgroundJobs(ctx context.Context) {
+    c.rateRefresher.Start(ctx)
+    c.publicationScheduler.Start(ctx)
+    c.reservationSweeper.Start(ctx)
+    c.searchService.RebuildIfEmpty(ctx)
+}
+
 // Agregar getter para DB
 func (c *Container) DB() *sql.DB {
     return c.db
@@ -113,16 +153,44 @@ func (c *Container) initRepositories() error {
     c.pricesRepo = prices.NewMySQLRepository(c.db)
     c.customerRepo = customer.NewMySQLRepository(c.db)
     c.currenciesRepo = currencies.NewMySQLRepository(c.db)
+    c.catalogRepo = catalog.NewMySQLRepository(c.db)
+    c.resolverRepo = resolver.NewMySQLRepository(c.db)
+    c.reviewRepo = review.NewMySQLRepository(c.db)
+    c.inventoryRepo = inventory.NewMySQLRepository(c.db)
+    c.reservationRepo = reservation.NewMySQLRepository(c.db)
     return nil
 }
 
-func (c *Container) initServices() error {
+func (c *Container) initServices(cfg *config.Config) error {
     uploadStrategy := media.NewLocalUploadStrategy("./uploads")
     
     // Inicializa el servicio de medios con el repositorio y la estrategia de subida
     c.mediaService = media.NewService(c.mediaRepo, uploadStrategy)
-    c.tagService = tag.NewService(c.tagRepo)
-    c.categoryService = category.NewService(c.categoryRepo)
+
+    // Índice de búsqueda local; se actualiza con los cambios de productos, categorías y tags
+    var synonyms map[string][]string
+    if cfg.Search.SynonymsFile != "" {
+        loaded, err := searchindex.LoadSynonyms(cfg.Search.SynonymsFile)
+        if err != nil {
+            return err
+        }
+        synonyms = loaded
+    }
+    index, err := searchindex.OpenDiskIndex(cfg.Search.IndexPath, searchindex.NewSpanishAnalyzer(synonyms))
+    if err != nil {
+        return err
+    }
+    c.searchIndex = index
+    c.searchService = search.NewService(index, c.productRepo, c.categoryRepo)
+
+    // Productos y categorías comparten los slugs que resuelve /resolve/:slug
+    slugs := slug.NewNamespace(map[string]slug.Lister{
+        slug.EntityProduct:  c.productRepo,
+        slug.EntityCategory: c.categoryRepo,
+    })
+
+    c.tagService = tag.NewService(c.tagRepo, c.searchService)
+    c.categoryService = category.NewService(c.categoryRepo, slugs, c.searchService)
     c.productattributeService = productattribute.NewService(c.productattributeRepo)
     c.attributeValueService = attributeValue.NewService(c.attributeValueRepo)
     c.zoneService = zone.NewService(c.zoneRepo)
@@ -130,10 +198,28 @@ func (c *Container) initServices() error {
     c.taxzoneService = taxzone.NewService(c.taxzoneRepo)
     c.taxrateService = taxrate.NewService(c.taxrateRepo)
     c.taxruleService = taxrule.NewService(c.taxruleRepo)
-    c.productService = product.NewService(c.productRepo, c.taxcategoryService)
-    c.pricesService = prices.NewService(c.pricesRepo)
     c.customerService = customer.NewService(c.customerRepo)
-    c.currenciesService = currencies.NewService(c.currenciesRepo)
+
+    // Proveedor externo de tipos de cambio (opcional)
+    var rateProvider currencies.ExchangeRateProvider
+    if cfg.ExchangeRates.Provider != "" {
+        provider, err := currencies.NewExchangeRateProvider(cfg.ExchangeRates.Provider, cfg.ExchangeRates.Source)
+        if err != nil {
+            return err
+        }
+        rateProvider = provider
+    }
+    c.currenciesService = currencies.NewService(c.currenciesRepo, rateProvider, cfg.ExchangeRates.MaxChange)
+    c.rateRefresher = currencies.NewRateRefresher(c.currenciesService, cfg.ExchangeRates.RefreshInterval)
+    c.pricesService = prices.NewService(c.pricesRepo, c.currenciesService)
+    c.productService = product.NewService(c.productRepo, c.taxcategoryService, c.pricesService, c.currenciesService, slugs, c.searchService)
+    c.publicationScheduler = product.NewPublicationScheduler(c.productService, cfg.Publication.SchedulerInterval)
+    c.catalogService = catalog.NewService(c.catalogRepo, c.searchService)
+    c.resolverService = resolver.NewService(c.resolverRepo, c.productService, c.categoryService)
+    c.reviewService = review.NewService(c.reviewRepo)
+    c.inventoryService = inventory.NewService(c.inventoryRepo, c.searchService)
+    c.reservationService = reservation.NewService(c.reservationRepo, cfg.Reservations.TTL, c.searchService)
+    c.reservationSweeper = reservation.NewSweeper(c.reservationService, cfg.Reservations.SweeperInterval)
     return nil
 }
 
@@ -184,6 +270,24 @@ func (c *Container) CustomerService() *customer.Service {
 func (c *Container) CurrencyService() *currencies.Service {
     return c.currenciesService
 }
+func (c *Container) SearchService() *search.Service {
+    return c.searchService
+}
+func (c *Container) CatalogService() *catalog.Service {
+    return c.catalogService
+}
+func (c *Container) ResolverService() *resolver.Service {
+    return c.resolverService
+}
+func (c *Container) ReviewService() *review.Service {
+    return c.reviewService
+}
+func (c *Container) InventoryService() *inventory.Service {
+    return c.inventoryService
+}
+func (c *Container) ReservationService() *reservation.Service {
+    return c.reservationService
+}
 
 
 
@@ -13,6 +13,21 @@ func NewCurrenciesMigration(db *sql.DB) *CurrenciesMigration {
    return &CurrenciesMigration{db: db}
 }
 
+func (m *CurrenciesMigration) checkConstraintExists(tableName, constraintName string) (bool, error) {
+   query := `
+       SELECT COUNT(*)
+       FROM information_schema.TABLE_CONSTRAINTS
+       WHERE CONSTRAINT_SCHEMA = DATABASE()
+       AND TABLE_NAME = ?
+       AND CONSTRAINT_NAME = ?
+   `
+   var count int
+   if err := m.db.QueryRow(query, tableName, constraintName).Scan(&count); err != nil {
+       return false, fmt.Errorf("error checking constraint existence: %w", err)
+   }
+   return count > 0, nil
+}
+
 func (m *CurrenciesMigration) Migrate() error {
    // Crear tabla currencies
    createCurrenciesQuery := `
@@ -34,6 +49,21 @@ func (m *CurrenciesMigration) Migrate() error {
        return fmt.Errorf("error creating Currencies table: %w", err)
    }
 
+   // Reglas de redondeo y formato por moneda para los precios de la tienda
+   addCurrencyFormatQuery := `
+       ALTER TABLE currencies
+       ADD COLUMN IF NOT EXISTS decimal_places TINYINT NOT NULL DEFAULT 2 AFTER active,
+       ADD COLUMN IF NOT EXISTS rounding_mode VARCHAR(10) NOT NULL DEFAULT '' AFTER decimal_places,
+       ADD COLUMN IF NOT EXISTS rounding_step DECIMAL(10,2) NULL AFTER rounding_mode,
+       ADD COLUMN IF NOT EXISTS charm_ending DECIMAL(3,2) NULL AFTER rounding_step,
+       ADD COLUMN IF NOT EXISTS symbol_position ENUM('before', 'after') NOT NULL DEFAULT 'before' AFTER charm_ending,
+       ADD COLUMN IF NOT EXISTS decimal_separator VARCHAR(1) NOT NULL DEFAULT '.' AFTER symbol_position,
+       ADD COLUMN IF NOT EXISTS thousands_separator VARCHAR(1) NOT NULL DEFAULT ',' AFTER decimal_separator
+   `
+   if _, err := m.db.Exec(addCurrencyFormatQuery); err != nil {
+       return fmt.Errorf("error adding format columns to currencies table: %w", err)
+   }
+
    // Crear tabla actual_currencies
    createActualCurrencyQuery := `
         CREATE TABLE IF NOT EXISTS exchange_rates (
@@ -52,6 +82,30 @@ func (m *CurrenciesMigration) Migrate() error {
        return fmt.Errorf("error creating exchange_rates table: %w", err)
    }
 
+   // Moneda de las listas de precios (NULL = moneda base). price_lists se crea antes que currencies
+   addPriceListCurrencyQuery := `
+       ALTER TABLE price_lists
+       ADD COLUMN IF NOT EXISTS id_currency INT NULL AFTER status
+   `
+   if _, err := m.db.Exec(addPriceListCurrencyQuery); err != nil {
+       return fmt.Errorf("error adding id_currency to price_lists table: %w", err)
+   }
+
+   exists, err := m.checkConstraintExists("price_lists", "fk_price_lists_currency")
+   if err != nil {
+       return err
+   }
+   if !exists {
+       alterTableQuery := `
+           ALTER TABLE price_lists
+           ADD CONSTRAINT fk_price_lists_currency
+           FOREIGN KEY (id_currency) REFERENCES currencies(id)
+       `
+       if _, err := m.db.Exec(alterTableQuery); err != nil {
+           return fmt.Errorf("error adding foreign key to price_lists table: %w", err)
+       }
+   }
+
    fmt.Println("Currencies, exchange rates and relationships ready")
    return nil
 }
\ No newline at end of file
@@ -0,0 +1,117 @@
+package entities
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+type InventoryMigration struct {
+    db *sql.DB
+}
+
+func NewInventoryMigration(db *sql.DB) *InventoryMigration {
+    return &InventoryMigration{db: db}
+}
+
+func (m *InventoryMigration) Migrate() error {
+    // Crear tabla warehouses
+    createWarehousesQuery := `
+        CREATE TABLE IF NOT EXISTS warehouses (
+            id INT AUTO_INCREMENT PRIMARY KEY,
+            name VARCHAR(150) NOT NULL,
+            code VARCHAR(50) UNIQUE NOT NULL,
+            address VARCHAR(255) NULL,
+            active BOOLEAN NOT NULL DEFAULT true,
+            is_default BOOLEAN NOT NULL DEFAULT false,
+            priority INT NOT NULL DEFAULT 0,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+            deleted_at TIMESTAMP NULL
+        )
+    `
+    if _, err := m.db.Exec(createWarehousesQuery); err != nil {
+        return fmt.Errorf("error creating warehouses table: %w", err)
+    }
+
+    // Libro de movimientos: sólo se insertan filas, nunca se modifican
+    createStockMovementsQuery := `
+        CREATE TABLE IF NOT EXISTS stock_movements (
+            id BIGINT AUTO_INCREMENT PRIMARY KEY,
+            id_warehouse INT NOT NULL,
+            id_product_variant INT NOT NULL,
+            type ENUM('receipt','sale','return','adjustment','transfer') NOT NULL,
+            quantity INT NOT NULL,
+            balance_after INT NOT NULL,
+            id_counterpart_warehouse INT NULL,
+            reason VARCHAR(255) NOT NULL,
+            actor VARCHAR(150) NOT NULL,
+            reference VARCHAR(100) NULL,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+            FOREIGN KEY (id_warehouse) REFERENCES warehouses(id),
+            FOREIGN KEY (id_product_variant) REFERENCES product_variants(id),
+            FOREIGN KEY (id_counterpart_warehouse) REFERENCES warehouses(id),
+            INDEX idx_stock_movements_variant (id_product_variant, created_at),
+            INDEX idx_stock_movements_warehouse (id_warehouse, created_at),
+            INDEX idx_stock_movements_reference (reference),
+            CONSTRAINT check_stock_movement_quantity CHECK (quantity <> 0)
+        )
+    `
+    if _, err := m.db.Exec(createStockMovementsQuery); err != nil {
+        return fmt.Errorf("error creating stock_movements table: %w", err)
+    }
+
+    // Existencias por almacén, acumuladas a partir del libro de movimientos
+    createWarehouseStockQuery := `
+        CREATE TABLE IF NOT EXISTS warehouse_stock (
+            id_warehouse INT NOT NULL,
+            id_product_variant INT NOT NULL,
+            quantity INT NOT NULL DEFAULT 0,
+            updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+            PRIMARY KEY (id_warehouse, id_product_variant),
+            FOREIGN KEY (id_warehouse) REFERENCES warehouses(id),
+            FOREIGN KEY (id_product_variant) REFERENCES product_variants(id),
+            INDEX idx_warehouse_stock_variant (id_product_variant),
+            CONSTRAINT check_warehouse_stock_quantity CHECK (quantity >= 0)
+        )
+    `
+    if _, err := m.db.Exec(createWarehouseStockQuery); err != nil {
+        return fmt.Errorf("error creating warehouse_stock table: %w", err)
+    }
+
+    // Almacén principal para el stock existente antes de los almacenes
+    createDefaultWarehouseQuery := `
+        INSERT INTO warehouses (name, code, active, is_default)
+        SELECT 'Almacén principal', 'MAIN', true, true
+        WHERE NOT EXISTS (SELECT 1 FROM warehouses)
+    `
+    if _, err := m.db.Exec(createDefaultWarehouseQuery); err != nil {
+        return fmt.Errorf("error creating default warehouse: %w", err)
+    }
+
+    // El stock anterior al libro entra como saldo inicial en el almacén principal
+    backfillMovementsQuery := `
+        INSERT INTO stock_movements (id_warehouse, id_product_variant, type, quantity, balance_after, reason, actor)
+        SELECT w.id, pv.id, 'adjustment', pv.stock, pv.stock, 'Saldo inicial', 'system'
+        FROM product_variants pv
+        INNER JOIN warehouses w ON w.is_default = true AND w.deleted_at IS NULL
+        WHERE pv.stock > 0
+            AND NOT EXISTS (SELECT 1 FROM stock_movements sm WHERE sm.id_product_variant = pv.id)
+            AND NOT EXISTS (SELECT 1 FROM product_bundles b WHERE b.id_product_variant = pv.id)
+    `
+    if _, err := m.db.Exec(backfillMovementsQuery); err != nil {
+        return fmt.Errorf("error backfilling stock movements: %w", err)
+    }
+
+    backfillWarehouseStockQuery := `
+        INSERT IGNORE INTO warehouse_stock (id_warehouse, id_product_variant, quantity)
+        SELECT id_warehouse, id_product_variant, SUM(quantity)
+        FROM stock_movements
+        GROUP BY id_warehouse, id_product_variant
+    `
+    if _, err := m.db.Exec(backfillWarehouseStockQuery); err != nil {
+        return fmt.Errorf("error backfilling warehouse stock: %w", err)
+    }
+
+    fmt.Println("Inventory tables ready")
+    return nil
+}
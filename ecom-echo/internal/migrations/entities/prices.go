@@ -79,6 +79,32 @@ func (m *PricesMigration) Migrate() error {
        return fmt.Errorf("error creating ProductVariant-Prices relationship table: %w", err)
    }
    
+   // Crear tabla price_tiers (precios por volumen)
+   createPriceTiersQuery := `
+       CREATE TABLE IF NOT EXISTS price_tiers (
+           id INT AUTO_INCREMENT PRIMARY KEY,
+           id_price_list INT NOT NULL,
+           id_product_variant INT NOT NULL,
+           min_quantity INT NOT NULL,
+           max_quantity INT NULL,
+           amount DECIMAL(10,2) NOT NULL,
+           created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+           updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+           deleted_at TIMESTAMP NULL,
+           FOREIGN KEY (id_price_list) REFERENCES price_lists(id),
+           FOREIGN KEY (id_product_variant) REFERENCES product_variants(id),
+           INDEX idx_list_variant (id_price_list, id_product_variant),
+           CONSTRAINT check_tier_amount CHECK (amount >= 0),
+           CONSTRAINT check_tier_quantities CHECK (
+               min_quantity >= 1 AND (max_quantity IS NULL OR max_quantity >= min_quantity)
+           )
+       )
+   `
+   
+   if _, err := m.db.Exec(createPriceTiersQuery); err != nil {
+       return fmt.Errorf("error creating PriceTiers table: %w", err)
+   }
+   
    fmt.Println("Price system tables ready")
    return nil
 }
\ No newline at end of file
@@ -124,6 +124,17 @@ func (m *ProductsMigration) Migrate() error {
         return fmt.Errorf("error creating product_variant_media table: %w", err)
     }
 
+    // Orden y portada de la galería de cada variante
+    addVariantMediaGalleryQuery := `
+        ALTER TABLE n_product_variant_media
+        ADD COLUMN IF NOT EXISTS position INT NOT NULL DEFAULT 0,
+        ADD COLUMN IF NOT EXISTS is_primary BOOLEAN NOT NULL DEFAULT false,
+        ADD COLUMN IF NOT EXISTS created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    `
+    if _, err := m.db.Exec(addVariantMediaGalleryQuery); err != nil {
+        return fmt.Errorf("error adding gallery columns to n_product_variant_media table: %w", err)
+    }
+
     // Crear tabla product_dimensions
     createProductDimensionsQuery := `
         CREATE TABLE IF NOT EXISTS product_dimensions (
@@ -141,6 +152,94 @@ func (m *ProductsMigration) Migrate() error {
         return fmt.Errorf("error creating product_dimensions table: %w", err)
     }
 
+    // Peso en kg con precisión de gramos
+    alterDimensionsWeightQuery := `ALTER TABLE product_dimensions MODIFY weight DECIMAL(10,3)`
+    if _, err := m.db.Exec(alterDimensionsWeightQuery); err != nil {
+        return fmt.Errorf("error altering product_dimensions weight column: %w", err)
+    }
+
+    // Relaciones curadas entre productos (relacionados, venta cruzada, gama superior y accesorios)
+    createProductRelationsQuery := `
+        CREATE TABLE IF NOT EXISTS product_relations (
+            id_product INT NOT NULL,
+            id_related_product INT NOT NULL,
+            type ENUM('related','cross_sell','up_sell','accessory') NOT NULL,
+            position INT NOT NULL DEFAULT 0,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+            PRIMARY KEY (id_product, type, id_related_product),
+            FOREIGN KEY (id_product) REFERENCES products(id),
+            FOREIGN KEY (id_related_product) REFERENCES products(id)
+        )
+    `
+    if _, err := m.db.Exec(createProductRelationsQuery); err != nil {
+        return fmt.Errorf("error creating product_relations table: %w", err)
+    }
+
+    // Packs: variantes compuestas por otras variantes
+    createProductBundlesQuery := `
+        CREATE TABLE IF NOT EXISTS product_bundles (
+            id_product_variant INT PRIMARY KEY,
+            pricing_mode ENUM('fixed','components') NOT NULL DEFAULT 'fixed',
+            discount_type ENUM('percent','amount') NULL,
+            discount_value DECIMAL(10,2) NOT NULL DEFAULT 0,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+            FOREIGN KEY (id_product_variant) REFERENCES product_variants(id)
+        )
+    `
+    if _, err := m.db.Exec(createProductBundlesQuery); err != nil {
+        return fmt.Errorf("error creating product_bundles table: %w", err)
+    }
+
+    createBundleComponentsQuery := `
+        CREATE TABLE IF NOT EXISTS product_bundle_components (
+            id_bundle_variant INT NOT NULL,
+            id_component_variant INT NOT NULL,
+            quantity INT NOT NULL,
+            position INT NOT NULL DEFAULT 0,
+            PRIMARY KEY (id_bundle_variant, id_component_variant),
+            FOREIGN KEY (id_bundle_variant) REFERENCES product_bundles(id_product_variant),
+            FOREIGN KEY (id_component_variant) REFERENCES product_variants(id),
+            INDEX idx_bundle_component (id_component_variant),
+            CONSTRAINT check_bundle_component_quantity CHECK (quantity > 0)
+        )
+    `
+    if _, err := m.db.Exec(createBundleComponentsQuery); err != nil {
+        return fmt.Errorf("error creating product_bundle_components table: %w", err)
+    }
+
+    // Índices FULLTEXT para la búsqueda de productos por relevancia
+    searchIndexQueries := []string{
+        `ALTER TABLE products ADD FULLTEXT INDEX IF NOT EXISTS ft_products_search (name, description)`,
+        `ALTER TABLE product_variants ADD FULLTEXT INDEX IF NOT EXISTS ft_product_variants_sku (sku)`,
+    }
+    for _, query := range searchIndexQueries {
+        if _, err := m.db.Exec(query); err != nil {
+            return fmt.Errorf("error creating product search indexes: %w", err)
+        }
+    }
+
+    // Ciclo de publicación y programación de productos
+    addPublicationQuery := `
+        ALTER TABLE products
+        ADD COLUMN IF NOT EXISTS publication_status ENUM('draft','in_review','scheduled','published','archived') NOT NULL DEFAULT 'draft' AFTER status,
+        ADD COLUMN IF NOT EXISTS publish_at TIMESTAMP NULL AFTER publication_status,
+        ADD COLUMN IF NOT EXISTS unpublish_at TIMESTAMP NULL AFTER publish_at,
+        ADD INDEX IF NOT EXISTS idx_products_publication (publication_status, publish_at, unpublish_at)
+    `
+    if _, err := m.db.Exec(addPublicationQuery); err != nil {
+        return fmt.Errorf("error adding publication columns to products table: %w", err)
+    }
+
+    // Los productos activos anteriores al ciclo de publicación pasan a publicados
+    backfillPublicationQuery := `
+        UPDATE products SET publication_status = 'published'
+        WHERE status = true AND publication_status = 'draft' AND publish_at IS NULL
+    `
+    if _, err := m.db.Exec(backfillPublicationQuery); err != nil {
+        return fmt.Errorf("error backfilling products publication status: %w", err)
+    }
+
     fmt.Println("Products system tables ready")
     return nil
 }
\ No newline at end of file
@@ -0,0 +1,55 @@
+package entities
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+type ReservationsMigration struct {
+    db *sql.DB
+}
+
+func NewReservationsMigration(db *sql.DB) *ReservationsMigration {
+    return &ReservationsMigration{db: db}
+}
+
+func (m *ReservationsMigration) Migrate() error {
+    // Reservas de stock por línea de carrito. Una línea de pack reserva sus componentes, así que
+    // id_line_variant es la variante del carrito e id_product_variant la que tiene stock
+    createReservationsQuery := `
+        CREATE TABLE IF NOT EXISTS stock_reservations (
+            id BIGINT AUTO_INCREMENT PRIMARY KEY,
+            id_cart INT NOT NULL,
+            id_line_variant INT NOT NULL,
+            id_product_variant INT NOT NULL,
+            quantity INT NOT NULL,
+            status ENUM('active','converted','released','expired') NOT NULL DEFAULT 'active',
+            expires_at TIMESTAMP NOT NULL,
+            reference VARCHAR(100) NULL,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+            FOREIGN KEY (id_cart) REFERENCES carts(id),
+            FOREIGN KEY (id_line_variant) REFERENCES product_variants(id),
+            FOREIGN KEY (id_product_variant) REFERENCES product_variants(id),
+            INDEX idx_stock_reservations_cart (id_cart, status),
+            INDEX idx_stock_reservations_variant (id_product_variant, status, expires_at),
+            INDEX idx_stock_reservations_expiry (status, expires_at),
+            CONSTRAINT check_stock_reservation_quantity CHECK (quantity > 0)
+        )
+    `
+    if _, err := m.db.Exec(createReservationsQuery); err != nil {
+        return fmt.Errorf("error creating stock_reservations table: %w", err)
+    }
+
+    // Unidades reservadas por variante, mantenidas junto a las reservas activas
+    addReservedQuery := `
+        ALTER TABLE product_variants
+        ADD COLUMN IF NOT EXISTS reserved INT NOT NULL DEFAULT 0 AFTER stock
+    `
+    if _, err := m.db.Exec(addReservedQuery); err != nil {
+        return fmt.Errorf("error adding reserved column to product_variants table: %w", err)
+    }
+
+    fmt.Println("Stock reservations table ready")
+    return nil
+}
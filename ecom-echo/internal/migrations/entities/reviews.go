@@ -0,0 +1,56 @@
+package entities
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+type ReviewsMigration struct {
+    db *sql.DB
+}
+
+func NewReviewsMigration(db *sql.DB) *ReviewsMigration {
+    return &ReviewsMigration{db: db}
+}
+
+func (m *ReviewsMigration) Migrate() error {
+    // Crear tabla product_reviews
+    createReviewsQuery := `
+        CREATE TABLE IF NOT EXISTS product_reviews (
+            id INT AUTO_INCREMENT PRIMARY KEY,
+            id_product INT NOT NULL,
+            id_customer INT NOT NULL,
+            rating TINYINT NOT NULL,
+            title VARCHAR(200) NOT NULL,
+            body TEXT,
+            verified_purchase BOOLEAN NOT NULL DEFAULT false,
+            status ENUM('pending','approved','rejected') NOT NULL DEFAULT 'pending',
+            moderation_note VARCHAR(255),
+            moderated_at TIMESTAMP NULL,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+            FOREIGN KEY (id_product) REFERENCES products(id),
+            FOREIGN KEY (id_customer) REFERENCES customers(id),
+            UNIQUE KEY uq_review_product_customer (id_product, id_customer),
+            INDEX idx_review_product_status (id_product, status),
+            INDEX idx_review_status (status, created_at),
+            CONSTRAINT check_review_rating CHECK (rating BETWEEN 1 AND 5)
+        )
+    `
+    if _, err := m.db.Exec(createReviewsQuery); err != nil {
+        return fmt.Errorf("error creating product_reviews table: %w", err)
+    }
+
+    // Valoración media y número de reseñas aprobadas, recalculadas al moderar
+    addProductRatingQuery := `
+        ALTER TABLE products
+        ADD COLUMN IF NOT EXISTS rating_average DECIMAL(3,2) NOT NULL DEFAULT 0,
+        ADD COLUMN IF NOT EXISTS rating_count INT NOT NULL DEFAULT 0
+    `
+    if _, err := m.db.Exec(addProductRatingQuery); err != nil {
+        return fmt.Errorf("error adding rating columns to products table: %w", err)
+    }
+
+    fmt.Println("Reviews table ready")
+    return nil
+}
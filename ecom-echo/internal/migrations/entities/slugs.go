@@ -0,0 +1,35 @@
+package entities
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+type SlugHistoryMigration struct {
+    db *sql.DB
+}
+
+func NewSlugHistoryMigration(db *sql.DB) *SlugHistoryMigration {
+    return &SlugHistoryMigration{db: db}
+}
+
+func (m *SlugHistoryMigration) Migrate() error {
+    // Slugs antiguos de productos y categorías, para redirigirlos al actual
+    query := `
+        CREATE TABLE IF NOT EXISTS slug_history (
+            id INT AUTO_INCREMENT PRIMARY KEY,
+            entity_type ENUM('product','category') NOT NULL,
+            entity_id INT NOT NULL,
+            slug VARCHAR(220) NOT NULL,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+            UNIQUE KEY uq_slug_history (entity_type, slug),
+            INDEX idx_slug_history_entity (entity_type, entity_id)
+        )
+    `
+    if _, err := m.db.Exec(query); err != nil {
+        return fmt.Errorf("error creating slug_history table: %w", err)
+    }
+
+    fmt.Println("Slug history table ready")
+    return nil
+}
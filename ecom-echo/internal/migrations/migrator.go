@@ -26,13 +26,17 @@ func NewMigrator(db *sql.DB) *Migrator {
             entities.NewTaxesMigration(db),
             entities.NewProductsMigration(db),
             entities.NewPricesMigration(db),
+            entities.NewSlugHistoryMigration(db),
+            entities.NewInventoryMigration(db),
             entities.NewZonesMigration(db),
             entities.NewCurrenciesMigration(db),
             entities.NewCountriesMigration(db),
             entities.NewCustomersMigration(db),
             entities.NewShippingsMigration(db),
             entities.NewCartsMigration(db),
+            entities.NewReservationsMigration(db),
             entities.NewOrdersMigration(db),
+            entities.NewReviewsMigration(db),
             entities.NewTriggersMigration(db),
             //entities.NewIndexesMigration(db),
             // Agregar aquí las demás migraciones
@@ -3,13 +3,19 @@ package server
 
 import (
 	attributeValue "ecom/internal/use_cases/attributeValue"
+	"ecom/internal/use_cases/catalog"
 	"ecom/internal/use_cases/category"
 	currencies "ecom/internal/use_cases/currencies"
 	"ecom/internal/use_cases/customer"
+	"ecom/internal/use_cases/inventory"
 	"ecom/internal/use_cases/media"
 	"ecom/internal/use_cases/prices"
 	product "ecom/internal/use_cases/product"
 	productattribute "ecom/internal/use_cases/productAttribute"
+	"ecom/internal/use_cases/reservation"
+	"ecom/internal/use_cases/resolver"
+	"ecom/internal/use_cases/review"
+	"ecom/internal/use_cases/search"
 	"ecom/internal/use_cases/tag"
 	taxcategory "ecom/internal/use_cases/taxes/taxCategory"
 	taxrate "ecom/internal/use_cases/taxes/taxRate"
@@ -37,9 +43,16 @@ func (s *Server) registerRoutes() {
 	taxrate.NewHandler(v1.Group("/tax-rates"), s.container.TaxRateService())
 	taxrule.NewHandler(v1.Group("/tax-rules"), s.container.TaxRuleService())
 	product.NewHandler(v1.Group("/products"), s.container.ProductService())
+	product.NewStorefrontHandler(v1.Group("/storefront/products"), s.container.ProductService())
 	prices.NewHandler(v1.Group("/prices"), s.container.PricesService())
 	customer.NewHandler(v1.Group("/customers"), s.container.CustomerService())
 	currencies.NewHandler(v1.Group("/currencies"), s.container.CurrencyService())
+	search.NewHandler(v1.Group("/search"), s.container.SearchService())
+	catalog.NewHandler(v1.Group("/catalog"), s.container.CatalogService())
+	resolver.NewHandler(v1.Group("/resolve"), s.container.ResolverService())
+	review.NewHandler(v1.Group("/reviews"), s.container.ReviewService())
+	inventory.NewHandler(v1.Group("/inventory"), s.container.InventoryService())
+	reservation.NewHandler(v1.Group("/reservations"), s.container.ReservationService())
 	
 	// Product routes
 	//product.NewHandler(v1.Group("/products"), s.container.ProductService())
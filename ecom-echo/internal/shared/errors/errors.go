@@ -66,6 +66,12 @@ func NewDuplicateEntryError(message string, err error) *AppError {
         Err:     err,
     }
 }
+func NewConflictError(message string) *AppError {
+    return &AppError{
+        Code:    409,
+        Message: message,
+    }
+}
 func NewMysqlError(err error) *AppError {
     
     if mysqlErr, ok := err.(*mysql.MySQLError); ok {
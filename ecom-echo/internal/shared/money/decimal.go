@@ -0,0 +1,121 @@
+// internal/shared/money/decimal.go
+package money
+
+import (
+	"database/sql/driver"
+
+	"github.com/shopspring/decimal"
+)
+
+// Decimal es el tipo numérico exacto usado para importes, tipos de cambio y porcentajes.
+// En JSON se escribe como número y se lee tanto de números como de cadenas
+type Decimal struct {
+	d decimal.Decimal
+}
+
+func (d Decimal) Add(other Decimal) Decimal { return Decimal{d.d.Add(other.d)} }
+func (d Decimal) Sub(other Decimal) Decimal { return Decimal{d.d.Sub(other.d)} }
+func (d Decimal) Mul(other Decimal) Decimal { return Decimal{d.d.Mul(other.d)} }
+
+// Div divide con la precisión por defecto de la librería (16 decimales)
+func (d Decimal) Div(other Decimal) Decimal { return Decimal{d.d.Div(other.d)} }
+
+func (d Decimal) Abs() Decimal   { return Decimal{d.d.Abs()} }
+func (d Decimal) Neg() Decimal   { return Decimal{d.d.Neg()} }
+func (d Decimal) Floor() Decimal { return Decimal{d.d.Floor()} }
+
+// Round redondea al medio alejándose de cero; para otros modos está RoundWith
+func (d Decimal) Round(places int32) Decimal { return Decimal{d.d.Round(places)} }
+
+func (d Decimal) RoundBank(places int32) Decimal  { return Decimal{d.d.RoundBank(places)} }
+func (d Decimal) RoundCeil(places int32) Decimal  { return Decimal{d.d.RoundCeil(places)} }
+func (d Decimal) RoundFloor(places int32) Decimal { return Decimal{d.d.RoundFloor(places)} }
+
+func (d Decimal) Cmp(other Decimal) int                 { return d.d.Cmp(other.d) }
+func (d Decimal) Equal(other Decimal) bool              { return d.d.Equal(other.d) }
+func (d Decimal) GreaterThan(other Decimal) bool        { return d.d.GreaterThan(other.d) }
+func (d Decimal) GreaterThanOrEqual(other Decimal) bool { return d.d.GreaterThanOrEqual(other.d) }
+func (d Decimal) LessThan(other Decimal) bool           { return d.d.LessThan(other.d) }
+func (d Decimal) LessThanOrEqual(other Decimal) bool    { return d.d.LessThanOrEqual(other.d) }
+
+func (d Decimal) Sign() int               { return d.d.Sign() }
+func (d Decimal) IsZero() bool            { return d.d.IsZero() }
+func (d Decimal) IsNegative() bool        { return d.d.IsNegative() }
+func (d Decimal) IsPositive() bool        { return d.d.IsPositive() }
+func (d Decimal) IntPart() int64          { return d.d.IntPart() }
+func (d Decimal) String() string          { return d.d.String() }
+func (d Decimal) InexactFloat64() float64 { return d.d.InexactFloat64() }
+
+func (d Decimal) StringFixed(places int32) string { return d.d.StringFixed(places) }
+
+// MarshalJSON escribe el importe como número, sin comillas
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.d.String()), nil
+}
+
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	return d.d.UnmarshalJSON(data)
+}
+
+func (d Decimal) MarshalText() ([]byte, error) {
+	return d.d.MarshalText()
+}
+
+func (d *Decimal) UnmarshalText(text []byte) error {
+	return d.d.UnmarshalText(text)
+}
+
+func (d Decimal) GobEncode() ([]byte, error) {
+	return d.d.GobEncode()
+}
+
+func (d *Decimal) GobDecode(data []byte) error {
+	return d.d.GobDecode(data)
+}
+
+// Scan lee columnas DECIMAL
+func (d *Decimal) Scan(value interface{}) error {
+	return d.d.Scan(value)
+}
+
+func (d Decimal) Value() (driver.Value, error) {
+	return d.d.Value()
+}
+
+// NullDecimal permite leer columnas DECIMAL que admiten NULL
+type NullDecimal struct {
+	Decimal Decimal
+	Valid   bool
+}
+
+func (n *NullDecimal) Scan(value interface{}) error {
+	if value == nil {
+		n.Decimal, n.Valid = Zero, false
+		return nil
+	}
+	n.Valid = true
+	return n.Decimal.Scan(value)
+}
+
+func (n NullDecimal) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Decimal.Value()
+}
+
+func (n NullDecimal) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return n.Decimal.MarshalJSON()
+}
+
+func (n *NullDecimal) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		n.Decimal, n.Valid = Zero, false
+		return nil
+	}
+	n.Valid = true
+	return n.Decimal.UnmarshalJSON(data)
+}
@@ -0,0 +1,158 @@
+// internal/shared/money/money.go
+package money
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Escalas de las columnas DECIMAL de la base de datos
+const (
+	AmountScale     int32 = 2 // DECIMAL(10,2)
+	RateScale       int32 = 6 // DECIMAL(10,6)
+	PercentageScale int32 = 2 // DECIMAL(5,2)
+)
+
+type RoundingMode string
+
+const (
+	RoundHalfUp   RoundingMode = "half_up"
+	RoundHalfEven RoundingMode = "half_even"
+	RoundUp       RoundingMode = "up"   // Alejándose de cero: 1.231 -> 1.24 y -1.231 -> -1.24
+	RoundDown     RoundingMode = "down" // Hacia cero: 1.239 -> 1.23 y -1.239 -> -1.23
+)
+
+var (
+	Zero    = Decimal{}
+	Hundred = NewFromInt(100)
+
+	defaultRounding = RoundHalfUp
+)
+
+// ParseRoundingMode valida un modo de redondeo; una cadena vacía equivale a half_up
+func ParseRoundingMode(value string) (RoundingMode, error) {
+	mode := RoundingMode(strings.ToLower(strings.TrimSpace(value)))
+	switch mode {
+	case "":
+		return RoundHalfUp, nil
+	case RoundHalfUp, RoundHalfEven, RoundUp, RoundDown:
+		return mode, nil
+	}
+	return "", fmt.Errorf("modo de redondeo no soportado: %s", value)
+}
+
+// SetDefaultRounding configura el modo de redondeo usado por Round y Money.Round
+func SetDefaultRounding(mode RoundingMode) {
+	defaultRounding = mode
+}
+
+func DefaultRounding() RoundingMode {
+	return defaultRounding
+}
+
+func NewFromInt(value int64) Decimal {
+	return Decimal{decimal.NewFromInt(value)}
+}
+
+// NewFromFloat convierte un float64 usando su representación decimal más corta,
+// así 0.1 se convierte en exactamente 0.1
+func NewFromFloat(value float64) Decimal {
+	return Decimal{decimal.NewFromFloat(value)}
+}
+
+func NewFromString(value string) (Decimal, error) {
+	d, err := decimal.NewFromString(strings.TrimSpace(value))
+	return Decimal{d}, err
+}
+
+// Round redondea con el modo por defecto
+func Round(d Decimal, places int32) Decimal {
+	return RoundWith(d, places, defaultRounding)
+}
+
+// RoundWith redondea a un número de decimales con un modo concreto
+func RoundWith(d Decimal, places int32, mode RoundingMode) Decimal {
+	switch mode {
+	case RoundHalfEven:
+		return Decimal{d.d.RoundBank(places)}
+	case RoundUp:
+		return Decimal{d.d.RoundUp(places)}
+	case RoundDown:
+		return Decimal{d.d.RoundDown(places)}
+	default:
+		return Decimal{d.d.Round(places)}
+	}
+}
+
+// RoundToStep redondea a un múltiplo de step (por ejemplo 0.05 o 0.99 céntimos de paso)
+func RoundToStep(d, step Decimal, mode RoundingMode) Decimal {
+	if step.Sign() <= 0 {
+		return d
+	}
+	return RoundWith(d.Div(step), 0, mode).Mul(step)
+}
+
+// Percentage calcula el porcentaje de un importe sin redondear
+func Percentage(d, percentage Decimal) Decimal {
+	return d.Mul(percentage).Div(Hundred)
+}
+
+// Money es un importe en una divisa concreta
+type Money struct {
+	Amount   Decimal `json:"amount"`
+	Currency string  `json:"currency"`
+}
+
+func New(amount Decimal, currency string) Money {
+	return Money{Amount: amount, Currency: strings.ToUpper(currency)}
+}
+
+func (m Money) sameCurrency(other Money) error {
+	if m.Currency != other.Currency {
+		return fmt.Errorf("no se pueden operar importes en %s y %s", m.Currency, other.Currency)
+	}
+	return nil
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount.Add(other.Amount), Currency: m.Currency}, nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount.Sub(other.Amount), Currency: m.Currency}, nil
+}
+
+// Mul multiplica por un factor (cantidad, porcentaje ya dividido, etc.) sin redondear
+func (m Money) Mul(factor Decimal) Money {
+	return Money{Amount: m.Amount.Mul(factor), Currency: m.Currency}
+}
+
+// Convert aplica un tipo de cambio y redondea al céntimo con el modo por defecto
+func (m Money) Convert(rate Decimal, currency string) Money {
+	return New(Round(m.Amount.Mul(rate), AmountScale), currency)
+}
+
+// Round redondea al céntimo con el modo por defecto
+func (m Money) Round() Money {
+	return Money{Amount: Round(m.Amount, AmountScale), Currency: m.Currency}
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount.IsZero()
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount.IsNegative()
+}
+
+func (m Money) String() string {
+	return m.Amount.StringFixed(AmountScale) + " " + m.Currency
+}
@@ -0,0 +1,105 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func mustDecimal(t *testing.T, value string) Decimal {
+	t.Helper()
+	d, err := NewFromString(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestRoundWith(t *testing.T) {
+	cases := []struct {
+		value string
+		mode  RoundingMode
+		want  string
+	}{
+		{"1.225", RoundHalfUp, "1.23"},
+		{"-1.225", RoundHalfUp, "-1.23"},
+		{"1.224", RoundHalfUp, "1.22"},
+		{"1.225", RoundHalfEven, "1.22"},
+		{"1.235", RoundHalfEven, "1.24"},
+		{"-1.225", RoundHalfEven, "-1.22"},
+		{"-1.235", RoundHalfEven, "-1.24"},
+		{"1.231", RoundUp, "1.24"},
+		{"-1.231", RoundUp, "-1.24"},
+		{"1.23", RoundUp, "1.23"},
+		{"1.239", RoundDown, "1.23"},
+		{"-1.239", RoundDown, "-1.23"},
+		{"-1.23", RoundDown, "-1.23"},
+	}
+	for _, tc := range cases {
+		got := RoundWith(mustDecimal(t, tc.value), AmountScale, tc.mode)
+		if got.StringFixed(AmountScale) != tc.want {
+			t.Errorf("RoundWith(%s, %s) = %s, se esperaba %s", tc.value, tc.mode, got.StringFixed(AmountScale), tc.want)
+		}
+	}
+}
+
+func TestRoundUsesDefaultMode(t *testing.T) {
+	defer SetDefaultRounding(DefaultRounding())
+
+	SetDefaultRounding(RoundHalfEven)
+	if got := Round(mustDecimal(t, "2.345"), AmountScale).String(); got != "2.34" {
+		t.Fatalf("Round con half_even = %s, se esperaba 2.34", got)
+	}
+	SetDefaultRounding(RoundHalfUp)
+	if got := Round(mustDecimal(t, "2.345"), AmountScale).String(); got != "2.35" {
+		t.Fatalf("Round con half_up = %s, se esperaba 2.35", got)
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	type payload struct {
+		Amount Decimal     `json:"amount"`
+		Old    NullDecimal `json:"old"`
+	}
+
+	data, err := json.Marshal(payload{Amount: mustDecimal(t, "19.90")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"amount":19.9,"old":null}` {
+		t.Fatalf("JSON %s, se esperaba el importe como número y old null", data)
+	}
+
+	cases := []struct {
+		name  string
+		input string
+		want  string
+		valid bool
+	}{
+		{"números", `{"amount":0.1,"old":12.5}`, "0.1", true},
+		{"cadenas", `{"amount":"0.30","old":"7"}`, "0.3", true},
+		{"null", `{"amount":1,"old":null}`, "1", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var p payload
+			if err := json.Unmarshal([]byte(tc.input), &p); err != nil {
+				t.Fatal(err)
+			}
+			if !p.Amount.Equal(mustDecimal(t, tc.want)) || p.Old.Valid != tc.valid {
+				t.Fatalf("amount %s old %+v, se esperaba %s y valid=%v", p.Amount, p.Old, tc.want, tc.valid)
+			}
+
+			again, err := json.Marshal(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var back payload
+			if err := json.Unmarshal(again, &back); err != nil {
+				t.Fatal(err)
+			}
+			if !back.Amount.Equal(p.Amount) || back.Old.Valid != p.Old.Valid || !back.Old.Decimal.Equal(p.Old.Decimal) {
+				t.Fatalf("ida y vuelta %s -> %s no conserva el valor", tc.input, again)
+			}
+		})
+	}
+}
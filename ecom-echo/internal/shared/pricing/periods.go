@@ -0,0 +1,112 @@
+// internal/shared/pricing/periods.go
+package pricing
+
+import (
+	"context"
+	"database/sql"
+	"ecom/internal/shared/errors"
+	"time"
+)
+
+// Period vigencia de un precio asignado a una variante. Los solapamientos se comprueban
+// con prices.Price.Overlaps
+type Period struct {
+	IDPrice  int64
+	StartsAt time.Time
+	EndsAt   *time.Time
+}
+
+// LockVariantPrices bloquea la variante y devuelve sus precios activos en la lista, también
+// bloqueados. Mientras dure la transacción nadie más puede asignarle precios, así que las
+// comprobaciones de solapamiento hechas con el resultado siguen siendo válidas al insertar
+func LockVariantPrices(ctx context.Context, tx *sql.Tx, variantID, priceListID int64) ([]Period, error) {
+	var id int64
+	err := tx.QueryRowContext(ctx,
+		"SELECT id FROM product_variants WHERE id = ? AND deleted_at IS NULL FOR UPDATE", variantID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, errors.NewNotFoundError("Variante de producto no encontrada")
+	}
+	if err != nil {
+		return nil, errors.NewMysqlError(err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT p.id, p.starts_at, p.ends_at
+		FROM prices p
+		JOIN n_product_variant_prices pvp ON pvp.id_price = p.id
+		WHERE pvp.id_product_variant = ? AND p.id_price_list = ?
+		AND pvp.is_active = true AND p.deleted_at IS NULL
+		ORDER BY p.starts_at
+		FOR UPDATE
+	`, variantID, priceListID)
+	if err != nil {
+		return nil, errors.NewMysqlError(err)
+	}
+	defer rows.Close()
+
+	var periods []Period
+	for rows.Next() {
+		var p Period
+		if err := rows.Scan(&p.IDPrice, &p.StartsAt, &p.EndsAt); err != nil {
+			return nil, errors.NewMysqlError(err)
+		}
+		periods = append(periods, p)
+	}
+	return periods, rows.Err()
+}
+
+// ClosePrice termina en at el precio de la variante. Si otras variantes comparten la fila de
+// prices, la variante pasa a una copia cerrada y las demás conservan el precio sin cambios.
+// Un precio que aún no había empezado en at simplemente deja de estar activo
+func ClosePrice(ctx context.Context, tx *sql.Tx, variantID, priceID int64, at time.Time) error {
+	var startsAt time.Time
+	var shared int
+	err := tx.QueryRowContext(ctx, `
+		SELECT p.starts_at, (SELECT COUNT(*) FROM n_product_variant_prices WHERE id_price = p.id)
+		FROM prices p WHERE p.id = ?
+	`, priceID).Scan(&startsAt, &shared)
+	if err == sql.ErrNoRows {
+		return errors.NewNotFoundError("Precio no encontrado")
+	}
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+
+	if !startsAt.Before(at) {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE n_product_variant_prices SET is_active = false, updated_at = NOW()
+			WHERE id_product_variant = ? AND id_price = ?
+		`, variantID, priceID); err != nil {
+			return errors.NewMysqlError(err)
+		}
+		return nil
+	}
+
+	if shared <= 1 {
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE prices SET ends_at = ?, updated_at = NOW() WHERE id = ?", at, priceID); err != nil {
+			return errors.NewMysqlError(err)
+		}
+		return nil
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO prices (id_price_list, amount, starts_at, ends_at, created_at, updated_at)
+		SELECT id_price_list, amount, starts_at, ?, NOW(), NOW() FROM prices WHERE id = ?
+	`, at, priceID)
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	copyID, err := result.LastInsertId()
+	if err != nil {
+		return errors.NewInternalError("Error al obtener el id creado", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE n_product_variant_prices SET id_price = ?, updated_at = NOW()
+		WHERE id_product_variant = ? AND id_price = ?
+	`, copyID, variantID, priceID); err != nil {
+		return errors.NewMysqlError(err)
+	}
+	return nil
+}
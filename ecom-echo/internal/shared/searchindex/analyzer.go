@@ -0,0 +1,155 @@
+// internal/shared/searchindex/analyzer.go
+package searchindex
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Analyzer convierte texto en términos indexables: minúsculas, sin acentos,
+// sin palabras vacías y con una reducción ligera de plurales y género del español
+type Analyzer struct {
+	stopwords map[string]bool
+	synonyms  map[string][]string // raíz -> frases equivalentes normalizadas
+}
+
+var spanishStopwords = []string{
+	"a", "al", "algo", "ante", "con", "contra", "cual", "de", "del", "desde", "donde",
+	"durante", "e", "el", "ella", "en", "entre", "es", "esa", "ese", "eso", "esta", "este",
+	"esto", "ha", "hay", "hasta", "la", "las", "le", "les", "lo", "los", "mas", "me",
+	"mi", "muy", "ni", "no", "o", "otra", "otro", "para", "pero", "por", "que", "se",
+	"sin", "sobre", "su", "sus", "tambien", "te", "u", "un", "una", "unas", "uno", "unos",
+	"y", "ya",
+}
+
+var accentReplacer = strings.NewReplacer(
+	"á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "ñ", "n",
+	"à", "a", "è", "e", "ì", "i", "ò", "o", "ù", "u",
+)
+
+// NewSpanishAnalyzer crea el analizador; synonyms asocia cada palabra normalizada con sus equivalentes
+func NewSpanishAnalyzer(synonyms map[string][]string) *Analyzer {
+	a := &Analyzer{
+		stopwords: make(map[string]bool, len(spanishStopwords)),
+		synonyms:  make(map[string][]string),
+	}
+	for _, w := range spanishStopwords {
+		a.stopwords[w] = true
+	}
+	for word, equivalents := range synonyms {
+		key := strings.Join(a.Terms(word), " ")
+		for _, e := range equivalents {
+			if e := a.normalizeWord(e); e != "" && strings.Join(a.Terms(e), " ") != key {
+				a.synonyms[key] = append(a.synonyms[key], e)
+			}
+		}
+	}
+	return a
+}
+
+// LoadSynonyms lee un archivo con un grupo de sinónimos por línea separados por comas,
+// por ejemplo "tv, televisor, television". Las líneas que empiezan por # se ignoran
+func LoadSynonyms(path string) (map[string][]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error al abrir el archivo de sinónimos: %w", err)
+	}
+	defer f.Close()
+
+	synonyms := make(map[string][]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var group []string
+		for _, w := range strings.Split(line, ",") {
+			if w = strings.TrimSpace(w); w != "" {
+				group = append(group, w)
+			}
+		}
+		for _, w := range group {
+			for _, other := range group {
+				if other != w {
+					synonyms[w] = append(synonyms[w], other)
+				}
+			}
+		}
+	}
+	return synonyms, scanner.Err()
+}
+
+func (a *Analyzer) normalizeWord(word string) string {
+	return accentReplacer.Replace(strings.ToLower(strings.TrimSpace(word)))
+}
+
+// Words divide el texto en palabras normalizadas, sin palabras vacías
+func (a *Analyzer) Words(text string) []string {
+	fields := strings.FieldsFunc(a.normalizeWord(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	words := fields[:0]
+	for _, w := range fields {
+		if !a.stopwords[w] {
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
+// Terms devuelve las raíces de las palabras del texto
+func (a *Analyzer) Terms(text string) []string {
+	words := a.Words(text)
+	for i, w := range words {
+		words[i] = Stem(w)
+	}
+	return words
+}
+
+// Synonyms devuelve las raíces de los sinónimos de un término
+func (a *Analyzer) Synonyms(term string) []string {
+	var terms []string
+	for _, s := range a.synonyms[term] {
+		terms = append(terms, a.Terms(s)...)
+	}
+	return terms
+}
+
+// Code normaliza un código (SKU) para buscarlo literalmente
+func (a *Analyzer) Code(code string) string {
+	return strings.Join(strings.Fields(a.normalizeWord(code)), "")
+}
+
+// Stem reduce plurales y terminaciones de género: "camisas" y "camisa" -> "camis",
+// "luces" -> "luz", "meses" -> "mes"
+func Stem(word string) string {
+	n := len(word)
+	switch {
+	case n < 4 || !isLetters(word):
+		return word
+	case n > 5 && strings.HasSuffix(word, "eses"):
+		return word[:n-2]
+	case n > 4 && strings.HasSuffix(word, "ces"):
+		return word[:n-3] + "z"
+	case strings.HasSuffix(word, "os") || strings.HasSuffix(word, "as") || strings.HasSuffix(word, "es"):
+		return word[:n-2]
+	case strings.HasSuffix(word, "o") || strings.HasSuffix(word, "a") || strings.HasSuffix(word, "e"):
+		return word[:n-1]
+	}
+	return word
+}
+
+func isLetters(word string) bool {
+	for _, r := range word {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package searchindex
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestStem(t *testing.T) {
+	cases := []struct {
+		word string
+		want string
+	}{
+		{"camisa", "camis"},
+		{"camisas", "camis"},
+		{"zapato", "zapat"},
+		{"zapatos", "zapat"},
+		{"luces", "luz"},
+		{"meses", "mes"},
+		{"intereses", "interes"},
+		{"paises", "pais"},
+		{"televisor", "televisor"},
+		{"sol", "sol"},
+		{"usb3", "usb3"},
+	}
+	for _, tc := range cases {
+		if got := Stem(tc.word); got != tc.want {
+			t.Errorf("Stem(%q) = %q, se esperaba %q", tc.word, got, tc.want)
+		}
+	}
+}
+
+func TestAnalyzerTermsNormalizeText(t *testing.T) {
+	a := NewSpanishAnalyzer(nil)
+
+	got := a.Terms("Camisetas de algodón para LAS Luces")
+	want := []string{"camiset", "algodon", "luz"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Terms = %v, se esperaba %v", got, want)
+	}
+}
+
+func TestAnalyzerSynonyms(t *testing.T) {
+	a := NewSpanishAnalyzer(map[string][]string{
+		"TV":       {"televisor", "Televisión"},
+		"camiseta": {"camisetas", "playera"},
+	})
+
+	cases := []struct {
+		name string
+		term string
+		want []string
+	}{
+		{"equivalentes normalizados", "tv", []string{"televisor", "television"}},
+		{"se omite el equivalente con la misma raíz", "camiset", []string{"player"}},
+		{"término sin sinónimos", "pantalon", nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := a.Synonyms(tc.term); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("Synonyms(%q) = %v, se esperaba %v", tc.term, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLoadSynonymsGroups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sinonimos.txt")
+	content := "# electrónica\ntv, televisor, Televisión\n\nsofá ,sillón\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	synonyms, err := LoadSynonyms(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := NewSpanishAnalyzer(synonyms)
+
+	cases := []struct {
+		term string
+		want []string
+	}{
+		{"televisor", []string{"tv", "television"}},
+		{"tv", []string{"televisor", "television"}},
+		{"sof", []string{"sillon"}},
+	}
+	for _, tc := range cases {
+		if got := a.Synonyms(tc.term); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Synonyms(%q) = %v, se esperaba %v", tc.term, got, tc.want)
+		}
+	}
+}
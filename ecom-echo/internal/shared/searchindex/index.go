@@ -0,0 +1,602 @@
+// internal/shared/searchindex/index.go
+package searchindex
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Pesos de cada campo del documento
+const (
+	titleWeight   = 3.0
+	keywordWeight = 2.0
+	bodyWeight    = 1.0
+	codeBoost     = 10.0
+
+	synonymFactor = 0.9
+	fuzzyFactor   = 0.6
+	prefixFactor  = 0.5
+
+	indexVersion = 1
+	indexFile    = "index.gob"
+	lockFile     = "index.lock"
+
+	// Los cambios se agrupan y se guardan en disco como mucho una vez en este intervalo
+	flushDelay = 5 * time.Second
+)
+
+// ErrIndexLocked indica que otro proceso (la API o cmd/reindex) tiene abierto el índice
+var ErrIndexLocked = errors.New("el índice de búsqueda está abierto por otro proceso")
+
+// Document es la unidad que se indexa
+type Document struct {
+	ID       int64
+	Title    string            // Nombre: es lo que más pesa
+	Keywords []string          // Categoría, tags, atributos...
+	Body     string            // Descripción
+	Codes    []string          // Códigos que se buscan literalmente (SKU)
+	Stored   map[string]string // Datos que se devuelven con el resultado
+}
+
+type Hit struct {
+	ID     int64             `json:"id"`
+	Title  string            `json:"title"`
+	Score  float64           `json:"score"`
+	Stored map[string]string `json:"stored,omitempty"`
+}
+
+type Result struct {
+	Hits  []Hit `json:"hits"`
+	Total int   `json:"total"`
+	// Términos de la consulta que no existían y se sustituyeron por uno parecido
+	Corrections map[string]string `json:"corrections,omitempty"`
+}
+
+const (
+	SuggestionTerm    = "term"
+	SuggestionProduct = "product"
+)
+
+type Suggestion struct {
+	Text string `json:"text"`
+	Type string `json:"type"` // term | product
+	ID   int64  `json:"id,omitempty"`
+}
+
+// SearchIndex es un índice de búsqueda de texto. La implementación por defecto
+// es DiskIndex, embebida en el proceso y persistida en disco
+type SearchIndex interface {
+	Index(docs ...Document) error
+	Delete(ids ...int64) error
+	// Replace sustituye todo el contenido del índice (reconstrucción)
+	Replace(docs []Document) error
+	Search(query string, limit, offset int) (*Result, error)
+	Suggest(prefix string, limit int) ([]Suggestion, error)
+	Count() int
+	// Close guarda los cambios pendientes
+	Close() error
+}
+
+type storedDoc struct {
+	Title  string
+	Stored map[string]string
+	Terms  map[string]float64 // Para poder retirar el documento de los postings
+	Words  []string           // Palabras visibles para el autocompletado
+}
+
+type indexData struct {
+	Version  int
+	Docs     map[int64]*storedDoc
+	Postings map[string]map[int64]float64 // término -> documento -> peso
+	Words    map[string]int               // palabra -> número de documentos
+}
+
+func newIndexData() *indexData {
+	return &indexData{
+		Version:  indexVersion,
+		Docs:     make(map[int64]*storedDoc),
+		Postings: make(map[string]map[int64]float64),
+		Words:    make(map[string]int),
+	}
+}
+
+// DiskIndex es un índice invertido en memoria que se guarda en un archivo del directorio
+// indicado. Los cambios sueltos se guardan agrupados en segundo plano (flushDelay); una
+// reconstrucción y Close guardan en el momento. Si el proceso termina sin Close se pierden
+// como mucho los cambios de los últimos segundos, que se recuperan con cmd/reindex.
+// Un solo proceso puede tener abierto el directorio: cada uno guarda su copia en memoria
+// completa y sobrescribiría lo que haya guardado el otro
+type DiskIndex struct {
+	mu       sync.RWMutex
+	dir      string
+	analyzer *Analyzer
+	data     *indexData
+	lock     *os.File
+
+	saveMu sync.Mutex // Una sola escritura del archivo a la vez
+
+	flushMu sync.Mutex // Protege dirty y timer
+	dirty   bool
+	timer   *time.Timer
+}
+
+// OpenDiskIndex abre (o crea) el índice del directorio. Si el archivo es de otra versión
+// se empieza vacío y hay que reconstruirlo. Devuelve ErrIndexLocked si otro proceso lo tiene abierto
+func OpenDiskIndex(dir string, analyzer *Analyzer) (*DiskIndex, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error al crear el directorio del índice: %w", err)
+	}
+
+	lock, err := lockDir(filepath.Join(dir, lockFile))
+	if err != nil {
+		return nil, err
+	}
+	idx := &DiskIndex{dir: dir, analyzer: analyzer, data: newIndexData(), lock: lock}
+
+	f, err := os.Open(filepath.Join(dir, indexFile))
+	if os.IsNotExist(err) {
+		return idx, nil
+	}
+	if err != nil {
+		lock.Close()
+		return nil, fmt.Errorf("error al abrir el índice de búsqueda: %w", err)
+	}
+	defer f.Close()
+
+	data := &indexData{}
+	if err := gob.NewDecoder(f).Decode(data); err != nil || data.Version != indexVersion {
+		log.Printf("El índice de búsqueda de %s no es válido, se debe reconstruir", dir)
+		return idx, nil
+	}
+	idx.data = data
+	return idx, nil
+}
+
+func (idx *DiskIndex) Count() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.data.Docs)
+}
+
+func (idx *DiskIndex) Index(docs ...Document) error {
+	idx.mu.Lock()
+	for _, doc := range docs {
+		idx.remove(idx.data, doc.ID)
+		idx.add(idx.data, doc)
+	}
+	idx.mu.Unlock()
+
+	idx.scheduleFlush()
+	return nil
+}
+
+func (idx *DiskIndex) Delete(ids ...int64) error {
+	idx.mu.Lock()
+	for _, id := range ids {
+		idx.remove(idx.data, id)
+	}
+	idx.mu.Unlock()
+
+	idx.scheduleFlush()
+	return nil
+}
+
+func (idx *DiskIndex) Replace(docs []Document) error {
+	data := newIndexData()
+	for _, doc := range docs {
+		idx.add(data, doc)
+	}
+
+	idx.mu.Lock()
+	idx.data = data
+	idx.mu.Unlock()
+
+	idx.flushMu.Lock()
+	idx.dirty = true
+	idx.flushMu.Unlock()
+	return idx.Flush()
+}
+
+// Flush guarda el índice si tiene cambios sin guardar
+func (idx *DiskIndex) Flush() error {
+	idx.saveMu.Lock()
+	defer idx.saveMu.Unlock()
+
+	idx.flushMu.Lock()
+	dirty := idx.dirty
+	idx.dirty = false
+	if idx.timer != nil {
+		idx.timer.Stop()
+		idx.timer = nil
+	}
+	idx.flushMu.Unlock()
+	if !dirty {
+		return nil
+	}
+
+	if err := idx.save(); err != nil {
+		idx.scheduleFlush()
+		return err
+	}
+	return nil
+}
+
+// Close guarda los cambios pendientes y libera el directorio para otro proceso
+func (idx *DiskIndex) Close() error {
+	err := idx.Flush()
+	if idx.lock != nil {
+		idx.lock.Close()
+		idx.lock = nil
+	}
+	return err
+}
+
+// scheduleFlush marca el índice como modificado y programa su guardado si no lo estaba ya
+func (idx *DiskIndex) scheduleFlush() {
+	idx.flushMu.Lock()
+	defer idx.flushMu.Unlock()
+
+	idx.dirty = true
+	if idx.timer == nil {
+		idx.timer = time.AfterFunc(flushDelay, func() {
+			if err := idx.Flush(); err != nil {
+				log.Printf("Error al guardar el índice de búsqueda: %v", err)
+			}
+		})
+	}
+}
+
+func (idx *DiskIndex) add(data *indexData, doc Document) {
+	terms := make(map[string]float64)
+	for _, t := range idx.analyzer.Terms(doc.Title) {
+		terms[t] += titleWeight
+	}
+	for _, k := range doc.Keywords {
+		for _, t := range idx.analyzer.Terms(k) {
+			terms[t] += keywordWeight
+		}
+	}
+	for _, t := range idx.analyzer.Terms(doc.Body) {
+		terms[t] += bodyWeight
+	}
+	for _, c := range doc.Codes {
+		if code := idx.analyzer.Code(c); code != "" {
+			terms["="+code] += codeBoost
+		}
+	}
+
+	for term, weight := range terms {
+		postings, ok := data.Postings[term]
+		if !ok {
+			postings = make(map[int64]float64)
+			data.Postings[term] = postings
+		}
+		postings[doc.ID] = weight
+	}
+
+	// Sólo las palabras del nombre y las palabras clave se sugieren al autocompletar
+	words := uniqueWords(idx.analyzer.Words(doc.Title + " " + strings.Join(doc.Keywords, " ")))
+	for _, w := range words {
+		data.Words[w]++
+	}
+
+	data.Docs[doc.ID] = &storedDoc{Title: doc.Title, Stored: doc.Stored, Terms: terms, Words: words}
+}
+
+func (idx *DiskIndex) remove(data *indexData, id int64) {
+	doc, ok := data.Docs[id]
+	if !ok {
+		return
+	}
+
+	for term := range doc.Terms {
+		delete(data.Postings[term], id)
+		if len(data.Postings[term]) == 0 {
+			delete(data.Postings, term)
+		}
+	}
+	for _, w := range doc.Words {
+		if data.Words[w]--; data.Words[w] <= 0 {
+			delete(data.Words, w)
+		}
+	}
+	delete(data.Docs, id)
+}
+
+// save escribe el índice en un archivo temporal y lo renombra para no dejarlo a medias.
+// Sólo bloquea el índice para lectura mientras lo codifica en memoria, así que las búsquedas
+// siguen respondiendo y la escritura en disco no retiene a nadie
+func (idx *DiskIndex) save() error {
+	var buf bytes.Buffer
+	idx.mu.RLock()
+	err := gob.NewEncoder(&buf).Encode(idx.data)
+	idx.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("error al guardar el índice de búsqueda: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(idx.dir, indexFile+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error al guardar el índice de búsqueda: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := buf.WriteTo(tmp); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error al guardar el índice de búsqueda: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error al guardar el índice de búsqueda: %w", err)
+	}
+	return os.Rename(tmp.Name(), filepath.Join(idx.dir, indexFile))
+}
+
+type expansion struct {
+	term   string
+	factor float64
+}
+
+// expand devuelve los términos del índice que cuentan como coincidencia de un término de la
+// consulta: él mismo, sus sinónimos, los parecidos (errores de escritura) y, si es la última
+// palabra, los que empiezan por él
+func (idx *DiskIndex) expand(term string, last bool) []expansion {
+	var result []expansion
+	seen := map[string]bool{}
+	addTerm := func(t string, factor float64) {
+		if !seen[t] {
+			seen[t] = true
+			result = append(result, expansion{term: t, factor: factor})
+		}
+	}
+
+	if _, ok := idx.data.Postings[term]; ok {
+		addTerm(term, 1)
+	}
+	for _, s := range idx.analyzer.Synonyms(term) {
+		if _, ok := idx.data.Postings[s]; ok {
+			addTerm(s, synonymFactor)
+		}
+	}
+
+	// Los parecidos sólo se usan si el término no existe tal cual ni como sinónimo
+	maxEdits := allowedEdits(term)
+	if len(result) > 0 {
+		maxEdits = 0
+	}
+	for candidate := range idx.data.Postings {
+		if seen[candidate] || strings.HasPrefix(candidate, "=") {
+			continue
+		}
+		if last && len(term) >= 2 && strings.HasPrefix(candidate, term) {
+			addTerm(candidate, prefixFactor)
+			continue
+		}
+		if maxEdits > 0 && withinDistance(term, candidate, maxEdits) {
+			addTerm(candidate, fuzzyFactor)
+		}
+	}
+	return result
+}
+
+// correction elige, entre los términos parecidos, el que aparece en más documentos
+func (idx *DiskIndex) correction(expansions []expansion) string {
+	best := ""
+	for _, e := range expansions {
+		if e.factor == 1 || e.factor == synonymFactor {
+			return ""
+		}
+		if e.factor != fuzzyFactor {
+			continue
+		}
+		df, bestDF := len(idx.data.Postings[e.term]), len(idx.data.Postings[best])
+		if best == "" || df > bestDF || (df == bestDF && e.term < best) {
+			best = e.term
+		}
+	}
+	return best
+}
+
+func allowedEdits(term string) int {
+	switch n := len([]rune(term)); {
+	case n >= 8:
+		return 2
+	case n >= 4:
+		return 1
+	}
+	return 0
+}
+
+// Search devuelve los documentos que contienen todos los términos de la consulta
+// (o alguno, si ninguno los contiene todos) ordenados por relevancia
+func (idx *DiskIndex) Search(query string, limit, offset int) (*Result, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	result := &Result{Hits: []Hit{}}
+	terms := idx.analyzer.Terms(query)
+	total := float64(len(idx.data.Docs))
+
+	scores := make(map[int64]float64)
+	matched := make(map[int64]int)
+
+	for i, term := range terms {
+		expansions := idx.expand(term, i == len(terms)-1)
+		if corrected := idx.correction(expansions); corrected != "" {
+			if result.Corrections == nil {
+				result.Corrections = make(map[string]string)
+			}
+			result.Corrections[term] = corrected
+		}
+
+		termScores := make(map[int64]float64)
+		for _, e := range expansions {
+			postings := idx.data.Postings[e.term]
+			df := float64(len(postings))
+			idf := math.Log(1 + (total-df+0.5)/(df+0.5))
+			for id, weight := range postings {
+				// Saturación tipo BM25: repetir una palabra aporta cada vez menos
+				score := idf * (weight * 2.2) / (weight + 1.2) * e.factor
+				if score > termScores[id] {
+					termScores[id] = score
+				}
+			}
+		}
+		for id, score := range termScores {
+			scores[id] += score
+			matched[id]++
+		}
+	}
+
+	// Coincidencia literal de código con la consulta completa
+	if code := idx.analyzer.Code(query); code != "" {
+		for id, weight := range idx.data.Postings["="+code] {
+			scores[id] += weight
+			matched[id] = len(terms)
+		}
+	}
+
+	var ids []int64
+	for id := range scores {
+		if matched[id] >= len(terms) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		for id := range scores {
+			ids = append(ids, id)
+		}
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] != scores[ids[j]] {
+			return scores[ids[i]] > scores[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+
+	result.Total = len(ids)
+	if offset >= len(ids) {
+		return result, nil
+	}
+	ids = ids[offset:]
+	if limit > 0 && len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	for _, id := range ids {
+		doc := idx.data.Docs[id]
+		result.Hits = append(result.Hits, Hit{
+			ID:     id,
+			Title:  doc.Title,
+			Score:  math.Round(scores[id]*1000) / 1000,
+			Stored: doc.Stored,
+		})
+	}
+	return result, nil
+}
+
+// Suggest completa la última palabra de lo escrito con las palabras más frecuentes del
+// catálogo y añade los productos que mejor coinciden
+func (idx *DiskIndex) Suggest(prefix string, limit int) ([]Suggestion, error) {
+	words := idx.analyzer.Words(prefix)
+	if len(words) == 0 {
+		return []Suggestion{}, nil
+	}
+	last := words[len(words)-1]
+	lead := strings.Join(words[:len(words)-1], " ")
+
+	idx.mu.RLock()
+	var completions []string
+	for w := range idx.data.Words {
+		if strings.HasPrefix(w, last) {
+			completions = append(completions, w)
+		}
+	}
+	sort.Slice(completions, func(i, j int) bool {
+		ci, cj := idx.data.Words[completions[i]], idx.data.Words[completions[j]]
+		if ci != cj {
+			return ci > cj
+		}
+		return completions[i] < completions[j]
+	})
+	idx.mu.RUnlock()
+
+	suggestions := []Suggestion{}
+	for _, w := range completions {
+		if len(suggestions) >= limit {
+			break
+		}
+		text := w
+		if lead != "" {
+			text = lead + " " + w
+		}
+		suggestions = append(suggestions, Suggestion{Text: text, Type: SuggestionTerm})
+	}
+
+	products, err := idx.Search(prefix, limit, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range products.Hits {
+		suggestions = append(suggestions, Suggestion{Text: h.Title, Type: SuggestionProduct, ID: h.ID})
+	}
+	return suggestions, nil
+}
+
+func uniqueWords(words []string) []string {
+	seen := make(map[string]bool, len(words))
+	var unique []string
+	for _, w := range words {
+		if !seen[w] {
+			seen[w] = true
+			unique = append(unique, w)
+		}
+	}
+	return unique
+}
+
+// withinDistance indica si la distancia de edición entre a y b es como mucho max.
+// Cuenta el intercambio de dos letras contiguas como una sola edición ("camsieta" -> "camiseta")
+func withinDistance(a, b string, max int) bool {
+	ra, rb := []rune(a), []rune(b)
+	if d := len(ra) - len(rb); d > max || -d > max {
+		return false
+	}
+
+	prevPrev := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				curr[j] = min(curr[j], prevPrev[j-2]+1)
+			}
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > max {
+			return false
+		}
+		prevPrev, prev, curr = prev, curr, prevPrev
+	}
+	return prev[len(rb)] <= max
+}
@@ -0,0 +1,96 @@
+package searchindex
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAllowedEdits(t *testing.T) {
+	cases := []struct {
+		term string
+		want int
+	}{
+		{"sol", 0},
+		{"mesa", 1},
+		{"lámpara", 1},
+		{"camiseta", 2},
+		{"zapatillas", 2},
+	}
+	for _, tc := range cases {
+		if got := allowedEdits(tc.term); got != tc.want {
+			t.Errorf("allowedEdits(%q) = %d, se esperaba %d", tc.term, got, tc.want)
+		}
+	}
+}
+
+func TestWithinDistance(t *testing.T) {
+	cases := []struct {
+		name string
+		a, b string
+		max  int
+		want bool
+	}{
+		{"iguales", "mesa", "mesa", 0, true},
+		{"sustitución sin ediciones permitidas", "masa", "mesa", 0, false},
+		{"sustitución", "camisata", "camiseta", 1, true},
+		{"letras intercambiadas cuentan como una", "camsieta", "camiseta", 1, true},
+		{"letra de menos", "camista", "camiseta", 1, true},
+		{"letra de más", "camiseeta", "camiseta", 1, true},
+		{"dos ediciones con una permitida", "cmsieta", "camiseta", 1, false},
+		{"dos ediciones", "cmsieta", "camiseta", 2, true},
+		{"longitudes demasiado distintas", "pantalon", "pantalones", 1, false},
+		{"cuenta letras y no bytes", "cancion", "canción", 1, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := withinDistance(tc.a, tc.b, tc.max); got != tc.want {
+				t.Fatalf("withinDistance(%q, %q, %d) = %v, se esperaba %v", tc.a, tc.b, tc.max, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSearchCorrectsTypos(t *testing.T) {
+	idx, err := OpenDiskIndex(t.TempDir(), NewSpanishAnalyzer(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+
+	idx.Index(
+		Document{ID: 1, Title: "Camiseta de algodón"},
+		Document{ID: 2, Title: "Pantalón vaquero"},
+	)
+
+	result, err := idx.Search("camsieta", 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Hits) != 1 || result.Hits[0].ID != 1 {
+		t.Fatalf("resultados %+v, se esperaba solo la camiseta", result.Hits)
+	}
+	if len(result.Corrections) == 0 {
+		t.Fatal("se esperaba la corrección del término mal escrito")
+	}
+}
+
+func TestOpenDiskIndexRejectsSecondProcess(t *testing.T) {
+	dir := t.TempDir()
+	idx, err := OpenDiskIndex(dir, NewSpanishAnalyzer(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := OpenDiskIndex(dir, NewSpanishAnalyzer(nil)); !errors.Is(err, ErrIndexLocked) {
+		t.Fatalf("error %v, se esperaba ErrIndexLocked con el índice abierto", err)
+	}
+
+	if err := idx.Close(); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := OpenDiskIndex(dir, NewSpanishAnalyzer(nil))
+	if err != nil {
+		t.Fatalf("error %v al reabrir tras Close", err)
+	}
+	reopened.Close()
+}
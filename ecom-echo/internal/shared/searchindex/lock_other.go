@@ -0,0 +1,19 @@
+// internal/shared/searchindex/lock_other.go
+
+//go:build !unix
+
+package searchindex
+
+import (
+	"fmt"
+	"os"
+)
+
+// lockDir crea el archivo de bloqueo; fuera de unix no se impide que otro proceso abra el índice
+func lockDir(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("error al abrir el bloqueo del índice: %w", err)
+	}
+	return f, nil
+}
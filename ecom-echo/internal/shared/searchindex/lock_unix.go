@@ -0,0 +1,29 @@
+// internal/shared/searchindex/lock_unix.go
+
+//go:build unix
+
+package searchindex
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// lockDir bloquea el directorio del índice para este proceso. El bloqueo lo libera el
+// sistema al cerrar el archivo o al terminar el proceso, aunque sea de forma abrupta
+func lockDir(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("error al abrir el bloqueo del índice: %w", err)
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, ErrIndexLocked
+		}
+		return nil, fmt.Errorf("error al bloquear el índice: %w", err)
+	}
+	return f, nil
+}
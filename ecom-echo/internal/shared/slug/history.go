@@ -0,0 +1,37 @@
+// internal/shared/slug/history.go
+package slug
+
+import (
+	"context"
+	"database/sql"
+	"ecom/internal/shared/errors"
+)
+
+// Tipos de entidad con historial de slugs
+const (
+	EntityProduct  = "product"
+	EntityCategory = "category"
+)
+
+// SaveHistory guarda oldSlug como slug antiguo de la entidad para redirigirlo al actual.
+// Si newSlug era un slug antiguo se elimina del historial, ya que vuelve a estar en uso
+func SaveHistory(ctx context.Context, tx *sql.Tx, entityType string, entityID int64, oldSlug, newSlug string) error {
+	if oldSlug == newSlug {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM slug_history WHERE entity_type = ? AND slug = ?
+	`, entityType, newSlug); err != nil {
+		return errors.NewMysqlError(err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO slug_history (entity_type, entity_id, slug, created_at)
+		VALUES (?, ?, ?, NOW())
+		ON DUPLICATE KEY UPDATE entity_id = VALUES(entity_id), created_at = NOW()
+	`, entityType, entityID, oldSlug); err != nil {
+		return errors.NewMysqlError(err)
+	}
+	return nil
+}
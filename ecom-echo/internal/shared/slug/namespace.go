@@ -0,0 +1,88 @@
+// internal/shared/slug/namespace.go
+package slug
+
+import (
+	"context"
+	"ecom/internal/shared/errors"
+	"sort"
+	"strings"
+)
+
+// Lister lista los slugs de un tipo de entidad que empiezan por el prefijo, actuales y antiguos,
+// salvo los de la entidad excludeID
+type Lister interface {
+	ListSlugsWithPrefix(ctx context.Context, prefix string, excludeID int64) ([]string, error)
+}
+
+// Namespace reúne los slugs de todas las entidades que resuelve /resolve/:slug. Un slug, actual o
+// antiguo, sólo puede pertenecer a una entidad de cualquiera de los tipos
+type Namespace struct {
+	listers map[string]Lister
+	types   []string
+}
+
+func NewNamespace(listers map[string]Lister) *Namespace {
+	types := make([]string, 0, len(listers))
+	for entityType := range listers {
+		types = append(types, entityType)
+	}
+	sort.Strings(types)
+	return &Namespace{listers: listers, types: types}
+}
+
+// Taken devuelve los slugs ocupados que empiezan por el prefijo. Sólo se excluyen los de la
+// entidad id del tipo entityType
+func (n *Namespace) Taken(ctx context.Context, prefix, entityType string, id int64) ([]string, error) {
+	var taken []string
+	for _, t := range n.types {
+		values, err := n.list(ctx, prefix, t, entityType, id)
+		if err != nil {
+			return nil, err
+		}
+		taken = append(taken, values...)
+	}
+	return taken, nil
+}
+
+// Generate devuelve base o, si ya está ocupado por cualquier entidad, base con el primer sufijo libre
+func (n *Namespace) Generate(ctx context.Context, base, entityType string, id int64) (string, error) {
+	taken, err := n.Taken(ctx, base, entityType, id)
+	if err != nil {
+		return "", err
+	}
+	return Unique(base, taken), nil
+}
+
+// Check comprueba que ninguna otra entidad, de ningún tipo, use ni haya usado el slug
+func (n *Namespace) Check(ctx context.Context, value, entityType string, id int64) error {
+	for _, t := range n.types {
+		values, err := n.list(ctx, value, t, entityType, id)
+		if err != nil {
+			return err
+		}
+		for _, taken := range values {
+			if strings.EqualFold(taken, value) {
+				return errors.NewConflictError(conflictMessage(t))
+			}
+		}
+	}
+	return nil
+}
+
+func (n *Namespace) list(ctx context.Context, prefix, listType, entityType string, id int64) ([]string, error) {
+	excludeID := int64(0)
+	if listType == entityType {
+		excludeID = id
+	}
+	return n.listers[listType].ListSlugsWithPrefix(ctx, prefix, excludeID)
+}
+
+func conflictMessage(entityType string) string {
+	switch entityType {
+	case EntityProduct:
+		return "Ya existe un producto con ese slug"
+	case EntityCategory:
+		return "Ya existe una categoría con ese slug"
+	}
+	return "El slug ya está en uso"
+}
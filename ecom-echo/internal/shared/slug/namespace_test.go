@@ -0,0 +1,90 @@
+package slug
+
+import (
+	"context"
+	"ecom/internal/shared/errors"
+	"strings"
+	"testing"
+)
+
+// fakeLister slugs por id de entidad, actuales o antiguos
+type fakeLister map[int64][]string
+
+func (f fakeLister) ListSlugsWithPrefix(_ context.Context, prefix string, excludeID int64) ([]string, error) {
+	var values []string
+	for id, slugs := range f {
+		if id == excludeID {
+			continue
+		}
+		for _, s := range slugs {
+			if strings.HasPrefix(s, prefix) {
+				values = append(values, s)
+			}
+		}
+	}
+	return values, nil
+}
+
+func newTestNamespace() *Namespace {
+	return NewNamespace(map[string]Lister{
+		EntityProduct:  fakeLister{1: {"camiseta-basica"}, 5: {"zapatillas", "zapatillas-old"}},
+		EntityCategory: fakeLister{5: {"camisetas"}, 7: {"ofertas", "rebajas"}},
+	})
+}
+
+func TestNamespaceCheckCollidesAcrossEntityTypes(t *testing.T) {
+	ns := newTestNamespace()
+	ctx := context.Background()
+
+	cases := []struct {
+		name       string
+		value      string
+		entityType string
+		id         int64
+		conflict   string
+	}{
+		{"producto con slug de categoría", "camisetas", EntityProduct, 0, "Ya existe una categoría con ese slug"},
+		{"producto con slug antiguo de categoría", "rebajas", EntityProduct, 0, "Ya existe una categoría con ese slug"},
+		{"categoría con slug de producto", "zapatillas", EntityCategory, 0, "Ya existe un producto con ese slug"},
+		{"categoría con slug antiguo de producto", "zapatillas-old", EntityCategory, 0, "Ya existe un producto con ese slug"},
+		{"mismo id en otro tipo no excluye", "camisetas", EntityProduct, 5, "Ya existe una categoría con ese slug"},
+		{"su propio slug", "ofertas", EntityCategory, 7, ""},
+		{"slug libre", "pantalones", EntityProduct, 0, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ns.Check(ctx, tc.value, tc.entityType, tc.id)
+			if tc.conflict == "" {
+				if err != nil {
+					t.Fatalf("Check(%q) = %v, se esperaba nil", tc.value, err)
+				}
+				return
+			}
+			appErr, ok := err.(*errors.AppError)
+			if !ok || appErr.Code != 409 || appErr.Message != tc.conflict {
+				t.Fatalf("Check(%q) = %v, se esperaba conflicto %q", tc.value, err, tc.conflict)
+			}
+		})
+	}
+}
+
+func TestNamespaceGenerateSkipsOtherEntityTypes(t *testing.T) {
+	ns := newTestNamespace()
+	ctx := context.Background()
+
+	got, err := ns.Generate(ctx, "camisetas", EntityProduct, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "camisetas-2" {
+		t.Fatalf("Generate = %q, se esperaba camisetas-2", got)
+	}
+
+	got, err = ns.Generate(ctx, "zapatillas", EntityCategory, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "zapatillas-2" {
+		t.Fatalf("Generate = %q, se esperaba zapatillas-2", got)
+	}
+}
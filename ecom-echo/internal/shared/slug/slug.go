@@ -0,0 +1,63 @@
+// internal/shared/slug/slug.go
+package slug
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxLength longitud máxima de un slug generado, sin contar el sufijo de unicidad
+const MaxLength = 200
+
+var transliterator = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ä", "a", "ã", "a", "å", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "ö", "o", "õ", "o", "ø", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ñ", "n", "ç", "c", "ý", "y", "ÿ", "y",
+	"ß", "ss", "æ", "ae", "œ", "oe",
+	"&", " y ",
+)
+
+// Make genera un slug a partir de un nombre: minúsculas, sin acentos y con guiones
+// entre palabras, por ejemplo "Camisetas Niño & Niña" -> "camisetas-nino-y-nina".
+// Devuelve "" si el nombre no tiene letras ni dígitos latinos
+func Make(name string) string {
+	text := transliterator.Replace(strings.ToLower(name))
+
+	var b strings.Builder
+	dash := false
+	for _, r := range text {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+
+	slug := b.String()
+	if len(slug) > MaxLength {
+		slug = strings.TrimRight(slug[:MaxLength], "-")
+	}
+	return slug
+}
+
+// Unique devuelve base o, si ya está ocupado, base con el primer sufijo "-2", "-3"... libre.
+// La comparación con taken no distingue mayúsculas
+func Unique(base string, taken []string) string {
+	used := make(map[string]bool, len(taken))
+	for _, t := range taken {
+		used[strings.ToLower(t)] = true
+	}
+
+	candidate := base
+	for n := 2; used[strings.ToLower(candidate)]; n++ {
+		candidate = fmt.Sprintf("%s-%d", base, n)
+	}
+	return candidate
+}
@@ -0,0 +1,181 @@
+// internal/shared/spreadsheet/spreadsheet.go
+package spreadsheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// Table es el contenido tabular de un archivo: cabecera normalizada y filas
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+// Column devuelve la posición de una columna de la cabecera o -1 si no existe
+func (t *Table) Column(name string) int {
+	for i, h := range t.Header {
+		if h == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Value devuelve el valor de una celda por nombre de columna
+func (t *Table) Value(row []string, name string) string {
+	i := t.Column(name)
+	if i < 0 || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+// FormatFromFileName detecta el formato a partir de la extensión del archivo
+func FormatFromFileName(fileName string) (string, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".xlsx":
+		return FormatXLSX, nil
+	}
+	return "", fmt.Errorf("formato de archivo no soportado: %s", fileName)
+}
+
+// Read lee un archivo CSV o XLSX (primera hoja). La primera fila es la cabecera.
+func Read(r io.Reader, format string) (*Table, error) {
+	var records [][]string
+
+	switch format {
+	case FormatCSV:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		rows, err := reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("error leyendo CSV: %w", err)
+		}
+		records = rows
+	case FormatXLSX:
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("error leyendo XLSX: %w", err)
+		}
+		defer f.Close()
+
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, fmt.Errorf("el archivo XLSX no tiene hojas")
+		}
+		rows, err := f.GetRows(sheets[0])
+		if err != nil {
+			return nil, fmt.Errorf("error leyendo XLSX: %w", err)
+		}
+		records = rows
+	default:
+		return nil, fmt.Errorf("formato no soportado: %s", format)
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("el archivo está vacío")
+	}
+
+	table := &Table{Header: make([]string, len(records[0]))}
+	for i, h := range records[0] {
+		// Quitar BOM y normalizar nombres de columna
+		h = strings.TrimPrefix(h, "\ufeff")
+		table.Header[i] = strings.ToLower(strings.TrimSpace(h))
+	}
+
+	for _, row := range records[1:] {
+		if isEmptyRow(row) {
+			continue
+		}
+		table.Rows = append(table.Rows, row)
+	}
+
+	return table, nil
+}
+
+// Write escribe la tabla en el formato indicado
+func Write(w io.Writer, format string, t *Table) error {
+	switch format {
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(t.Header); err != nil {
+			return err
+		}
+		if err := writer.WriteAll(t.Rows); err != nil {
+			return err
+		}
+		return writer.Error()
+	case FormatXLSX:
+		f := excelize.NewFile()
+		defer f.Close()
+
+		sheet := f.GetSheetName(0)
+		stream, err := f.NewStreamWriter(sheet)
+		if err != nil {
+			return err
+		}
+		if err := stream.SetRow("A1", toCells(t.Header)); err != nil {
+			return err
+		}
+		for i, row := range t.Rows {
+			cell, err := excelize.CoordinatesToCellName(1, i+2)
+			if err != nil {
+				return err
+			}
+			if err := stream.SetRow(cell, toCells(row)); err != nil {
+				return err
+			}
+		}
+		if err := stream.Flush(); err != nil {
+			return err
+		}
+
+		var buf bytes.Buffer
+		if err := f.Write(&buf); err != nil {
+			return err
+		}
+		_, err = w.Write(buf.Bytes())
+		return err
+	}
+	return fmt.Errorf("formato no soportado: %s", format)
+}
+
+// ContentType devuelve el tipo MIME del formato
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+func toCells(row []string) []interface{} {
+	cells := make([]interface{}, len(row))
+	for i, v := range row {
+		cells[i] = v
+	}
+	return cells
+}
+
+func isEmptyRow(row []string) bool {
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,358 @@
+// internal/shared/stock/ledger.go
+package stock
+
+import (
+	"context"
+	"database/sql"
+	"ecom/internal/shared/errors"
+	"fmt"
+	"sort"
+)
+
+// Tipos de movimiento del libro de stock
+const (
+	MovementReceipt    = "receipt"
+	MovementSale       = "sale"
+	MovementReturn     = "return"
+	MovementAdjustment = "adjustment"
+	MovementTransfer   = "transfer"
+)
+
+// ActorSystem autor de los movimientos que no inicia una persona (stock inicial, saldos, etc.)
+const ActorSystem = "system"
+
+// Movement apunte del libro. Quantity lleva signo: positivo entra en el almacén, negativo sale
+type Movement struct {
+	IDWarehouse            int64
+	IDProductVariant       int64
+	Type                   string
+	Quantity               int
+	IDCounterpartWarehouse *int64
+	Reason                 string
+	Actor                  string
+	Reference              string
+}
+
+// Post registra el movimiento dentro de la transacción, actualiza el stock del almacén y recalcula
+// el stock de la variante como la suma de sus almacenes activos. Ningún almacén puede quedar en negativo.
+// Devuelve el id del movimiento
+func Post(ctx context.Context, tx *sql.Tx, m Movement) (int64, error) {
+	if m.Quantity == 0 {
+		return 0, errors.NewBadRequestError("La cantidad del movimiento no puede ser cero")
+	}
+
+	if err := checkWarehouse(ctx, tx, m.IDWarehouse); err != nil {
+		return 0, err
+	}
+	if err := checkVariant(ctx, tx, m.IDProductVariant); err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT IGNORE INTO warehouse_stock (id_warehouse, id_product_variant, quantity) VALUES (?, ?, 0)
+	`, m.IDWarehouse, m.IDProductVariant); err != nil {
+		return 0, errors.NewMysqlError(err)
+	}
+
+	var current int
+	if err := tx.QueryRowContext(ctx, `
+		SELECT quantity FROM warehouse_stock WHERE id_warehouse = ? AND id_product_variant = ? FOR UPDATE
+	`, m.IDWarehouse, m.IDProductVariant).Scan(&current); err != nil {
+		return 0, errors.NewMysqlError(err)
+	}
+
+	balance := current + m.Quantity
+	if balance < 0 {
+		return 0, errors.NewConflictError(fmt.Sprintf(
+			"Stock insuficiente de la variante %d en el almacén %d: disponible %d", m.IDProductVariant, m.IDWarehouse, current))
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO stock_movements
+			(id_warehouse, id_product_variant, type, quantity, balance_after, id_counterpart_warehouse, reason, actor, reference, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''), NOW())
+	`, m.IDWarehouse, m.IDProductVariant, m.Type, m.Quantity, balance, m.IDCounterpartWarehouse, m.Reason, m.Actor, m.Reference)
+	if err != nil {
+		return 0, errors.NewMysqlError(err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, errors.NewInternalError("Error al obtener el id creado", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE warehouse_stock SET quantity = ? WHERE id_warehouse = ? AND id_product_variant = ?
+	`, balance, m.IDWarehouse, m.IDProductVariant); err != nil {
+		return 0, errors.NewMysqlError(err)
+	}
+
+	if err := refreshVariantStock(ctx, tx, m.IDProductVariant); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// Consume registra la venta de quantity unidades de la variante. Si no se indica almacén se reparte
+// entre los almacenes activos, empezando por el predeterminado y siguiendo por prioridad
+func Consume(ctx context.Context, tx *sql.Tx, variantID int64, quantity int, warehouseID *int64, actor, reference string) error {
+	if warehouseID != nil {
+		_, err := Post(ctx, tx, Movement{
+			IDWarehouse:      *warehouseID,
+			IDProductVariant: variantID,
+			Type:             MovementSale,
+			Quantity:         -quantity,
+			Reason:           "Venta",
+			Actor:            actor,
+			Reference:        reference,
+		})
+		return err
+	}
+
+	levels, available, err := lockLevels(ctx, tx, variantID)
+	if err != nil {
+		return err
+	}
+	if available < quantity {
+		return errors.NewConflictError(fmt.Sprintf("Stock insuficiente para la variante %d", variantID))
+	}
+
+	return drain(ctx, tx, levels, quantity, Movement{
+		IDProductVariant: variantID,
+		Type:             MovementSale,
+		Reason:           "Venta",
+		Actor:            actor,
+		Reference:        reference,
+	})
+}
+
+// level existencias de una variante en un almacén activo
+type level struct {
+	warehouseID int64
+	quantity    int
+	isDefault   bool
+	priority    int
+}
+
+// lockLevels bloquea las existencias de la variante en los almacenes activos y las devuelve en orden
+// de salida: el predeterminado primero y después por prioridad. Devuelve también el total
+func lockLevels(ctx context.Context, tx *sql.Tx, variantID int64) ([]level, int, error) {
+	// Las filas se bloquean por id de almacén, el mismo orden que en cualquier otro movimiento,
+	// y después se reparten según la preferencia de los almacenes
+	rows, err := tx.QueryContext(ctx, `
+		SELECT ws.id_warehouse, ws.quantity, w.is_default, w.priority
+		FROM warehouse_stock ws
+		INNER JOIN warehouses w ON w.id = ws.id_warehouse
+		WHERE ws.id_product_variant = ? AND w.active = true AND w.deleted_at IS NULL
+		ORDER BY ws.id_warehouse
+		FOR UPDATE
+	`, variantID)
+	if err != nil {
+		return nil, 0, errors.NewMysqlError(err)
+	}
+
+	var levels []level
+	available := 0
+	for rows.Next() {
+		var l level
+		if err := rows.Scan(&l.warehouseID, &l.quantity, &l.isDefault, &l.priority); err != nil {
+			rows.Close()
+			return nil, 0, err
+		}
+		levels = append(levels, l)
+		available += l.quantity
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	sort.SliceStable(levels, func(i, j int) bool {
+		if levels[i].isDefault != levels[j].isDefault {
+			return levels[i].isDefault
+		}
+		return levels[i].priority > levels[j].priority
+	})
+	return levels, available, nil
+}
+
+// drain saca quantity unidades recorriendo los almacenes en orden, con un movimiento como m en
+// cada almacén del que toma unidades
+func drain(ctx context.Context, tx *sql.Tx, levels []level, quantity int, m Movement) error {
+	pending := quantity
+	for _, l := range levels {
+		if pending == 0 {
+			break
+		}
+		take := l.quantity
+		if take > pending {
+			take = pending
+		}
+		if take <= 0 {
+			continue
+		}
+		m.IDWarehouse = l.warehouseID
+		m.Quantity = -take
+		if _, err := Post(ctx, tx, m); err != nil {
+			return err
+		}
+		pending -= take
+	}
+	return nil
+}
+
+// DefaultWarehouseID devuelve el almacén predeterminado, donde entra el stock inicial de las variantes
+func DefaultWarehouseID(ctx context.Context, tx *sql.Tx) (int64, error) {
+	var id int64
+	err := tx.QueryRowContext(ctx, `
+		SELECT id FROM warehouses WHERE is_default = true AND active = true AND deleted_at IS NULL LIMIT 1
+	`).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, errors.NewBadRequestError("No hay un almacén predeterminado activo")
+	}
+	if err != nil {
+		return 0, errors.NewMysqlError(err)
+	}
+	return id, nil
+}
+
+// Receive da entrada al stock inicial de una variante nueva en el almacén predeterminado
+func Receive(ctx context.Context, tx *sql.Tx, variantID int64, quantity int, reason, actor string) error {
+	if quantity <= 0 {
+		return nil
+	}
+
+	warehouseID, err := DefaultWarehouseID(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	_, err = Post(ctx, tx, Movement{
+		IDWarehouse:      warehouseID,
+		IDProductVariant: variantID,
+		Type:             MovementReceipt,
+		Quantity:         quantity,
+		Reason:           reason,
+		Actor:            actor,
+	})
+	return err
+}
+
+// SetTotal ajusta el stock total de la variante a target con movimientos de ajuste. Las subidas entran
+// en el almacén predeterminado y las bajadas salen de los almacenes en el mismo orden que las ventas.
+// Sirve a las cargas masivas que, como la importación de catálogo, indican el total
+func SetTotal(ctx context.Context, tx *sql.Tx, variantID int64, target int, reason, actor string) error {
+	var current int
+	err := tx.QueryRowContext(ctx,
+		"SELECT COALESCE(stock, 0) FROM product_variants WHERE id = ? AND deleted_at IS NULL FOR UPDATE", variantID).Scan(&current)
+	if err == sql.ErrNoRows {
+		return errors.NewNotFoundError(fmt.Sprintf("Variante %d no encontrada", variantID))
+	}
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	if current == target {
+		return nil
+	}
+
+	if target < current {
+		levels, available, err := lockLevels(ctx, tx, variantID)
+		if err != nil {
+			return err
+		}
+		if available < current-target {
+			return errors.NewConflictError(fmt.Sprintf("Stock insuficiente para la variante %d", variantID))
+		}
+		return drain(ctx, tx, levels, current-target, Movement{
+			IDProductVariant: variantID,
+			Type:             MovementAdjustment,
+			Reason:           reason,
+			Actor:            actor,
+		})
+	}
+
+	warehouseID, err := DefaultWarehouseID(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	_, err = Post(ctx, tx, Movement{
+		IDWarehouse:      warehouseID,
+		IDProductVariant: variantID,
+		Type:             MovementAdjustment,
+		Quantity:         target - current,
+		Reason:           reason,
+		Actor:            actor,
+	})
+	return err
+}
+
+func checkWarehouse(ctx context.Context, tx *sql.Tx, id int64) error {
+	var active bool
+	err := tx.QueryRowContext(ctx,
+		"SELECT active FROM warehouses WHERE id = ? AND deleted_at IS NULL", id).Scan(&active)
+	if err == sql.ErrNoRows {
+		return errors.NewNotFoundError(fmt.Sprintf("Almacén %d no encontrado", id))
+	}
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	if !active {
+		return errors.NewBadRequestError(fmt.Sprintf("El almacén %d no está activo", id))
+	}
+	return nil
+}
+
+// checkVariant comprueba que la variante exista y no sea un pack, cuyo stock se calcula de sus componentes.
+// Bloquea la fila de la variante antes que la de su almacén, el mismo orden que las reservas
+func checkVariant(ctx context.Context, tx *sql.Tx, id int64) error {
+	var isBundle bool
+	err := tx.QueryRowContext(ctx, `
+		SELECT EXISTS(SELECT 1 FROM product_bundles WHERE id_product_variant = pv.id)
+		FROM product_variants pv
+		WHERE pv.id = ? AND pv.deleted_at IS NULL
+		FOR UPDATE
+	`, id).Scan(&isBundle)
+	if err == sql.ErrNoRows {
+		return errors.NewNotFoundError(fmt.Sprintf("Variante %d no encontrada", id))
+	}
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	if isBundle {
+		return errors.NewBadRequestError(fmt.Sprintf("La variante %d es un pack; su stock se calcula a partir de sus componentes", id))
+	}
+	return nil
+}
+
+// querySellableStock suma las existencias de la variante en los almacenes activos
+const querySellableStock = `
+	SELECT COALESCE(SUM(ws.quantity), 0)
+	FROM warehouse_stock ws
+	INNER JOIN warehouses w ON w.id = ws.id_warehouse
+	WHERE ws.id_product_variant = pv.id AND w.active = true AND w.deleted_at IS NULL
+`
+
+// refreshVariantStock guarda en la variante la suma de sus existencias en los almacenes activos
+func refreshVariantStock(ctx context.Context, tx *sql.Tx, variantID int64) error {
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE product_variants pv
+		SET pv.stock = (`+querySellableStock+`), pv.updated_at = NOW()
+		WHERE pv.id = ?
+	`, variantID); err != nil {
+		return errors.NewMysqlError(err)
+	}
+	return nil
+}
+
+// RefreshWarehouseVariants recalcula el stock de las variantes del almacén cuando éste se activa,
+// se desactiva o se elimina
+func RefreshWarehouseVariants(ctx context.Context, tx *sql.Tx, warehouseID int64) error {
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE product_variants pv
+		SET pv.stock = (`+querySellableStock+`), pv.updated_at = NOW()
+		WHERE pv.id IN (SELECT id_product_variant FROM warehouse_stock WHERE id_warehouse = ?)
+	`, warehouseID); err != nil {
+		return errors.NewMysqlError(err)
+	}
+	return nil
+}
@@ -0,0 +1,388 @@
+// internal/shared/stock/reservations.go
+package stock
+
+import (
+	"context"
+	"database/sql"
+	"ecom/internal/shared/errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Estados de una reserva. Sólo las activas sin caducar cuentan como stock reservado
+const (
+	ReservationActive    = "active"
+	ReservationConverted = "converted"
+	ReservationReleased  = "released"
+	ReservationExpired   = "expired"
+)
+
+// Todas las operaciones que cambian el stock o las reservas de una variante bloquean antes su fila
+// de product_variants, varias a la vez siempre en orden de id. Así las comprobaciones de disponible
+// (stock menos reservas) no se pisan entre peticiones simultáneas
+
+// Expand devuelve las variantes con stock propio que consume una línea y sus unidades: la propia
+// variante o, si es un pack, sus componentes
+func Expand(ctx context.Context, tx *sql.Tx, variantID int64, quantity int) (map[int64]int, error) {
+	var exists bool
+	if err := tx.QueryRowContext(ctx,
+		"SELECT EXISTS(SELECT 1 FROM product_variants WHERE id = ? AND deleted_at IS NULL)", variantID).Scan(&exists); err != nil {
+		return nil, errors.NewMysqlError(err)
+	}
+	if !exists {
+		return nil, errors.NewNotFoundError(fmt.Sprintf("Variante %d no encontrada", variantID))
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT c.id_component_variant, c.quantity
+		FROM product_bundle_components c
+		WHERE c.id_bundle_variant = ?
+	`, variantID)
+	if err != nil {
+		return nil, errors.NewMysqlError(err)
+	}
+	defer rows.Close()
+
+	units := make(map[int64]int)
+	for rows.Next() {
+		var id int64
+		var perBundle int
+		if err := rows.Scan(&id, &perBundle); err != nil {
+			return nil, err
+		}
+		units[id] += perBundle * quantity
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(units) == 0 {
+		units[variantID] = quantity
+	}
+	return units, nil
+}
+
+// Availability stock de una variante bloqueada dentro de la transacción
+type Availability struct {
+	OnHand   int
+	Reserved int
+}
+
+// Available unidades que se pueden vender o reservar
+func (a Availability) Available() int {
+	if a.OnHand < a.Reserved {
+		return 0
+	}
+	return a.OnHand - a.Reserved
+}
+
+// LockVariants bloquea las variantes en orden de id y libera antes sus reservas caducadas, de modo
+// que lo reservado sólo incluye reservas vigentes
+func LockVariants(ctx context.Context, tx *sql.Tx, ids []int64) (map[int64]*Availability, error) {
+	sorted := append([]int64(nil), ids...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	locked := make(map[int64]*Availability, len(sorted))
+	for _, id := range sorted {
+		if _, ok := locked[id]; ok {
+			continue
+		}
+		a := &Availability{}
+		err := tx.QueryRowContext(ctx, `
+			SELECT COALESCE(stock, 0), reserved FROM product_variants WHERE id = ? FOR UPDATE
+		`, id).Scan(&a.OnHand, &a.Reserved)
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError(fmt.Sprintf("Variante %d no encontrada", id))
+		}
+		if err != nil {
+			return nil, errors.NewMysqlError(err)
+		}
+
+		expired, err := expireVariant(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		a.Reserved -= expired
+		locked[id] = a
+	}
+	return locked, nil
+}
+
+// Reserve sustituye la reserva de una línea de carrito por quantity unidades de la variante durante
+// ttl. Con quantity 0 sólo libera la reserva anterior. Las unidades que ya tenía la línea cuentan
+// como disponibles, así que volver a reservar la misma cantidad sólo renueva la caducidad
+func Reserve(ctx context.Context, tx *sql.Tx, cartID, lineVariantID int64, quantity int, ttl time.Duration) error {
+	units := map[int64]int{}
+	if quantity > 0 {
+		expanded, err := Expand(ctx, tx, lineVariantID, quantity)
+		if err != nil {
+			return err
+		}
+		units = expanded
+	}
+
+	previous, err := lineReservations(ctx, tx, cartID, []int64{lineVariantID})
+	if err != nil {
+		return err
+	}
+
+	ids := make([]int64, 0, len(units)+len(previous))
+	for id := range units {
+		ids = append(ids, id)
+	}
+	for id := range previous {
+		ids = append(ids, id)
+	}
+	locked, err := LockVariants(ctx, tx, ids)
+	if err != nil {
+		return err
+	}
+
+	// Las cantidades se vuelven a leer con las variantes bloqueadas
+	previous, err = lineReservations(ctx, tx, cartID, []int64{lineVariantID})
+	if err != nil {
+		return err
+	}
+	if err := closeReservations(ctx, tx, cartID, []int64{lineVariantID}, ReservationReleased, ""); err != nil {
+		return err
+	}
+	for id, held := range previous {
+		if err := addReserved(ctx, tx, id, -held); err != nil {
+			return err
+		}
+		if a := locked[id]; a != nil {
+			a.Reserved -= held
+		}
+	}
+
+	for id, need := range units {
+		if available := locked[id].Available(); available < need {
+			return errors.NewConflictError(fmt.Sprintf(
+				"Stock insuficiente para la variante %d: disponibles %d", id, available))
+		}
+	}
+
+	for id, need := range units {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO stock_reservations
+				(id_cart, id_line_variant, id_product_variant, quantity, status, expires_at, created_at, updated_at)
+			VALUES (?, ?, ?, ?, 'active', DATE_ADD(NOW(), INTERVAL ? SECOND), NOW(), NOW())
+		`, cartID, lineVariantID, id, need, int64(ttl/time.Second)); err != nil {
+			return errors.NewMysqlError(err)
+		}
+		if err := addReserved(ctx, tx, id, need); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReleaseCart libera las reservas activas del carrito y devuelve las variantes afectadas
+func ReleaseCart(ctx context.Context, tx *sql.Tx, cartID int64) ([]int64, error) {
+	held, err := lineReservations(ctx, tx, cartID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, 0, len(held))
+	for id := range held {
+		ids = append(ids, id)
+	}
+	if _, err := LockVariants(ctx, tx, ids); err != nil {
+		return nil, err
+	}
+
+	if held, err = lineReservations(ctx, tx, cartID, nil); err != nil {
+		return nil, err
+	}
+	if err := closeReservations(ctx, tx, cartID, nil, ReservationReleased, ""); err != nil {
+		return nil, err
+	}
+	for id, quantity := range held {
+		if err := addReserved(ctx, tx, id, -quantity); err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
+}
+
+// Sell registra la venta de las unidades por variante. Si se indica el carrito, las reservas activas
+// de las líneas vendidas (lineVariantIDs) se convierten en la venta: sus unidades dejan de estar
+// reservadas y pueden venderse. Las reservas de las demás líneas del carrito siguen activas y las
+// reservadas por otros carritos siguen sin poder venderse
+func Sell(ctx context.Context, tx *sql.Tx, units map[int64]int, cartID *int64, lineVariantIDs []int64, warehouseID *int64, actor, reference string) error {
+	ids := make([]int64, 0, len(units))
+	for id := range units {
+		ids = append(ids, id)
+	}
+
+	converting := cartID != nil && len(lineVariantIDs) > 0
+	var held map[int64]int
+	if converting {
+		var err error
+		if held, err = lineReservations(ctx, tx, *cartID, lineVariantIDs); err != nil {
+			return err
+		}
+		for id := range held {
+			ids = append(ids, id)
+		}
+	}
+
+	locked, err := LockVariants(ctx, tx, ids)
+	if err != nil {
+		return err
+	}
+
+	if converting {
+		if held, err = lineReservations(ctx, tx, *cartID, lineVariantIDs); err != nil {
+			return err
+		}
+		if err := closeReservations(ctx, tx, *cartID, lineVariantIDs, ReservationConverted, reference); err != nil {
+			return err
+		}
+		for id, quantity := range held {
+			if err := addReserved(ctx, tx, id, -quantity); err != nil {
+				return err
+			}
+			if a := locked[id]; a != nil {
+				a.Reserved -= quantity
+			}
+		}
+	}
+
+	sold := make([]int64, 0, len(units))
+	for id := range units {
+		sold = append(sold, id)
+	}
+	sort.Slice(sold, func(i, j int) bool { return sold[i] < sold[j] })
+
+	for _, id := range sold {
+		need := units[id]
+		if available := locked[id].Available(); available < need {
+			return errors.NewConflictError(fmt.Sprintf(
+				"Stock insuficiente para la variante %d: disponibles %d", id, available))
+		}
+		if err := Consume(ctx, tx, id, need, warehouseID, actor, reference); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExpiredVariantIDs devuelve las variantes con reservas activas ya caducadas
+func ExpiredVariantIDs(ctx context.Context, db *sql.DB, limit int) ([]int64, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT DISTINCT id_product_variant FROM stock_reservations
+		WHERE status = 'active' AND expires_at <= NOW()
+		ORDER BY id_product_variant
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, errors.NewMysqlError(err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// expireVariant marca como caducadas las reservas vencidas de una variante ya bloqueada y descuenta
+// sus unidades de lo reservado. Devuelve las unidades liberadas
+func expireVariant(ctx context.Context, tx *sql.Tx, variantID int64) (int, error) {
+	var expired int
+	if err := tx.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(quantity), 0) FROM stock_reservations
+		WHERE id_product_variant = ? AND status = 'active' AND expires_at <= NOW()
+		FOR UPDATE
+	`, variantID).Scan(&expired); err != nil {
+		return 0, errors.NewMysqlError(err)
+	}
+	if expired == 0 {
+		return 0, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE stock_reservations SET status = 'expired', updated_at = NOW()
+		WHERE id_product_variant = ? AND status = 'active' AND expires_at <= NOW()
+	`, variantID); err != nil {
+		return 0, errors.NewMysqlError(err)
+	}
+	if err := addReserved(ctx, tx, variantID, -expired); err != nil {
+		return 0, err
+	}
+	return expired, nil
+}
+
+// lineReservations suma por variante las reservas activas del carrito o, si se indican, sólo las de
+// esas líneas
+func lineReservations(ctx context.Context, tx *sql.Tx, cartID int64, lineVariantIDs []int64) (map[int64]int, error) {
+	query := `
+		SELECT id_product_variant, SUM(quantity) FROM stock_reservations
+		WHERE id_cart = ? AND status = 'active'`
+	args := []interface{}{cartID}
+	query, args = filterLines(query, args, lineVariantIDs)
+	query += " GROUP BY id_product_variant"
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.NewMysqlError(err)
+	}
+	defer rows.Close()
+
+	held := make(map[int64]int)
+	for rows.Next() {
+		var id int64
+		var quantity int
+		if err := rows.Scan(&id, &quantity); err != nil {
+			return nil, err
+		}
+		held[id] = quantity
+	}
+	return held, rows.Err()
+}
+
+func closeReservations(ctx context.Context, tx *sql.Tx, cartID int64, lineVariantIDs []int64, status, reference string) error {
+	query := `
+		UPDATE stock_reservations SET status = ?, reference = COALESCE(NULLIF(?, ''), reference), updated_at = NOW()
+		WHERE id_cart = ? AND status = 'active'`
+	args := []interface{}{status, reference, cartID}
+	query, args = filterLines(query, args, lineVariantIDs)
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return errors.NewMysqlError(err)
+	}
+	return nil
+}
+
+// filterLines limita la consulta a las líneas indicadas; sin líneas abarca todo el carrito
+func filterLines(query string, args []interface{}, lineVariantIDs []int64) (string, []interface{}) {
+	if len(lineVariantIDs) == 0 {
+		return query, args
+	}
+	query += " AND id_line_variant IN (?" + strings.Repeat(", ?", len(lineVariantIDs)-1) + ")"
+	for _, id := range lineVariantIDs {
+		args = append(args, id)
+	}
+	return query, args
+}
+
+func addReserved(ctx context.Context, tx *sql.Tx, variantID int64, delta int) error {
+	if delta == 0 {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE product_variants SET reserved = GREATEST(reserved + ?, 0) WHERE id = ?
+	`, delta, variantID); err != nil {
+		return errors.NewMysqlError(err)
+	}
+	return nil
+}
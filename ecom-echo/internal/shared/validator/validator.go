@@ -2,6 +2,8 @@
 package validator
 
 import (
+	"ecom/internal/shared/money"
+	"reflect"
 	"regexp"
 
 	"github.com/go-playground/validator/v10"
@@ -26,6 +28,14 @@ func NewValidator() *CustomValidator {
         return match
     })
     
+    // Permitir min/max/gt sobre importes decimales
+    v.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+        if d, ok := field.Interface().(money.Decimal); ok {
+            return d.InexactFloat64()
+        }
+        return nil
+    }, money.Decimal{})
+    
     return &CustomValidator{
         validator: v,
     }
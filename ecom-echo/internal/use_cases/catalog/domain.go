@@ -0,0 +1,117 @@
+// domain.go
+package catalog
+
+import "ecom/internal/shared/money"
+
+// Columnas del archivo de catálogo: una fila por variante. Las columnas de producto se repiten
+// en cada variante; las de precio se llaman "price:<nombre de la lista>"
+const (
+	ColumnProductSlug = "product_slug"
+	ColumnProductName = "product_name"
+	ColumnDescription = "description"
+	ColumnStatus      = "status"
+	ColumnCategory    = "category_slug"
+	ColumnTags        = "tags"       // códigos separados por |
+	ColumnSKU         = "sku"
+	ColumnVariantName = "variant_name"
+	ColumnStock       = "stock"        // total de la variante; ajusta el stock como stock.SetTotal. En packs se calcula y no se importa
+	ColumnTaxCategory = "tax_category" // nombre; vacío = categoría por defecto
+	ColumnAttributes  = "attributes"   // "Atributo:Valor" separados por |
+
+	PriceColumnPrefix = "price:"
+	ListSeparator     = "|"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// ProductRef es un producto existente localizado por su slug. Los eliminados siguen
+// ocupando el slug
+type ProductRef struct {
+	ID      int64
+	Deleted bool
+}
+
+// VariantRef es una variante existente localizada por su SKU
+type VariantRef struct {
+	ID        int64
+	IDProduct int64
+	Deleted   bool
+	Bundle    bool // Su stock se calcula a partir de los componentes
+}
+
+// Lookups son los datos de referencia que se resuelven por nombre, código o slug en la importación.
+// Las claves de nombres están en minúsculas
+type Lookups struct {
+	Products        map[string]ProductRef       // slug -> producto
+	TakenSlugs      map[string]bool             // slugs de categorías o antiguos, que no puede usar un producto nuevo
+	Variants        map[string]VariantRef       // sku -> variante
+	Categories      map[string]int64            // slug -> id
+	Tags            map[string]int64            // código -> id
+	TaxCategories   map[string]int64            // nombre -> id
+	DefaultTaxID    int64
+	AttributeValues map[string]map[string]int64 // atributo -> valor -> id
+	PriceLists      map[string]int64            // nombre -> id
+	VariantPrices   map[int64]map[int64]ListPrices // variante -> lista -> precios activos
+}
+
+// ListPrices precios activos de una variante existente en una lista
+type ListPrices struct {
+	Current   *money.Decimal // Importe vigente, nil si no tiene
+	Scheduled bool           // Tiene precios que aún no han empezado
+}
+
+// ImportProduct producto del archivo con sus variantes, validado y listo para aplicarse.
+// Los campos vacíos de un producto o variante existente no se modifican
+type ImportProduct struct {
+	ID          int64 // 0 si es nuevo
+	Slug        string
+	Name        string
+	Description *string
+	Status      *bool
+	IDCategory  int64
+	TagIDs      []int64
+	ReplaceTags bool
+	Variants    []ImportVariant
+}
+
+type ImportVariant struct {
+	ID                int64 // 0 si es nueva
+	SKU               string
+	Name              string
+	Stock             *int
+	IDTaxCategory     int64
+	AttributeValueIDs []int64
+	ReplaceAttributes bool
+	Prices            []ImportPrice
+}
+
+// ImportPrice precio vigente a partir de ahora en una lista; sólo se crea si cambia el importe
+type ImportPrice struct {
+	IDPriceList int64
+	Amount      money.Decimal
+}
+
+// PriceListColumn lista de precios exportada como columna
+type PriceListColumn struct {
+	ID   int64
+	Name string
+}
+
+// ExportRow es una variante del catálogo con los datos de su producto
+type ExportRow struct {
+	ProductSlug string
+	ProductName string
+	Description string
+	Status      bool
+	Category    string
+	Tags        []string
+	SKU         string
+	VariantName string
+	Stock       int
+	TaxCategory string
+	Attributes  []string         // "Atributo:Valor"
+	Prices      []*money.Decimal // en el orden de las listas; nil = sin precio vigente
+}
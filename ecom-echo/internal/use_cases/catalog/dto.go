@@ -0,0 +1,41 @@
+// dto.go
+package catalog
+
+// ImportRow resultado de la validación de una fila del archivo
+type ImportRow struct {
+	Row         int      `json:"row"`
+	ProductSlug string   `json:"product_slug"`
+	SKU         string   `json:"sku"`
+	Action      string   `json:"action,omitempty"` // create | update
+	Errors      []string `json:"errors,omitempty"`
+}
+
+type ImportReport struct {
+	DryRun          bool        `json:"dry_run"`
+	TotalRows       int         `json:"total_rows"`
+	ValidRows       int         `json:"valid_rows"`
+	InvalidRows     int         `json:"invalid_rows"`
+	ProductsCreated int         `json:"products_created"`
+	ProductsUpdated int         `json:"products_updated"`
+	VariantsCreated int         `json:"variants_created"`
+	VariantsUpdated int         `json:"variants_updated"`
+	PricesChanged   int         `json:"prices_changed"` // Sólo al aplicar: los importes iguales al vigente no crean precio
+	Imported        bool        `json:"imported"`
+	Rows            []ImportRow `json:"rows"`
+}
+
+// ExportItem es una fila del catálogo en la exportación JSON
+type ExportItem struct {
+	ProductSlug  string            `json:"product_slug"`
+	ProductName  string            `json:"product_name"`
+	Description  string            `json:"description"`
+	Status       bool              `json:"status"`
+	CategorySlug string            `json:"category_slug"`
+	Tags         []string          `json:"tags"`
+	SKU          string            `json:"sku"`
+	VariantName  string            `json:"variant_name"`
+	Stock        int               `json:"stock"`
+	TaxCategory  string            `json:"tax_category"`
+	Attributes   map[string]string `json:"attributes"`
+	Prices       map[string]string `json:"prices"` // nombre de la lista -> importe vigente
+}
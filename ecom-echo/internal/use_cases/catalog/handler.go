@@ -0,0 +1,99 @@
+// handler.go
+package catalog
+
+import (
+	"ecom/internal/shared/errors"
+	"ecom/internal/shared/response"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(e *echo.Group, s *Service) {
+	h := &Handler{service: s}
+	e.POST("/import", h.Import)
+	e.GET("/export", h.Export)
+}
+
+func (h *Handler) Import(c echo.Context) error {
+	file, err := c.FormFile("file")
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error al procesar el archivo", err.Error()))
+	}
+
+	dryRun, _ := strconv.ParseBool(c.FormValue("dry_run"))
+
+	src, err := file.Open()
+	if err != nil {
+			return h.handleError(c, err)
+	}
+	defer src.Close()
+
+	report, err := h.service.Import(c.Request().Context(), file.Filename, src, dryRun)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	if report.InvalidRows > 0 && !dryRun {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("El archivo contiene filas inválidas, no se importó ningún producto", report))
+	}
+
+	message := "Catálogo importado exitosamente"
+	if dryRun {
+			message = "Validación del archivo completada"
+	}
+
+	return c.JSON(http.StatusOK, response.Success(message, report))
+}
+
+// Export envía el catálogo a medida que se genera; si falla antes de escribir se responde con JSON
+func (h *Handler) Export(c echo.Context) error {
+	format := c.QueryParam("format")
+	if format == "" {
+			format = FormatCSV
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if format == FormatJSON {
+			contentType = echo.MIMEApplicationJSONCharsetUTF8
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, contentType)
+	res.Header().Set(echo.HeaderContentDisposition,
+			fmt.Sprintf("attachment; filename=\"catalog-%s.%s\"", time.Now().Format("20060102"), format))
+
+	if err := h.service.Export(c.Request().Context(), format, res); err != nil {
+			if !res.Committed {
+					res.Header().Del(echo.HeaderContentDisposition)
+					return h.handleError(c, err)
+			}
+			// La respuesta ya se empezó a enviar: sólo queda cortarla
+			log.Printf("Error al exportar el catálogo: %v", err)
+	}
+	return nil
+}
+
+func (h *Handler) handleError(c echo.Context, err error) error {
+	switch e := err.(type) {
+	case *errors.AppError:
+			return c.JSON(e.Code, response.Error(e.Message, nil))
+	default:
+			if errors.IsNotFound(err) {
+					return c.JSON(http.StatusNotFound, 
+							response.Error("Recurso no encontrado", nil))
+			}
+			return c.JSON(http.StatusInternalServerError, 
+					response.Error("Error interno del servidor", nil))
+	}
+}
@@ -0,0 +1,548 @@
+// repository.go
+package catalog
+
+import (
+	"context"
+	"database/sql"
+	"ecom/internal/shared/errors"
+	"ecom/internal/shared/money"
+	"ecom/internal/shared/pricing"
+	"ecom/internal/shared/stock"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type Repository interface {
+	LoadLookups(ctx context.Context, slugs, skus []string) (*Lookups, error)
+	ApplyImport(ctx context.Context, products []ImportProduct) (int, error)
+	ListPriceLists(ctx context.Context) ([]PriceListColumn, error)
+	StreamCatalog(ctx context.Context, lists []PriceListColumn, fn func(*ExportRow) error) error
+}
+
+type MySQLRepository struct {
+	db *sql.DB
+}
+
+func NewMySQLRepository(db *sql.DB) Repository {
+	return &MySQLRepository{db: db}
+}
+
+// actorImport autor de los movimientos de stock de la importación
+const actorImport = "catalog-import"
+
+// LoadLookups resuelve los productos y variantes del archivo y carga las tablas de referencia
+func (r *MySQLRepository) LoadLookups(ctx context.Context, slugs, skus []string) (*Lookups, error) {
+	l := &Lookups{
+		Products:        make(map[string]ProductRef),
+		TakenSlugs:      make(map[string]bool),
+		Variants:        make(map[string]VariantRef),
+		Categories:      make(map[string]int64),
+		Tags:            make(map[string]int64),
+		TaxCategories:   make(map[string]int64),
+		AttributeValues: make(map[string]map[string]int64),
+		PriceLists:      make(map[string]int64),
+		VariantPrices:   make(map[int64]map[int64]ListPrices),
+	}
+
+	if len(slugs) > 0 {
+		query := fmt.Sprintf("SELECT slug, id, deleted_at IS NOT NULL FROM products WHERE slug IN (%s)", placeholders(len(slugs)))
+		err := r.queryRows(ctx, query, stringArgs(slugs), func(rows *sql.Rows) error {
+			var slug string
+			var ref ProductRef
+			if err := rows.Scan(&slug, &ref.ID, &ref.Deleted); err != nil {
+				return err
+			}
+			l.Products[slug] = ref
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// Productos y categorías comparten los slugs de /resolve/:slug
+		query = fmt.Sprintf(`
+			SELECT slug FROM categories WHERE slug IN (%[1]s)
+			UNION
+			SELECT slug FROM slug_history WHERE slug IN (%[1]s)
+		`, placeholders(len(slugs)))
+		args := stringArgs(slugs)
+		err = r.queryRows(ctx, query, append(args, args...), func(rows *sql.Rows) error {
+			var slug string
+			if err := rows.Scan(&slug); err != nil {
+				return err
+			}
+			l.TakenSlugs[slug] = true
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(skus) > 0 {
+		query := fmt.Sprintf(`
+			SELECT sku, id, id_product, deleted_at IS NOT NULL,
+				EXISTS(SELECT 1 FROM product_bundles b WHERE b.id_product_variant = product_variants.id)
+			FROM product_variants WHERE sku IN (%s)
+		`, placeholders(len(skus)))
+		err := r.queryRows(ctx, query, stringArgs(skus), func(rows *sql.Rows) error {
+			var sku string
+			var ref VariantRef
+			if err := rows.Scan(&sku, &ref.ID, &ref.IDProduct, &ref.Deleted, &ref.Bundle); err != nil {
+				return err
+			}
+			l.Variants[sku] = ref
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// Precios vigentes y programados de las variantes existentes, para validar las columnas de
+		// precio. Entre varios vigentes gana el más reciente, como en savePrice
+		query = fmt.Sprintf(`
+			SELECT v.id, p.id_price_list, p.amount, p.starts_at <= NOW()
+			FROM n_product_variant_prices pvp
+			JOIN prices p ON p.id = pvp.id_price
+			JOIN product_variants v ON v.id = pvp.id_product_variant
+			WHERE v.sku IN (%s) AND pvp.is_active = true AND p.deleted_at IS NULL
+			AND (p.ends_at IS NULL OR p.ends_at > NOW())
+			ORDER BY p.created_at
+		`, placeholders(len(skus)))
+		err = r.queryRows(ctx, query, stringArgs(skus), func(rows *sql.Rows) error {
+			var variantID, listID int64
+			var amount money.Decimal
+			var effective bool
+			if err := rows.Scan(&variantID, &listID, &amount, &effective); err != nil {
+				return err
+			}
+			if l.VariantPrices[variantID] == nil {
+				l.VariantPrices[variantID] = make(map[int64]ListPrices)
+			}
+			state := l.VariantPrices[variantID][listID]
+			if effective {
+				state.Current = &amount
+			} else {
+				state.Scheduled = true
+			}
+			l.VariantPrices[variantID][listID] = state
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	simple := []struct {
+		query  string
+		target map[string]int64
+	}{
+		{"SELECT slug, id FROM categories WHERE deleted_at IS NULL", l.Categories},
+		{"SELECT code, id FROM tags WHERE deleted_at IS NULL", l.Tags},
+		{"SELECT LOWER(name), id FROM tax_categories WHERE deleted_at IS NULL", l.TaxCategories},
+		{"SELECT LOWER(name), id FROM price_lists WHERE deleted_at IS NULL", l.PriceLists},
+	}
+	for _, q := range simple {
+		err := r.queryRows(ctx, q.query, nil, func(rows *sql.Rows) error {
+			var key string
+			var id int64
+			if err := rows.Scan(&key, &id); err != nil {
+				return err
+			}
+			q.target[key] = id
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := r.queryRows(ctx, `
+		SELECT LOWER(pa.name), LOWER(av.name), av.id
+		FROM attribute_values av
+		INNER JOIN product_attributes pa ON pa.id = av.id_attribute_product
+		WHERE pa.deleted_at IS NULL
+	`, nil, func(rows *sql.Rows) error {
+		var attribute, value string
+		var id int64
+		if err := rows.Scan(&attribute, &value, &id); err != nil {
+			return err
+		}
+		if l.AttributeValues[attribute] == nil {
+			l.AttributeValues[attribute] = make(map[string]int64)
+		}
+		l.AttributeValues[attribute][value] = id
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.QueryRowContext(ctx,
+		"SELECT id FROM tax_categories WHERE is_default = true AND deleted_at IS NULL LIMIT 1",
+	).Scan(&l.DefaultTaxID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, errors.NewMysqlError(err)
+	}
+
+	return l, nil
+}
+
+// ApplyImport crea o actualiza los productos, variantes, relaciones y precios en una única
+// transacción. Asigna los ids creados y devuelve cuántos precios se crearon
+func (r *MySQLRepository) ApplyImport(ctx context.Context, products []ImportProduct) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, errors.NewMysqlError(err)
+	}
+	defer tx.Rollback()
+
+	pricesChanged := 0
+	for i := range products {
+		p := &products[i]
+		if err := r.saveProduct(ctx, tx, p); err != nil {
+			return 0, err
+		}
+
+		for j := range p.Variants {
+			v := &p.Variants[j]
+			if err := r.saveVariant(ctx, tx, p, v); err != nil {
+				return 0, err
+			}
+
+			for _, price := range v.Prices {
+				changed, err := r.savePrice(ctx, tx, v.ID, price)
+				if err != nil {
+					return 0, err
+				}
+				if changed {
+					pricesChanged++
+				}
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errors.NewMysqlError(err)
+	}
+	return pricesChanged, nil
+}
+
+func (r *MySQLRepository) saveProduct(ctx context.Context, tx *sql.Tx, p *ImportProduct) error {
+	if p.ID == 0 {
+		description := ""
+		if p.Description != nil {
+			description = *p.Description
+		}
+		status := p.Status != nil && *p.Status
+
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO products (name, slug, description, status, publication_status, id_category, created_at, updated_at)
+			VALUES (?, ?, ?, ?, IF(?, 'published', 'draft'), ?, NOW(), NOW())
+		`, p.Name, p.Slug, description, status, status, p.IDCategory)
+		if err != nil {
+			return errors.NewMysqlError(err)
+		}
+		if p.ID, err = result.LastInsertId(); err != nil {
+			return errors.NewInternalError("Error al obtener el id creado", err)
+		}
+	} else {
+		// Si cambia status, el estado de publicación pasa a publicado o borrador y se
+		// descartan las fechas programadas. status se asigna el último porque MySQL evalúa
+		// las asignaciones en orden y las anteriores deben ver el valor previo
+		changed := "? IS NOT NULL AND ? <> COALESCE(status, false)"
+		_, err := tx.ExecContext(ctx, `
+			UPDATE products SET
+				name = COALESCE(NULLIF(?, ''), name),
+				description = COALESCE(?, description),
+				publish_at = IF(`+changed+`, NULL, publish_at),
+				unpublish_at = IF(`+changed+`, NULL, unpublish_at),
+				publication_status = IF(`+changed+`, IF(?, 'published', 'draft'), publication_status),
+				status = COALESCE(?, status),
+				id_category = COALESCE(NULLIF(?, 0), id_category),
+				updated_at = NOW()
+			WHERE id = ?
+		`, p.Name, p.Description,
+			p.Status, p.Status,
+			p.Status, p.Status,
+			p.Status, p.Status, p.Status,
+			p.Status, p.IDCategory, p.ID)
+		if err != nil {
+			return errors.NewMysqlError(err)
+		}
+	}
+
+	if !p.ReplaceTags {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM n_products_tags WHERE id_product = ?", p.ID); err != nil {
+		return errors.NewMysqlError(err)
+	}
+	for _, tagID := range p.TagIDs {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO n_products_tags (id_tag, id_product) VALUES (?, ?)", tagID, p.ID,
+		); err != nil {
+			return errors.NewMysqlError(err)
+		}
+	}
+	return nil
+}
+
+func (r *MySQLRepository) saveVariant(ctx context.Context, tx *sql.Tx, p *ImportProduct, v *ImportVariant) error {
+	idTaxCategory := sql.NullInt64{Int64: v.IDTaxCategory, Valid: v.IDTaxCategory != 0}
+
+	if v.ID == 0 {
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO product_variants (name, sku, stock, id_product, id_tax_category, created_at, updated_at)
+			VALUES (COALESCE(NULLIF(?, ''), (SELECT name FROM products WHERE id = ?)), ?, 0, ?, ?, NOW(), NOW())
+		`, v.Name, p.ID, v.SKU, p.ID, idTaxCategory)
+		if err != nil {
+			return errors.NewMysqlError(err)
+		}
+		if v.ID, err = result.LastInsertId(); err != nil {
+			return errors.NewInternalError("Error al obtener el id creado", err)
+		}
+	} else {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE product_variants SET
+				name = COALESCE(NULLIF(?, ''), name),
+				id_tax_category = COALESCE(?, id_tax_category),
+				updated_at = NOW()
+			WHERE id = ?
+		`, v.Name, idTaxCategory, v.ID)
+		if err != nil {
+			return errors.NewMysqlError(err)
+		}
+	}
+
+	// La columna stock es el total de la variante; la diferencia entra en el libro como ajuste
+	if v.Stock != nil {
+		if err := stock.SetTotal(ctx, tx, v.ID, *v.Stock, "Importación de catálogo", actorImport); err != nil {
+			return err
+		}
+	}
+
+	if !v.ReplaceAttributes {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx,
+		"DELETE FROM n_product_variant_attribute_values WHERE id_product_variant = ?", v.ID,
+	); err != nil {
+		return errors.NewMysqlError(err)
+	}
+	for _, valueID := range v.AttributeValueIDs {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO n_product_variant_attribute_values (id_product_variant, id_attribute_value) VALUES (?, ?)",
+			v.ID, valueID,
+		); err != nil {
+			return errors.NewMysqlError(err)
+		}
+	}
+	return nil
+}
+
+// savePrice crea un precio vigente desde ahora si el importe difiere del vigente en la lista.
+// El vigente termina en ese momento; los programados no se tocan y, como la validación ya los
+// rechaza, encontrar uno aquí significa que se programó durante la importación
+func (r *MySQLRepository) savePrice(ctx context.Context, tx *sql.Tx, variantID int64, price ImportPrice) (bool, error) {
+	periods, err := pricing.LockVariantPrices(ctx, tx, variantID, price.IDPriceList)
+	if err != nil {
+		return false, err
+	}
+
+	var current money.NullDecimal
+	err = tx.QueryRowContext(ctx, `
+		SELECT p.amount
+		FROM prices p
+		JOIN n_product_variant_prices pvp ON pvp.id_price = p.id
+		WHERE pvp.id_product_variant = ? AND p.id_price_list = ?
+		AND pvp.is_active = true AND p.deleted_at IS NULL
+		AND p.starts_at <= NOW() AND (p.ends_at IS NULL OR p.ends_at > NOW())
+		ORDER BY p.created_at DESC
+		LIMIT 1
+	`, variantID, price.IDPriceList).Scan(&current)
+	if err != nil && err != sql.ErrNoRows {
+		return false, errors.NewMysqlError(err)
+	}
+	if current.Valid && current.Decimal.Equal(price.Amount) {
+		return false, nil
+	}
+
+	now := time.Now()
+	for _, p := range periods {
+		switch {
+		case p.EndsAt != nil && !p.EndsAt.After(now):
+			continue
+		case p.StartsAt.After(now):
+			return false, errors.NewConflictError(fmt.Sprintf(
+				"La variante %d tiene el precio %d programado en la lista %d", variantID, p.IDPrice, price.IDPriceList))
+		}
+		if err := pricing.ClosePrice(ctx, tx, variantID, p.IDPrice, now); err != nil {
+			return false, err
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO prices (id_price_list, amount, starts_at, ends_at, created_at, updated_at)
+		VALUES (?, ?, ?, NULL, NOW(), NOW())
+	`, price.IDPriceList, price.Amount, now)
+	if err != nil {
+		return false, errors.NewMysqlError(err)
+	}
+	priceID, err := result.LastInsertId()
+	if err != nil {
+		return false, errors.NewInternalError("Error al obtener el id creado", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO n_product_variant_prices
+		(id_product_variant, id_price, is_active, created_at, updated_at)
+		VALUES (?, ?, true, NOW(), NOW())
+	`, variantID, priceID); err != nil {
+		return false, errors.NewMysqlError(err)
+	}
+	return true, nil
+}
+
+// ListPriceLists devuelve las listas de precios activas, la lista por defecto primero
+func (r *MySQLRepository) ListPriceLists(ctx context.Context) ([]PriceListColumn, error) {
+	var lists []PriceListColumn
+	err := r.queryRows(ctx, `
+		SELECT id, name FROM price_lists
+		WHERE deleted_at IS NULL AND status = true
+		ORDER BY is_default DESC, priority, id
+	`, nil, func(rows *sql.Rows) error {
+		var l PriceListColumn
+		if err := rows.Scan(&l.ID, &l.Name); err != nil {
+			return err
+		}
+		lists = append(lists, l)
+		return nil
+	})
+	return lists, err
+}
+
+// StreamCatalog recorre las variantes no eliminadas del catálogo ordenadas por producto
+// sin cargarlas todas en memoria
+func (r *MySQLRepository) StreamCatalog(ctx context.Context, lists []PriceListColumn, fn func(*ExportRow) error) error {
+	priceColumns := ""
+	args := make([]interface{}, 0, len(lists))
+	for _, l := range lists {
+		priceColumns += `,
+			(SELECT pr.amount
+			 FROM prices pr
+			 JOIN n_product_variant_prices pvp ON pvp.id_price = pr.id
+			 WHERE pvp.id_product_variant = v.id AND pr.id_price_list = ?
+			 AND pvp.is_active = true AND pr.deleted_at IS NULL
+			 AND pr.starts_at <= NOW() AND (pr.ends_at IS NULL OR pr.ends_at > NOW())
+			 ORDER BY pr.created_at DESC LIMIT 1)`
+		args = append(args, l.ID)
+	}
+
+	query := `
+		SELECT p.slug, p.name, COALESCE(p.description, ''), COALESCE(p.status, false),
+			COALESCE(c.slug, ''),
+			(SELECT GROUP_CONCAT(t.code ORDER BY t.code SEPARATOR '|')
+			 FROM n_products_tags pt JOIN tags t ON t.id = pt.id_tag
+			 WHERE pt.id_product = p.id AND t.deleted_at IS NULL),
+			v.sku, v.name,
+			CASE WHEN EXISTS(SELECT 1 FROM product_bundles b WHERE b.id_product_variant = v.id)
+				THEN COALESCE((
+					SELECT MIN(CASE WHEN cv.deleted_at IS NULL THEN FLOOR(COALESCE(cv.stock, 0) / bc.quantity) ELSE 0 END)
+					FROM product_bundle_components bc
+					JOIN product_variants cv ON cv.id = bc.id_component_variant
+					WHERE bc.id_bundle_variant = v.id
+				), 0)
+				ELSE COALESCE(v.stock, 0) END,
+			COALESCE(tc.name, ''),
+			(SELECT GROUP_CONCAT(CONCAT(pa.name, ':', av.name) ORDER BY pa.name SEPARATOR '|')
+			 FROM n_product_variant_attribute_values pvav
+			 JOIN attribute_values av ON av.id = pvav.id_attribute_value
+			 JOIN product_attributes pa ON pa.id = av.id_attribute_product
+			 WHERE pvav.id_product_variant = v.id)` + priceColumns + `
+		FROM products p
+		JOIN product_variants v ON v.id_product = p.id AND v.deleted_at IS NULL
+		LEFT JOIN categories c ON c.id = p.id_category
+		LEFT JOIN tax_categories tc ON tc.id = v.id_tax_category
+		WHERE p.deleted_at IS NULL
+		ORDER BY p.id, v.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row ExportRow
+		var tags, attributes sql.NullString
+		prices := make([]money.NullDecimal, len(lists))
+
+		dest := []interface{}{
+			&row.ProductSlug, &row.ProductName, &row.Description, &row.Status,
+			&row.Category, &tags,
+			&row.SKU, &row.VariantName, &row.Stock, &row.TaxCategory, &attributes,
+		}
+		for i := range prices {
+			dest = append(dest, &prices[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return errors.NewMysqlError(err)
+		}
+
+		row.Tags = splitList(tags.String)
+		row.Attributes = splitList(attributes.String)
+		row.Prices = make([]*money.Decimal, len(prices))
+		for i, p := range prices {
+			if p.Valid {
+				amount := p.Decimal
+				row.Prices[i] = &amount
+			}
+		}
+
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (r *MySQLRepository) queryRows(ctx context.Context, query string, args []interface{}, scan func(*sql.Rows) error) error {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return errors.NewMysqlError(err)
+		}
+	}
+	return rows.Err()
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+func stringArgs(values []string) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
+
+// splitList separa un valor de lista "a|b|c" ignorando los elementos vacíos
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ListSeparator) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
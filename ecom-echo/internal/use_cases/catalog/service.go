@@ -0,0 +1,610 @@
+// service.go
+package catalog
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"ecom/internal/shared/errors"
+	"ecom/internal/shared/money"
+	"ecom/internal/shared/spreadsheet"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	product "ecom/internal/use_cases/product"
+)
+
+// maxImportRows limita el tamaño de un archivo de importación
+const maxImportRows = 10000
+
+var slugPattern = regexp.MustCompile(`^[a-zA-Z0-9-]+$`)
+
+type Service struct {
+	repo     Repository
+	listener product.ChangeListener
+}
+
+func NewService(repo Repository, listener product.ChangeListener) *Service {
+	return &Service{repo: repo, listener: listener}
+}
+
+// importState agrupa las filas de un mismo producto mientras se valida el archivo
+type importState struct {
+	product  *ImportProduct
+	firstRow int
+	lines    []int // posiciones en report.Rows
+	tags     string
+}
+
+// Import valida un archivo CSV, XLSX o JSON con una fila por variante y, si no es dry-run
+// y todas las filas son válidas, crea o actualiza productos (por slug) y variantes (por SKU)
+// en una única transacción
+func (s *Service) Import(ctx context.Context, fileName string, content io.Reader, dryRun bool) (*ImportReport, error) {
+	table, firstRow, err := readImportTable(fileName, content)
+	if err != nil {
+		return nil, err
+	}
+	if len(table.Rows) > maxImportRows {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("El archivo supera el máximo de %d filas", maxImportRows))
+	}
+	for _, column := range []string{ColumnProductSlug, ColumnSKU} {
+		if table.Column(column) < 0 {
+			return nil, errors.NewBadRequestError(fmt.Sprintf("Falta la columna obligatoria \"%s\"", column))
+		}
+	}
+
+	var slugs, skus []string
+	for _, row := range table.Rows {
+		slugs = append(slugs, table.Value(row, ColumnProductSlug))
+		skus = append(skus, table.Value(row, ColumnSKU))
+	}
+	lookups, err := s.repo.LoadLookups(ctx, slugs, skus)
+	if err != nil {
+		return nil, err
+	}
+
+	// Columnas de precio: "price:<lista>"
+	priceColumns := make(map[string]int64)
+	for _, h := range table.Header {
+		if !strings.HasPrefix(h, PriceColumnPrefix) {
+			continue
+		}
+		name := strings.TrimSpace(strings.TrimPrefix(h, PriceColumnPrefix))
+		id, ok := lookups.PriceLists[name]
+		if !ok {
+			return nil, errors.NewBadRequestError(fmt.Sprintf("No existe la lista de precios \"%s\"", name))
+		}
+		priceColumns[h] = id
+	}
+
+	report := &ImportReport{
+		DryRun:    dryRun,
+		TotalRows: len(table.Rows),
+		Rows:      make([]ImportRow, 0, len(table.Rows)),
+	}
+	states := make(map[string]*importState)
+	var order []string
+	seenSKUs := make(map[string]int)
+
+	for i, row := range table.Rows {
+		line := ImportRow{
+			Row:         firstRow + i,
+			ProductSlug: table.Value(row, ColumnProductSlug),
+			SKU:         table.Value(row, ColumnSKU),
+		}
+		addError := func(format string, args ...interface{}) {
+			line.Errors = append(line.Errors, fmt.Sprintf(format, args...))
+		}
+
+		// Producto
+		state := states[line.ProductSlug]
+		switch {
+		case line.ProductSlug == "":
+			addError("El slug del producto es obligatorio")
+		case !slugPattern.MatchString(line.ProductSlug):
+			addError("El slug sólo puede contener letras, números y guiones")
+		case state == nil:
+			state = &importState{product: &ImportProduct{Slug: line.ProductSlug}, firstRow: line.Row}
+			if ref, ok := lookups.Products[line.ProductSlug]; ok {
+				if ref.Deleted {
+					addError("El slug pertenece a un producto eliminado")
+				}
+				state.product.ID = ref.ID
+			} else if lookups.TakenSlugs[line.ProductSlug] {
+				addError("El slug ya lo usa una categoría o es el slug antiguo de otra entidad")
+			}
+			states[line.ProductSlug] = state
+			order = append(order, line.ProductSlug)
+		}
+
+		if state != nil {
+			p := state.product
+			conflict := func(column string) {
+				addError("%s distinto al de la fila %d", column, state.firstRow)
+			}
+
+			if name := table.Value(row, ColumnProductName); name != "" {
+				if len([]rune(name)) < 2 || len(name) > 200 {
+					addError("El nombre del producto debe tener entre 2 y 200 caracteres")
+				} else if p.Name == "" {
+					p.Name = name
+				} else if p.Name != name {
+					conflict(ColumnProductName)
+				}
+			}
+			if description := table.Value(row, ColumnDescription); description != "" {
+				if p.Description == nil {
+					p.Description = &description
+				} else if *p.Description != description {
+					conflict(ColumnDescription)
+				}
+			}
+			if value := table.Value(row, ColumnStatus); value != "" {
+				status, err := strconv.ParseBool(value)
+				if err != nil {
+					addError("status debe ser true o false")
+				} else if p.Status == nil {
+					p.Status = &status
+				} else if *p.Status != status {
+					conflict(ColumnStatus)
+				}
+			}
+			if slug := table.Value(row, ColumnCategory); slug != "" {
+				id, ok := lookups.Categories[slug]
+				if !ok {
+					addError("No existe una categoría con slug \"%s\"", slug)
+				} else if p.IDCategory == 0 {
+					p.IDCategory = id
+				} else if p.IDCategory != id {
+					conflict(ColumnCategory)
+				}
+			}
+			if value := table.Value(row, ColumnTags); value != "" {
+				codes := splitList(value)
+				sort.Strings(codes)
+				key := strings.Join(codes, ListSeparator)
+				if state.tags == "" {
+					state.tags = key
+					p.ReplaceTags = true
+					for _, code := range codes {
+						if id, ok := lookups.Tags[code]; ok {
+							p.TagIDs = append(p.TagIDs, id)
+						} else {
+							addError("No existe un tag con código \"%s\"", code)
+						}
+					}
+				} else if state.tags != key {
+					conflict(ColumnTags)
+				}
+			}
+		}
+
+		// Variante
+		variant := ImportVariant{SKU: line.SKU, Name: table.Value(row, ColumnVariantName)}
+		bundle := false
+		switch {
+		case line.SKU == "":
+			addError("El SKU es obligatorio")
+		case len(line.SKU) > 100:
+			addError("El SKU no puede superar los 100 caracteres")
+		default:
+			if first, ok := seenSKUs[line.SKU]; ok {
+				addError("SKU duplicado en el archivo (fila %d)", first)
+			}
+			seenSKUs[line.SKU] = line.Row
+
+			if ref, ok := lookups.Variants[line.SKU]; ok {
+				switch {
+				case ref.Deleted:
+					addError("El SKU pertenece a una variante eliminada")
+				case state != nil && state.product.ID != ref.IDProduct:
+					addError("El SKU pertenece a otro producto")
+				}
+				variant.ID = ref.ID
+				bundle = ref.Bundle
+			}
+		}
+		if len(variant.Name) > 200 {
+			addError("El nombre de la variante no puede superar los 200 caracteres")
+		}
+
+		// El stock de un pack se exporta calculado a partir de sus componentes y no se importa
+		if value := table.Value(row, ColumnStock); value != "" && !bundle {
+			stock, err := strconv.Atoi(value)
+			if err != nil || stock < 0 {
+				addError("stock debe ser un entero mayor o igual a 0")
+			} else {
+				variant.Stock = &stock
+			}
+		}
+
+		if name := table.Value(row, ColumnTaxCategory); name != "" {
+			if id, ok := lookups.TaxCategories[strings.ToLower(name)]; ok {
+				variant.IDTaxCategory = id
+			} else {
+				addError("No existe la categoría de impuestos \"%s\"", name)
+			}
+		} else if variant.ID == 0 {
+			variant.IDTaxCategory = lookups.DefaultTaxID
+		}
+
+		if value := table.Value(row, ColumnAttributes); value != "" {
+			variant.ReplaceAttributes = true
+			seenAttributes := make(map[string]bool)
+			for _, pair := range splitList(value) {
+				attribute, val, ok := strings.Cut(pair, ":")
+				attribute = strings.ToLower(strings.TrimSpace(attribute))
+				val = strings.ToLower(strings.TrimSpace(val))
+				if !ok || attribute == "" || val == "" {
+					addError("Atributo \"%s\" inválido, use Atributo:Valor", pair)
+					continue
+				}
+				if seenAttributes[attribute] {
+					addError("El atributo \"%s\" está repetido", attribute)
+					continue
+				}
+				seenAttributes[attribute] = true
+
+				id, ok := lookups.AttributeValues[attribute][val]
+				if !ok {
+					addError("No existe el valor \"%s\"", pair)
+					continue
+				}
+				variant.AttributeValueIDs = append(variant.AttributeValueIDs, id)
+			}
+		}
+
+		for column, listID := range priceColumns {
+			value := table.Value(row, column)
+			if value == "" {
+				continue
+			}
+			amount, err := parseAmount(value)
+			if err != nil {
+				addError("%s: %s", column, err.Error())
+				continue
+			}
+			// Un precio nuevo desde ahora se solaparía con los programados de la lista
+			if prices := lookups.VariantPrices[variant.ID][listID]; variant.ID != 0 && prices.Scheduled &&
+				(prices.Current == nil || !prices.Current.Equal(amount)) {
+				addError("%s: la variante tiene precios programados en la lista; cámbielos con la importación de precios", column)
+				continue
+			}
+			variant.Prices = append(variant.Prices, ImportPrice{IDPriceList: listID, Amount: amount})
+		}
+		sort.Slice(variant.Prices, func(i, j int) bool { return variant.Prices[i].IDPriceList < variant.Prices[j].IDPriceList })
+
+		line.Action = "update"
+		if variant.ID == 0 {
+			line.Action = "create"
+		}
+		if state != nil {
+			state.product.Variants = append(state.product.Variants, variant)
+			state.lines = append(state.lines, len(report.Rows))
+		}
+		report.Rows = append(report.Rows, line)
+	}
+
+	// Los productos nuevos necesitan nombre y categoría en alguna de sus filas
+	products := make([]ImportProduct, 0, len(order))
+	for _, slug := range order {
+		state := states[slug]
+		p := state.product
+		if p.ID == 0 {
+			first := &report.Rows[state.lines[0]]
+			if p.Name == "" {
+				first.Errors = append(first.Errors, "El producto es nuevo y necesita product_name")
+			}
+			if p.IDCategory == 0 {
+				first.Errors = append(first.Errors, "El producto es nuevo y necesita category_slug")
+			}
+			report.ProductsCreated++
+		} else {
+			report.ProductsUpdated++
+		}
+		for _, v := range p.Variants {
+			if v.ID == 0 {
+				report.VariantsCreated++
+			} else {
+				report.VariantsUpdated++
+			}
+		}
+		products = append(products, *p)
+	}
+
+	for _, line := range report.Rows {
+		if len(line.Errors) == 0 {
+			report.ValidRows++
+		} else {
+			report.InvalidRows++
+		}
+	}
+
+	// La importación es todo o nada: con errores o en dry-run no se aplica nada
+	if dryRun || report.InvalidRows > 0 || len(products) == 0 {
+		return report, nil
+	}
+
+	pricesChanged, err := s.repo.ApplyImport(ctx, products)
+	if err != nil {
+		return nil, err
+	}
+	report.PricesChanged = pricesChanged
+	report.Imported = true
+
+	if s.listener != nil {
+		for _, p := range products {
+			s.listener.ProductChanged(ctx, p.ID)
+		}
+	}
+
+	return report, nil
+}
+
+// Export escribe el catálogo completo en CSV o JSON a medida que se lee de la base de datos
+func (s *Service) Export(ctx context.Context, format string, w io.Writer) error {
+	if format != FormatCSV && format != FormatJSON {
+		return errors.NewBadRequestError("Formato no soportado, use csv o json")
+	}
+
+	lists, err := s.repo.ListPriceLists(ctx)
+	if err != nil {
+		return err
+	}
+
+	if format == FormatJSON {
+		return s.exportJSON(ctx, lists, w)
+	}
+	return s.exportCSV(ctx, lists, w)
+}
+
+func (s *Service) exportCSV(ctx context.Context, lists []PriceListColumn, w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{
+		ColumnProductSlug, ColumnProductName, ColumnDescription, ColumnStatus, ColumnCategory, ColumnTags,
+		ColumnSKU, ColumnVariantName, ColumnStock, ColumnTaxCategory, ColumnAttributes,
+	}
+	for _, l := range lists {
+		header = append(header, PriceColumnPrefix+l.Name)
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	count := 0
+	err := s.repo.StreamCatalog(ctx, lists, func(row *ExportRow) error {
+		record := []string{
+			row.ProductSlug, row.ProductName, row.Description, strconv.FormatBool(row.Status), row.Category,
+			strings.Join(row.Tags, ListSeparator),
+			row.SKU, row.VariantName, strconv.Itoa(row.Stock), row.TaxCategory,
+			strings.Join(row.Attributes, ListSeparator),
+		}
+		for _, price := range row.Prices {
+			if price != nil {
+				record = append(record, price.StringFixed(money.AmountScale))
+			} else {
+				record = append(record, "")
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+
+		if count++; count%100 == 0 {
+			return flush(writer, w)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return flush(writer, w)
+}
+
+func (s *Service) exportJSON(ctx context.Context, lists []PriceListColumn, w io.Writer) error {
+	buf := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buf)
+
+	if _, err := buf.WriteString("["); err != nil {
+		return err
+	}
+	count := 0
+	err := s.repo.StreamCatalog(ctx, lists, func(row *ExportRow) error {
+		item := ExportItem{
+			ProductSlug:  row.ProductSlug,
+			ProductName:  row.ProductName,
+			Description:  row.Description,
+			Status:       row.Status,
+			CategorySlug: row.Category,
+			Tags:         append([]string{}, row.Tags...),
+			SKU:          row.SKU,
+			VariantName:  row.VariantName,
+			Stock:        row.Stock,
+			TaxCategory:  row.TaxCategory,
+			Attributes:   make(map[string]string, len(row.Attributes)),
+			Prices:       make(map[string]string, len(lists)),
+		}
+		for _, pair := range row.Attributes {
+			if attribute, value, ok := strings.Cut(pair, ":"); ok {
+				item.Attributes[attribute] = value
+			}
+		}
+		for i, price := range row.Prices {
+			if price != nil {
+				item.Prices[lists[i].Name] = price.StringFixed(money.AmountScale)
+			}
+		}
+
+		if count > 0 {
+			if _, err := buf.WriteString(","); err != nil {
+				return err
+			}
+		}
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+
+		if count++; count%100 == 0 {
+			if err := buf.Flush(); err != nil {
+				return err
+			}
+			flushResponse(w)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := buf.WriteString("]\n"); err != nil {
+		return err
+	}
+	if err := buf.Flush(); err != nil {
+		return err
+	}
+	flushResponse(w)
+	return nil
+}
+
+// flush envía al cliente lo escrito hasta ahora
+func flush(writer *csv.Writer, w io.Writer) error {
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	flushResponse(w)
+	return nil
+}
+
+func flushResponse(w io.Writer) {
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// readImportTable lee el archivo como tabla y devuelve el número de la primera fila de datos
+func readImportTable(fileName string, content io.Reader) (*spreadsheet.Table, int, error) {
+	if strings.ToLower(filepath.Ext(fileName)) == ".json" {
+		table, err := readJSONTable(content)
+		if err != nil {
+			return nil, 0, errors.NewBadRequestError(err.Error())
+		}
+		// En JSON las filas se numeran desde 1
+		return table, 1, nil
+	}
+
+	format, err := spreadsheet.FormatFromFileName(fileName)
+	if err != nil {
+		return nil, 0, errors.NewBadRequestError(err.Error())
+	}
+	table, err := spreadsheet.Read(content, format)
+	if err != nil {
+		return nil, 0, errors.NewBadRequestError(err.Error())
+	}
+	// +2: la fila 1 es la cabecera y las filas se numeran desde 1
+	return table, 2, nil
+}
+
+// readJSONTable convierte un array de objetos con el formato de la exportación JSON en una tabla.
+// Las listas se unen con "|", "attributes" se convierte en "Atributo:Valor" y cada entrada de
+// "prices" en una columna "price:<lista>"
+func readJSONTable(content io.Reader) (*spreadsheet.Table, error) {
+	decoder := json.NewDecoder(content)
+	decoder.UseNumber()
+
+	var items []map[string]interface{}
+	if err := decoder.Decode(&items); err != nil {
+		return nil, fmt.Errorf("error leyendo JSON: se esperaba un array de objetos: %w", err)
+	}
+
+	flat := make([]map[string]string, len(items))
+	columns := make(map[string]bool)
+	for i, item := range items {
+		flat[i] = make(map[string]string, len(item))
+		for key, value := range item {
+			key = strings.ToLower(strings.TrimSpace(key))
+
+			switch {
+			case key == "prices":
+				prices, ok := value.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("fila %d: prices debe ser un objeto", i+1)
+				}
+				for list, amount := range prices {
+					column := PriceColumnPrefix + strings.ToLower(strings.TrimSpace(list))
+					flat[i][column] = jsonString(amount)
+					columns[column] = true
+				}
+			case key == ColumnAttributes:
+				if attributes, ok := value.(map[string]interface{}); ok {
+					pairs := make([]string, 0, len(attributes))
+					for name, val := range attributes {
+						pairs = append(pairs, name+":"+jsonString(val))
+					}
+					sort.Strings(pairs)
+					flat[i][key] = strings.Join(pairs, ListSeparator)
+				} else {
+					flat[i][key] = jsonString(value)
+				}
+				columns[key] = true
+			default:
+				flat[i][key] = jsonString(value)
+				columns[key] = true
+			}
+		}
+	}
+
+	table := &spreadsheet.Table{}
+	for column := range columns {
+		table.Header = append(table.Header, column)
+	}
+	sort.Strings(table.Header)
+
+	for _, values := range flat {
+		row := make([]string, len(table.Header))
+		for j, column := range table.Header {
+			row[j] = values[column]
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table, nil
+}
+
+func jsonString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = jsonString(item)
+		}
+		return strings.Join(items, ListSeparator)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func parseAmount(value string) (money.Decimal, error) {
+	if !strings.Contains(value, ".") {
+		value = strings.Replace(value, ",", ".", 1)
+	}
+	amount, err := money.NewFromString(value)
+	if err != nil {
+		return money.Zero, errors.NewValidationError("El importe no es un número válido")
+	}
+	if amount.IsNegative() {
+		return money.Zero, errors.NewValidationError("El importe no puede ser negativo")
+	}
+	return money.Round(amount, money.AmountScale), nil
+}
@@ -5,7 +5,7 @@ import "time"
 // CreateTagRequest estructura para crear un nuevo tag
 type CreateCategoryRequest struct {
 	Name        string `json:"name" validate:"required,min=2,max=200,name"`
-	Slug     	  string `json:"slug" validate:"required,min=2,max=200,slug"`
+	Slug     	  string `json:"slug" validate:"omitempty,min=2,max=200,slug"` // Por defecto se genera a partir del nombre
 	ParentID    *int64  `json:"parent_id" validate:"omitempty"`
 	Description string `json:"description" validate:"omitempty"`
 	IDMedia     *int64  `json:"id_media"`
@@ -39,4 +39,38 @@ type Pagination struct {
     PerPage  int    `query:"per_page" validate:"min=1,max=100"`
     Search   string `query:"search"`
     ParentID *int64 `query:"parent_id"`
-}
\ No newline at end of file
+}
+
+// TreeRequest opciones para obtener el árbol de categorías
+type TreeRequest struct {
+    RootID        *int64 `query:"root_id"`        // Devolver sólo el subárbol de esta categoría
+    MaxDepth      int    `query:"max_depth" validate:"min=0"` // 0 = sin límite
+    IncludeCounts bool   `query:"include_counts"` // Incluir número de productos por nodo
+}
+
+// CategoryTreeNode es una categoría con sus subcategorías anidadas
+type CategoryTreeNode struct {
+    ID           int64               `json:"id"`
+    Name         string              `json:"name"`
+    Slug         string              `json:"slug"`
+    Description  string              `json:"description"`
+    ParentID     *int64              `json:"parent_id"`
+    IDMedia      *int64              `json:"id_media"`
+    Depth        int                 `json:"depth"`
+    ProductCount *int64              `json:"product_count,omitempty"`       // Productos de la propia categoría
+    TotalCount   *int64              `json:"total_product_count,omitempty"` // Incluyendo descendientes
+    Children     []*CategoryTreeNode `json:"children"`
+}
+
+// BreadcrumbItem es un paso en la ruta desde la raíz hasta una categoría
+type BreadcrumbItem struct {
+    ID       int64  `json:"id"`
+    Name     string `json:"name"`
+    Slug     string `json:"slug"`
+    ParentID *int64 `json:"parent_id"`
+}
+
+// MoveCategoryRequest mueve una categoría (con todo su subárbol); parent_id nulo la deja en la raíz
+type MoveCategoryRequest struct {
+    ParentID *int64 `json:"parent_id"`
+}
@@ -16,7 +16,10 @@ type Handler struct {
 func NewHandler(e *echo.Group, s *Service) {
 	h := &Handler{service: s}
 	e.POST("", h.Create)
+	e.GET("/tree", h.GetTree)
 	e.GET("/:id", h.GetByID)
+	e.GET("/:id/breadcrumbs", h.GetBreadcrumbs)
+	e.PUT("/:id/move", h.Move)
 	e.PUT("/:id", h.Update)
 	e.DELETE("/:id", h.Delete)
 	e.GET("", h.List)
@@ -123,6 +126,65 @@ func (h *Handler) List(c echo.Context) error {
 					}))
 }
 
+func (h *Handler) GetTree(c echo.Context) error {
+	var req TreeRequest
+	if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error al procesar la solicitud (posible error de sintaxis)", err.Error()))
+	}
+
+	if err := c.Validate(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error de validación", err.Error()))
+	}
+
+	tree, err := h.service.GetTree(c.Request().Context(), &req)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Árbol de categorías obtenido exitosamente", tree))
+}
+
+func (h *Handler) GetBreadcrumbs(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID inválido", err.Error()))
+	}
+
+	breadcrumbs, err := h.service.GetBreadcrumbs(c.Request().Context(), id)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Ruta de la categoría obtenida exitosamente", breadcrumbs))
+}
+
+func (h *Handler) Move(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID inválido", err.Error()))
+	}
+
+	var req MoveCategoryRequest
+	if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error al procesar la solicitud (posible error de sintaxis)", err.Error()))
+	}
+
+	category, err := h.service.Move(c.Request().Context(), id, &req)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Categoría movida exitosamente", category))
+}
+
 func (h *Handler) handleError(c echo.Context, err error) error {
 	switch e := err.(type) {
 	case *errors.AppError:
@@ -4,7 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"ecom/internal/shared/errors"
+	"ecom/internal/shared/slug"
 	"fmt"
+	"strings"
 )
 
 type Repository interface {
@@ -14,6 +16,11 @@ type Repository interface {
 	Delete(ctx context.Context, id int64) error
 	List(ctx context.Context, p *Pagination) ([]Category, int64, error)
 	HasChildren(ctx context.Context, id int64) (bool, error)
+	ListAll(ctx context.Context) ([]Category, error)
+	GetAncestors(ctx context.Context, id int64) ([]Category, error)
+	CountProductsByCategory(ctx context.Context) (map[int64]int64, error)
+	UpdateParent(ctx context.Context, id int64, parentID *int64) error
+	ListSlugsWithPrefix(ctx context.Context, prefix string, excludeID int64) ([]string, error)
 }
 
 type MySQLRepository struct {
@@ -46,12 +53,72 @@ const (
 			WHERE id = ? AND deleted_at IS NULL
 	`
 
+	querySelectCategorySlugForUpdate = `
+			SELECT slug FROM categories WHERE id = ? AND deleted_at IS NULL FOR UPDATE
+	`
+
+	// Slugs actuales (incluidos los eliminados) y antiguos, salvo los de una categoría
+	queryListCategorySlugsWithPrefix = `
+			SELECT slug FROM (
+					SELECT slug, id AS entity_id FROM categories
+					UNION ALL
+					SELECT slug, entity_id FROM slug_history WHERE entity_type = 'category'
+			) s
+			WHERE slug LIKE ? AND entity_id <> ?
+	`
+
 	queryDeleteCategory = `
 			UPDATE categories
 			SET deleted_at = NOW()
 			WHERE id = ? AND deleted_at IS NULL
 	`
 
+	queryListAllCategories = `
+			SELECT id, name, slug, description, parent_id, id_media, created_at, updated_at
+			FROM categories
+			WHERE deleted_at IS NULL
+			ORDER BY name ASC
+	`
+
+	// Recorre la cadena de padres hasta la raíz. El límite de profundidad evita
+	// bucles infinitos si los datos ya contienen un ciclo
+	queryGetAncestors = `
+			WITH RECURSIVE ancestors AS (
+					SELECT id, name, slug, description, parent_id, id_media, created_at, updated_at, 0 AS depth
+					FROM categories
+					WHERE id = ? AND deleted_at IS NULL
+					UNION ALL
+					SELECT c.id, c.name, c.slug, c.description, c.parent_id, c.id_media, c.created_at, c.updated_at, a.depth + 1
+					FROM categories c
+					JOIN ancestors a ON c.id = a.parent_id
+					WHERE c.deleted_at IS NULL AND a.depth < 100
+			)
+			SELECT id, name, slug, description, parent_id, id_media, created_at, updated_at
+			FROM ancestors
+			ORDER BY depth DESC
+	`
+
+	queryCountProductsByCategory = `
+			SELECT id_category, COUNT(*)
+			FROM products
+			WHERE id_category IS NOT NULL AND deleted_at IS NULL
+			GROUP BY id_category
+	`
+
+	queryUpdateCategoryParent = `
+			UPDATE categories
+			SET parent_id = ?, updated_at = NOW()
+			WHERE id = ? AND deleted_at IS NULL
+	`
+
+	queryLockCategory = `
+			SELECT id FROM categories WHERE id = ? AND deleted_at IS NULL FOR UPDATE
+	`
+
+	queryLockCategoryParent = `
+			SELECT parent_id FROM categories WHERE id = ? AND deleted_at IS NULL FOR UPDATE
+	`
+
 	queryHasChildren = `
 			SELECT EXISTS(
 					SELECT 1 FROM categories 
@@ -123,23 +190,63 @@ func (r *MySQLRepository) GetByID(ctx context.Context, id int64) (*Category, err
 	return c, nil
 }
 
+// Update guarda la categoría; si cambia el slug, el anterior pasa al historial para redirigirlo
 func (r *MySQLRepository) Update(ctx context.Context, c *Category) error {
-	result, err := r.db.ExecContext(ctx, queryUpdateCategory,
-			c.Name, c.Slug, c.Description, c.ParentID, c.IDMedia, c.ID)
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return errors.NewMysqlError(err)	
+		return errors.NewMysqlError(err)
 	}
+	defer tx.Rollback()
 
-	rows, err := result.RowsAffected()
+	var oldSlug string
+	err = tx.QueryRowContext(ctx, querySelectCategorySlugForUpdate, c.ID).Scan(&oldSlug)
+	if err == sql.ErrNoRows {
+		return errors.NewNotFoundError("Categoría no encontrada")
+	}
 	if err != nil {
-		return errors.NewInternalError("Error al obtener la categoría", err)
+		return errors.NewMysqlError(err)
 	}
-	if rows == 0 {
-		return errors.NewNotFoundError("Categoría no encontrada")
+
+	if err := checkParentTx(ctx, tx, c.ID, c.ParentID); err != nil {
+		return err
+	}
+
+	if err := slug.SaveHistory(ctx, tx, slug.EntityCategory, c.ID, oldSlug, c.Slug); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, queryUpdateCategory,
+			c.Name, c.Slug, c.Description, c.ParentID, c.IDMedia, c.ID); err != nil {
+		return errors.NewMysqlError(err)	
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.NewInternalError("Error al actualizar la categoría", err)
 	}
 	return nil
 }
 
+// ListSlugsWithPrefix devuelve los slugs que empiezan por el prefijo, incluidos los de categorías
+// eliminadas y los antiguos del historial, salvo los de la categoría excludeID
+func (r *MySQLRepository) ListSlugsWithPrefix(ctx context.Context, prefix string, excludeID int64) ([]string, error) {
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(prefix)
+	rows, err := r.db.QueryContext(ctx, queryListCategorySlugsWithPrefix, escaped+"%", excludeID)
+	if err != nil {
+		return nil, errors.NewMysqlError(err)
+	}
+	defer rows.Close()
+
+	var slugs []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, errors.NewMysqlError(err)
+		}
+		slugs = append(slugs, value)
+	}
+	return slugs, rows.Err()
+}
+
 func (r *MySQLRepository) Delete(ctx context.Context, id int64) error {
 	result, err := r.db.ExecContext(ctx, queryDeleteCategory, id)
 	if err != nil {
@@ -266,4 +373,141 @@ func (r *MySQLRepository) List(ctx context.Context, p *Pagination) ([]Category,
 	}
 
 	return categories, total, nil
-}
\ No newline at end of file
+}
+
+func (r *MySQLRepository) ListAll(ctx context.Context) ([]Category, error) {
+	return r.queryCategories(ctx, queryListAllCategories)
+}
+
+// GetAncestors devuelve la categoría y sus ancestros ordenados desde la raíz
+func (r *MySQLRepository) GetAncestors(ctx context.Context, id int64) ([]Category, error) {
+	categories, err := r.queryCategories(ctx, queryGetAncestors, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(categories) == 0 {
+		return nil, errors.NewNotFoundError("Categoría no encontrada")
+	}
+	return categories, nil
+}
+
+func (r *MySQLRepository) CountProductsByCategory(ctx context.Context) (map[int64]int64, error) {
+	rows, err := r.db.QueryContext(ctx, queryCountProductsByCategory)
+	if err != nil {
+		return nil, errors.NewMysqlError(err)
+	}
+	defer rows.Close()
+
+	counts := make(map[int64]int64)
+	for rows.Next() {
+		var categoryID, count int64
+		if err := rows.Scan(&categoryID, &count); err != nil {
+			return nil, err
+		}
+		counts[categoryID] = count
+	}
+	return counts, rows.Err()
+}
+
+// UpdateParent mueve la categoría. La comprobación de ciclos y el cambio van en una transacción
+// con la categoría y la cadena de ancestros del nuevo padre bloqueadas, para que dos movimientos
+// cruzados (A bajo B y B bajo A) no puedan pasar ambos
+func (r *MySQLRepository) UpdateParent(ctx context.Context, id int64, parentID *int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	defer tx.Rollback()
+
+	var lockedID int64
+	err = tx.QueryRowContext(ctx, queryLockCategory, id).Scan(&lockedID)
+	if err == sql.ErrNoRows {
+		return errors.NewNotFoundError("Categoría no encontrada")
+	}
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+
+	if err := checkParentTx(ctx, tx, id, parentID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, queryUpdateCategoryParent, parentID, id); err != nil {
+		return errors.NewMysqlError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.NewInternalError("Error al mover la categoría", err)
+	}
+	return nil
+}
+
+// checkParentTx comprueba que parentID exista y que asignarlo a la categoría id no cree un ciclo,
+// bloqueando cada ancestro del nuevo padre mientras recorre la cadena hasta la raíz
+func checkParentTx(ctx context.Context, tx *sql.Tx, id int64, parentID *int64) error {
+	if parentID == nil || *parentID == 0 {
+		return nil
+	}
+	if *parentID == id {
+		return errors.NewValidationError("La categoría no puede tener un parent_id igual al id")
+	}
+
+	current := *parentID
+	for depth := 0; depth < 100; depth++ {
+		if current == id {
+			return errors.NewConflictError("No se puede mover una categoría dentro de una de sus subcategorías")
+		}
+
+		var next sql.NullInt64
+		err := tx.QueryRowContext(ctx, queryLockCategoryParent, current).Scan(&next)
+		if err == sql.ErrNoRows {
+			if current == *parentID {
+				return errors.NewBadRequestError("Categoría padre no encontrada")
+			}
+			// Un ancestro eliminado corta la cadena: lo que cuelga de él se muestra como raíz
+			return nil
+		}
+		if err != nil {
+			return errors.NewMysqlError(err)
+		}
+		if !next.Valid {
+			return nil
+		}
+		current = next.Int64
+	}
+	return errors.NewConflictError("La jerarquía de categorías es demasiado profunda")
+}
+
+// queryCategories ejecuta una consulta de categorías sin media
+func (r *MySQLRepository) queryCategories(ctx context.Context, query string, args ...interface{}) ([]Category, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.NewMysqlError(err)
+	}
+	defer rows.Close()
+
+	var categories []Category
+	for rows.Next() {
+		c := Category{}
+		var description sql.NullString
+		var parentID, mediaID sql.NullInt64
+
+		if err := rows.Scan(
+			&c.ID, &c.Name, &c.Slug, &description, &parentID, &mediaID,
+			&c.CreatedAt, &c.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		c.Description = description.String
+		if parentID.Valid {
+			c.ParentID = &parentID.Int64
+		}
+		if mediaID.Valid {
+			c.IDMedia = &mediaID.Int64
+		}
+		categories = append(categories, c)
+	}
+
+	return categories, rows.Err()
+}
@@ -3,16 +3,32 @@ package category
 import (
 	"context"
 	"ecom/internal/shared/errors"
+	"ecom/internal/shared/slug"
 	"fmt"
 	"time"
 )
 
+// ChangeListener recibe los cambios de categorías que afectan a sus productos
+// (los de la categoría y los de todas sus subcategorías)
+type ChangeListener interface {
+	CategoryChanged(ctx context.Context, id int64)
+}
+
 type Service struct {
-	repo Repository
+	repo     Repository
+	slugs    *slug.Namespace
+	listener ChangeListener
 }
 
-func NewService(repo Repository) *Service {
-	return &Service{repo: repo}
+// NewService crea el servicio; listener puede ser nil
+func NewService(repo Repository, slugs *slug.Namespace, listener ChangeListener) *Service {
+	return &Service{repo: repo, slugs: slugs, listener: listener}
+}
+
+func (s *Service) notifyChanged(ctx context.Context, id int64) {
+	if s.listener != nil {
+		s.listener.CategoryChanged(ctx, id)
+	}
 }
 
 func (s *Service) Create(ctx context.Context, req *CreateCategoryRequest) (*CategoryResponse, error) {
@@ -24,9 +40,20 @@ func (s *Service) Create(ctx context.Context, req *CreateCategoryRequest) (*Cate
 			}
 	}
 
+	categorySlug := req.Slug
+	if categorySlug == "" {
+			generated, err := s.generateSlug(ctx, req.Name, 0)
+			if err != nil {
+				return nil, err
+			}
+			categorySlug = generated
+	} else if err := s.checkSlugAvailable(ctx, categorySlug, 0); err != nil {
+			return nil, err
+	}
+
 	category := &Category{
 			Name:        req.Name,
-			Slug:        req.Slug,
+			Slug:        categorySlug,
 			Description: req.Description,
 			ParentID:    req.ParentID,
 			IDMedia:     req.IDMedia,
@@ -55,31 +82,26 @@ func (s *Service) Update(ctx context.Context, id int64, req *UpdateCategoryReque
 			return nil, err
 	}
 
+	// Sin slug explícito, un cambio de nombre regenera el slug; el anterior queda como redirección
+	if req.Slug != nil && *req.Slug != category.Slug {
+			if err := s.checkSlugAvailable(ctx, *req.Slug, id); err != nil {
+				return nil, err
+			}
+			category.Slug = *req.Slug
+	} else if req.Slug == nil && req.Name != nil && *req.Name != category.Name {
+			if category.Slug, err = s.generateSlug(ctx, *req.Name, id); err != nil {
+				return nil, err
+			}
+	}
 	if req.Name != nil {
 			category.Name = *req.Name
 	}
-	if req.Slug != nil {
-			category.Slug = *req.Slug
-	}
 	if req.Description != nil {
 			category.Description = *req.Description
 	}
 	
-	if req.ParentID != nil {		
-		if *req.ParentID == id {
-			return nil, errors.NewValidationError("La categoría no puede tener un parent_id igual al id")
-		}
-		// Validar que existe la categoría padre
-		if req.ParentID != nil && *req.ParentID != 0 {
-			_, err := s.repo.GetByID(ctx, *req.ParentID)
-			if err != nil {
-				return nil, errors.NewBadRequestError("Categoría padre no encontrada")
-			}
-		}
-		category.ParentID = req.ParentID
-	} else{		
-		category.ParentID = req.ParentID
-	}
+	// El repositorio valida que el padre exista y no sea descendiente de esta, con la cadena bloqueada
+	category.ParentID = req.ParentID
 
 	if req.IDMedia != nil {
 			category.IDMedia = req.IDMedia
@@ -91,6 +113,7 @@ func (s *Service) Update(ctx context.Context, id int64, req *UpdateCategoryReque
 	if err := s.repo.Update(ctx, category); err != nil {
 			return nil, err
 	}
+	s.notifyChanged(ctx, id)
 
 	return s.buildResponse(category), nil
 }
@@ -105,7 +128,11 @@ func (s *Service) Delete(ctx context.Context, id int64) error {
 			return errors.NewBadRequestError("No se puede eliminar una categoría con subcategorías")
 	}
 
-	return s.repo.Delete(ctx, id)
+	if err := s.repo.Delete(ctx, id); err != nil {
+			return err
+	}
+	s.notifyChanged(ctx, id)
+	return nil
 }
 
 func (s *Service) List(ctx context.Context, p *Pagination) ([]CategoryResponse, int64, error) {
@@ -137,4 +164,126 @@ func (s *Service) buildResponse(c *Category) *CategoryResponse {
 			CreatedAt:   c.CreatedAt,
 			UpdatedAt:   c.UpdatedAt,
 	}
-}
\ No newline at end of file
+}
+
+// Move cambia el padre de una categoría; sus subcategorías se mueven con ella
+func (s *Service) Move(ctx context.Context, id int64, req *MoveCategoryRequest) (*CategoryResponse, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	parentID := req.ParentID
+	if parentID != nil && *parentID == 0 {
+		parentID = nil
+	}
+	if err := s.repo.UpdateParent(ctx, id, parentID); err != nil {
+		return nil, err
+	}
+	s.notifyChanged(ctx, id)
+
+	return s.GetByID(ctx, id)
+}
+
+// GetBreadcrumbs devuelve la ruta desde la raíz hasta la categoría (incluida)
+func (s *Service) GetBreadcrumbs(ctx context.Context, id int64) ([]BreadcrumbItem, error) {
+	ancestors, err := s.repo.GetAncestors(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	breadcrumbs := make([]BreadcrumbItem, len(ancestors))
+	for i, a := range ancestors {
+		breadcrumbs[i] = BreadcrumbItem{ID: a.ID, Name: a.Name, Slug: a.Slug, ParentID: a.ParentID}
+	}
+	return breadcrumbs, nil
+}
+
+// GetTree devuelve la jerarquía de categorías anidada. Las categorías cuyo padre
+// ya no existe se muestran como raíces
+func (s *Service) GetTree(ctx context.Context, req *TreeRequest) ([]*CategoryTreeNode, error) {
+	categories, err := s.repo.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var counts map[int64]int64
+	if req.IncludeCounts {
+		counts, err = s.repo.CountProductsByCategory(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	nodes := make(map[int64]*CategoryTreeNode, len(categories))
+	for _, c := range categories {
+		nodes[c.ID] = &CategoryTreeNode{
+			ID:          c.ID,
+			Name:        c.Name,
+			Slug:        c.Slug,
+			Description: c.Description,
+			ParentID:    c.ParentID,
+			IDMedia:     c.IDMedia,
+			Children:    []*CategoryTreeNode{},
+		}
+	}
+
+	roots := []*CategoryTreeNode{}
+	for _, c := range categories {
+		node := nodes[c.ID]
+		if c.ParentID != nil {
+			if parent, ok := nodes[*c.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+
+	if req.RootID != nil {
+		root, ok := nodes[*req.RootID]
+		if !ok {
+			return nil, errors.NewNotFoundError("Categoría no encontrada")
+		}
+		roots = []*CategoryTreeNode{root}
+	}
+
+	for _, root := range roots {
+		completeNode(root, 0, req.MaxDepth, counts)
+	}
+	return roots, nil
+}
+
+// completeNode asigna la profundidad y los contadores de productos (propios y de los
+// descendientes) y recorta los hijos por debajo de maxDepth. Devuelve el total del subárbol
+func completeNode(node *CategoryTreeNode, depth, maxDepth int, counts map[int64]int64) int64 {
+	node.Depth = depth
+
+	total := counts[node.ID]
+	for _, child := range node.Children {
+		total += completeNode(child, depth+1, maxDepth, counts)
+	}
+
+	if counts != nil {
+		own := counts[node.ID]
+		node.ProductCount = &own
+		node.TotalCount = &total
+	}
+	if maxDepth > 0 && depth+1 >= maxDepth {
+		node.Children = []*CategoryTreeNode{}
+	}
+	return total
+}
+
+// generateSlug genera a partir del nombre un slug que no use ni haya usado otra categoría o producto
+func (s *Service) generateSlug(ctx context.Context, name string, id int64) (string, error) {
+	base := slug.Make(name)
+	if base == "" {
+		base = "categoria"
+	}
+	return s.slugs.Generate(ctx, base, slug.EntityCategory, id)
+}
+
+// checkSlugAvailable comprueba que ninguna otra categoría ni producto use ni haya usado el slug
+func (s *Service) checkSlugAvailable(ctx context.Context, value string, id int64) error {
+	return s.slugs.Check(ctx, value, slug.EntityCategory, id)
+}
@@ -1,6 +1,15 @@
 package currency
 
-import "time"
+import (
+	"ecom/internal/shared/money"
+	"strings"
+	"time"
+)
+
+const (
+    SymbolBefore = "before"
+    SymbolAfter  = "after"
+)
 
 type Currency struct {
     ID        int64     `json:"id"`
@@ -9,16 +18,115 @@ type Currency struct {
     Symbol    string    `json:"symbol"`
     IsBase    bool      `json:"is_base"`
     Active    bool      `json:"active"`
+    // Reglas de redondeo y formato para mostrar importes en esta moneda
+    DecimalPlaces      int32          `json:"decimal_places"`
+    RoundingMode       string         `json:"rounding_mode,omitempty"` // Vacío = redondeo por defecto
+    RoundingStep       *money.Decimal `json:"rounding_step,omitempty"` // Ej. 0.05 o 1
+    CharmEnding        *money.Decimal `json:"charm_ending,omitempty"`  // Ej. 0.90 o 0.99
+    SymbolPosition     string         `json:"symbol_position"`         // before | after
+    DecimalSeparator   string         `json:"decimal_separator"`
+    ThousandsSeparator string         `json:"thousands_separator"`
     CreatedAt time.Time `json:"created_at"`
     UpdatedAt time.Time `json:"updated_at"`
 }
 
+func (c *Currency) roundingMode() money.RoundingMode {
+    if c.RoundingMode == "" {
+        return money.DefaultRounding()
+    }
+    return money.RoundingMode(c.RoundingMode)
+}
+
+// ApplyPricing aplica al importe ya convertido el paso de redondeo, los decimales y la
+// terminación comercial de la moneda. Con el modo "down" la terminación se busca por debajo
+// del importe; con el resto, por encima
+func (c *Currency) ApplyPricing(amount money.Decimal) money.Decimal {
+    mode := c.roundingMode()
+
+    if c.RoundingStep != nil {
+        amount = money.RoundToStep(amount, *c.RoundingStep, mode)
+    }
+    amount = money.RoundWith(amount, c.DecimalPlaces, mode)
+
+    if c.CharmEnding == nil || !amount.IsPositive() {
+        return amount
+    }
+
+    one := money.NewFromInt(1)
+    charmed := amount.Floor().Add(*c.CharmEnding)
+    if mode == money.RoundDown {
+        if charmed.GreaterThan(amount) {
+            charmed = charmed.Sub(one)
+        }
+        if !charmed.IsPositive() {
+            return amount
+        }
+    } else if charmed.LessThan(amount) {
+        charmed = charmed.Add(one)
+    }
+    return charmed
+}
+
+// Format devuelve el importe con el símbolo y los separadores de la moneda, ej. "$1,234.90" o "1.234,90 €"
+func (c *Currency) Format(amount money.Decimal) string {
+    text := amount.Abs().StringFixed(c.DecimalPlaces)
+    integer, fraction, _ := strings.Cut(text, ".")
+
+    var b strings.Builder
+    for i, digit := range integer {
+        if i > 0 && (len(integer)-i)%3 == 0 {
+            b.WriteString(c.ThousandsSeparator)
+        }
+        b.WriteRune(digit)
+    }
+    if fraction != "" {
+        separator := c.DecimalSeparator
+        if separator == "" {
+            separator = "."
+        }
+        b.WriteString(separator)
+        b.WriteString(fraction)
+    }
+
+    sign := ""
+    if amount.IsNegative() {
+        sign = "-"
+    }
+    if c.SymbolPosition == SymbolAfter {
+        return sign + b.String() + " " + c.Symbol
+    }
+    return sign + c.Symbol + b.String()
+}
+
+// LocalizedPrice es un importe convertido a la moneda del cliente y listo para mostrar
+type LocalizedPrice struct {
+    Amount       money.Decimal  `json:"amount"`
+    CurrencyCode string         `json:"currency_code"`
+    Symbol       string         `json:"symbol"`
+    Formatted    string         `json:"formatted"`
+    ExchangeRate *money.Decimal `json:"exchange_rate,omitempty"`
+}
+
 type ExchangeRate struct {
     ID              int64     `json:"id"`
     FromCurrencyID  int64     `json:"from_currency_id"`
     ToCurrencyID    int64     `json:"to_currency_id"`
-    Rate            float64   `json:"rate"`
+    Rate            money.Decimal `json:"rate"`
     FromCurrency    *Currency `json:"from_currency,omitempty"`
     ToCurrency      *Currency `json:"to_currency,omitempty"`
     CreatedAt       time.Time `json:"created_at"`
-}
\ No newline at end of file
+}
+
+const (
+    RepricedSourcePrice = "price"
+    RepricedSourceTier  = "tier"
+)
+
+// RepricedAmount es un importe de una lista de precios afectado por el cambio de moneda base
+type RepricedAmount struct {
+    Source      string        `json:"source"` // price | tier
+    ID          int64         `json:"id"`
+    IDPriceList int64         `json:"id_price_list"`
+    Amount      money.Decimal `json:"amount"`
+    NewAmount   money.Decimal `json:"new_amount"`
+}
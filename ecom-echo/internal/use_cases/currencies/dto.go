@@ -1,6 +1,9 @@
 package currency
 
-import "time"
+import (
+	"ecom/internal/shared/money"
+	"time"
+)
 
 type CreateCurrencyRequest struct {
 	Name    string `json:"name" validate:"required,min=2"`
@@ -8,6 +11,14 @@ type CreateCurrencyRequest struct {
 	Symbol  string `json:"symbol" validate:"required"`
 	IsBase  bool   `json:"is_base"`
 	Active  bool   `json:"active"`
+	// Formato y redondeo de los precios mostrados en esta moneda
+	DecimalPlaces      *int32         `json:"decimal_places,omitempty" validate:"omitempty,min=0,max=4"`
+	RoundingMode       string         `json:"rounding_mode,omitempty" validate:"omitempty,oneof=half_up half_even up down"`
+	RoundingStep       *money.Decimal `json:"rounding_step,omitempty" validate:"omitempty,gt=0"`
+	CharmEnding        *money.Decimal `json:"charm_ending,omitempty" validate:"omitempty,gt=0,lt=1"`
+	SymbolPosition     string         `json:"symbol_position,omitempty" validate:"omitempty,oneof=before after"`
+	DecimalSeparator   string         `json:"decimal_separator,omitempty" validate:"omitempty,len=1"`
+	ThousandsSeparator *string        `json:"thousands_separator,omitempty" validate:"omitempty,max=1"`
 }
 
 type UpdateCurrencyRequest struct {
@@ -16,12 +27,28 @@ type UpdateCurrencyRequest struct {
 	Symbol  *string `json:"symbol,omitempty"`
 	IsBase  *bool   `json:"is_base,omitempty"`
 	Active  *bool   `json:"active,omitempty"`
+	DecimalPlaces      *int32         `json:"decimal_places,omitempty" validate:"omitempty,min=0,max=4"`
+	RoundingMode       *string        `json:"rounding_mode,omitempty" validate:"omitempty,oneof=half_up half_even up down"`
+	RoundingStep       *money.Decimal `json:"rounding_step,omitempty" validate:"omitempty,min=0"` // 0 elimina el paso
+	CharmEnding        *money.Decimal `json:"charm_ending,omitempty" validate:"omitempty,min=0,lt=1"` // 0 elimina la terminación
+	SymbolPosition     *string        `json:"symbol_position,omitempty" validate:"omitempty,oneof=before after"`
+	DecimalSeparator   *string        `json:"decimal_separator,omitempty" validate:"omitempty,len=1"`
+	ThousandsSeparator *string        `json:"thousands_separator,omitempty" validate:"omitempty,max=1"`
+	// Qué hacer con las listas de precios al pasar a moneda base: convert | relabel (por defecto)
+	BaseSwitchMode string `json:"base_switch_mode,omitempty" validate:"omitempty,oneof=convert relabel"`
+}
+
+// changesOtherFields indica si la petición modifica algo además de is_base
+func (r *UpdateCurrencyRequest) changesOtherFields() bool {
+	return r.Name != nil || r.Code != nil || r.Symbol != nil || r.Active != nil ||
+		r.DecimalPlaces != nil || r.RoundingMode != nil || r.RoundingStep != nil || r.CharmEnding != nil ||
+		r.SymbolPosition != nil || r.DecimalSeparator != nil || r.ThousandsSeparator != nil
 }
 
 type CreateExchangeRateRequest struct {
 	FromCurrencyID int64   `json:"from_currency_id" validate:"required"`
 	ToCurrencyID   int64   `json:"to_currency_id" validate:"required"`
-	Rate           float64 `json:"rate" validate:"required,gt=0"`
+	Rate           money.Decimal `json:"rate" validate:"required,gt=0"`
 }
 
 type CurrencyResponse struct {
@@ -31,6 +58,13 @@ type CurrencyResponse struct {
 	Symbol    string    `json:"symbol"`
 	IsBase    bool      `json:"is_base"`
 	Active    bool      `json:"active"`
+	DecimalPlaces      int32          `json:"decimal_places"`
+	RoundingMode       string         `json:"rounding_mode,omitempty"`
+	RoundingStep       *money.Decimal `json:"rounding_step,omitempty"`
+	CharmEnding        *money.Decimal `json:"charm_ending,omitempty"`
+	SymbolPosition     string         `json:"symbol_position"`
+	DecimalSeparator   string         `json:"decimal_separator"`
+	ThousandsSeparator string         `json:"thousands_separator"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -39,7 +73,7 @@ type ExchangeRateResponse struct {
 	ID           int64     `json:"id"`
 	FromCurrency *Currency `json:"from_currency"`
 	ToCurrency   *Currency `json:"to_currency"`
-	Rate         float64   `json:"rate"`
+	Rate         money.Decimal `json:"rate"`
 	CreatedAt    time.Time `json:"created_at"`
 }
 
@@ -51,16 +85,67 @@ type Pagination struct {
 }
 
 type ConvertAmountRequest struct {
-	Amount            float64 `json:"amount" validate:"required,gt=0"`
+	Amount            money.Decimal `json:"amount" validate:"required,gt=0"`
 	FromCurrencyCode string  `json:"from_currency_code" validate:"required,len=3"`
 	ToCurrencyCode   string  `json:"to_currency_code" validate:"required,len=3"`
+	At               *time.Time `json:"at,omitempty"` // Convertir con el tipo vigente en ese instante
 }
 
 type ConvertAmountResponse struct {
-	OriginalAmount   float64  `json:"original_amount"`
-	ConvertedAmount  float64  `json:"converted_amount"`
+	OriginalAmount   money.Decimal `json:"original_amount"`
+	ConvertedAmount  money.Decimal `json:"converted_amount"`
 	FromCurrency     Currency `json:"from_currency"`
 	ToCurrency       Currency `json:"to_currency"`
-	ExchangeRate     float64  `json:"exchange_rate"`
+	ExchangeRate     money.Decimal `json:"exchange_rate"`
+	Via              *Currency `json:"via,omitempty"`       // Moneda intermedia si se usó un tipo cruzado
+	RateDate         *time.Time `json:"rate_date,omitempty"` // Fecha de registro del tipo aplicado
 	ConvertedAt      time.Time `json:"converted_at"`
-}
\ No newline at end of file
+}
+
+// RefreshExchangeRatesRequest permite aceptar tipos que superan el umbral de variación, por
+// ejemplo tras revisar una devaluación que el refresco automático descarta una y otra vez
+type RefreshExchangeRatesRequest struct {
+	Accept []ExchangeRatePair `json:"accept" validate:"dive"`
+}
+
+type ExchangeRatePair struct {
+	FromCurrency string `json:"from_currency" validate:"required,len=3"`
+	ToCurrency   string `json:"to_currency" validate:"required,len=3"`
+}
+
+// SkippedExchangeRate es un tipo de cambio descartado por superar el umbral de variación
+type SkippedExchangeRate struct {
+	FromCurrency  string        `json:"from_currency"`
+	ToCurrency    string        `json:"to_currency"`
+	Rate          money.Decimal `json:"rate"`
+	PreviousRate  money.Decimal `json:"previous_rate"`
+	ChangePercent money.Decimal `json:"change_percent"`
+}
+
+type RefreshExchangeRatesResponse struct {
+	Provider  string                `json:"provider"`
+	Base      string                `json:"base"`
+	RatesDate time.Time             `json:"rates_date"`
+	Created   int                   `json:"created"`
+	Unchanged int                   `json:"unchanged"`
+	Skipped   []SkippedExchangeRate `json:"skipped"`
+	Accepted  []SkippedExchangeRate `json:"accepted,omitempty"` // Superaban el umbral y se guardaron porque se pidió aceptarlos
+	Missing   []string              `json:"missing,omitempty"` // Monedas activas que la fuente no publica
+}
+
+type BaseSwitchRequest struct {
+	Mode string `json:"mode" query:"mode" validate:"required,oneof=convert relabel"`
+}
+
+// BaseSwitchResponse resume el efecto del cambio de moneda base sobre las listas de precios
+type BaseSwitchResponse struct {
+	FromCurrency *Currency        `json:"from_currency"`
+	ToCurrency   *Currency        `json:"to_currency"`
+	Mode         string           `json:"mode"`
+	ExchangeRate *money.Decimal   `json:"exchange_rate,omitempty"`
+	Applied      bool             `json:"applied"`
+	PriceLists   int64            `json:"price_lists"`
+	Prices       int              `json:"prices"`
+	Tiers        int              `json:"tiers"`
+	Rows         []RepricedAmount `json:"rows"`
+}
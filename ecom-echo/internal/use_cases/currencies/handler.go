@@ -24,9 +24,12 @@ func NewHandler(e *echo.Group, s *Service) {
 	e.PUT("/:id", h.UpdateCurrency)
 	e.DELETE("/:id", h.DeleteCurrency)
 	e.GET("/base", h.GetBaseCurrency)
+	e.GET("/:id/base-switch/preview", h.PreviewBaseSwitch)
+	e.POST("/:id/base-switch", h.SwitchBaseCurrency)
 
 	// Exchange rate routes
 	e.POST("/rates", h.CreateExchangeRate)
+	e.POST("/rates/refresh", h.RefreshExchangeRates)
 	e.GET("/rates/:currency_id", h.GetExchangeRates)
 	e.POST("/convert", h.ConvertAmount)
 }
@@ -142,6 +145,60 @@ func (h *Handler) ListCurrencies(c echo.Context) error {
 					}))
 }
 
+func (h *Handler) PreviewBaseSwitch(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID inválido", err.Error()))
+	}
+
+	var req BaseSwitchRequest
+	if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error al procesar la solicitud", err.Error()))
+	}
+
+	if err := c.Validate(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error de validación", err.Error()))
+	}
+
+	result, err := h.service.PreviewBaseSwitch(c.Request().Context(), id, &req)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Vista previa del cambio de moneda base", result))
+}
+
+func (h *Handler) SwitchBaseCurrency(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID inválido", err.Error()))
+	}
+
+	var req BaseSwitchRequest
+	if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error al procesar la solicitud", err.Error()))
+	}
+
+	if err := c.Validate(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error de validación", err.Error()))
+	}
+
+	result, err := h.service.SwitchBaseCurrency(c.Request().Context(), id, &req)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Moneda base actualizada exitosamente", result))
+}
+
 func (h *Handler) CreateExchangeRate(c echo.Context) error {
 	var req CreateExchangeRateRequest
 	if err := c.Bind(&req); err != nil {
@@ -163,6 +220,27 @@ func (h *Handler) CreateExchangeRate(c echo.Context) error {
 			response.Success("Tipo de cambio creado exitosamente", rate))
 }
 
+func (h *Handler) RefreshExchangeRates(c echo.Context) error {
+	var req RefreshExchangeRatesRequest
+	if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error al procesar la solicitud", err.Error()))
+	}
+
+	if err := c.Validate(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error de validación", err.Error()))
+	}
+
+	result, err := h.service.RefreshExchangeRates(c.Request().Context(), &req)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Tipos de cambio actualizados exitosamente", result))
+}
+
 func (h *Handler) GetExchangeRates(c echo.Context) error {
 	currencyID, err := strconv.ParseInt(c.Param("currency_id"), 10, 64)
 	if err != nil {
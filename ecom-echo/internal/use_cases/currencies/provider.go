@@ -0,0 +1,212 @@
+// provider.go
+package currency
+
+import (
+	"context"
+	"ecom/internal/shared/money"
+	"ecom/internal/shared/spreadsheet"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	ProviderECB = "ecb"
+	ProviderCSV = "csv"
+
+	DefaultECBSource = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"
+)
+
+// RateSnapshot son los tipos de cambio publicados por una fuente respecto a su moneda base
+type RateSnapshot struct {
+	Base  string
+	Date  time.Time
+	Rates map[string]money.Decimal // código -> unidades por 1 de la moneda base
+}
+
+// Cross calcula el tipo de cambio entre dos monedas de la publicación
+func (s *RateSnapshot) Cross(from, to string) (money.Decimal, bool) {
+	fromRate, ok := s.rate(from)
+	if !ok {
+		return money.Zero, false
+	}
+	toRate, ok := s.rate(to)
+	if !ok {
+		return money.Zero, false
+	}
+	return money.Round(toRate.Div(fromRate), money.RateScale), true
+}
+
+func (s *RateSnapshot) rate(code string) (money.Decimal, bool) {
+	if code == s.Base {
+		return money.NewFromInt(1), true
+	}
+	r, ok := s.Rates[code]
+	if !ok || r.Sign() <= 0 {
+		return money.Zero, false
+	}
+	return r, true
+}
+
+// ExchangeRateProvider obtiene los tipos de cambio vigentes de una fuente externa
+type ExchangeRateProvider interface {
+	Name() string
+	FetchRates(ctx context.Context) (*RateSnapshot, error)
+}
+
+// NewExchangeRateProvider crea el proveedor configurado; source puede ser una URL o una ruta local
+func NewExchangeRateProvider(name, source string) (ExchangeRateProvider, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	switch strings.ToLower(name) {
+	case ProviderECB:
+		if source == "" {
+			source = DefaultECBSource
+		}
+		return &ECBProvider{source: source, client: client}, nil
+	case ProviderCSV:
+		if source == "" {
+			return nil, fmt.Errorf("el proveedor csv requiere una fuente")
+		}
+		return &CSVProvider{source: source, client: client}, nil
+	}
+
+	return nil, fmt.Errorf("proveedor de tipos de cambio no soportado: %s", name)
+}
+
+// ECBProvider lee el XML diario de referencia con el formato del Banco Central Europeo
+type ECBProvider struct {
+	source string
+	client *http.Client
+}
+
+type ecbEnvelope struct {
+	Cube struct {
+		Days []struct {
+			Time  string `xml:"time,attr"`
+			Rates []struct {
+				Currency string `xml:"currency,attr"`
+				Rate     string `xml:"rate,attr"`
+			} `xml:"Cube"`
+		} `xml:"Cube"`
+	} `xml:"Cube"`
+}
+
+func (p *ECBProvider) Name() string {
+	return ProviderECB
+}
+
+func (p *ECBProvider) FetchRates(ctx context.Context) (*RateSnapshot, error) {
+	body, err := openSource(ctx, p.client, p.source)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var envelope ecbEnvelope
+	if err := xml.NewDecoder(body).Decode(&envelope); err != nil {
+		return nil, fmt.Errorf("error al leer el XML de tipos de cambio: %w", err)
+	}
+	if len(envelope.Cube.Days) == 0 {
+		return nil, fmt.Errorf("la fuente no contiene tipos de cambio")
+	}
+
+	// La publicación más reciente va primero
+	day := envelope.Cube.Days[0]
+	snapshot := &RateSnapshot{Base: "EUR", Rates: make(map[string]money.Decimal)}
+	if date, err := time.Parse("2006-01-02", day.Time); err == nil {
+		snapshot.Date = date
+	}
+
+	for _, r := range day.Rates {
+		rate, err := money.NewFromString(r.Rate)
+		if err != nil {
+			return nil, fmt.Errorf("tipo de cambio inválido para %s: %s", r.Currency, r.Rate)
+		}
+		snapshot.Rates[strings.ToUpper(r.Currency)] = rate
+	}
+
+	return snapshot, nil
+}
+
+// CSVProvider lee un CSV con las columnas base, currency y rate (y date opcional)
+type CSVProvider struct {
+	source string
+	client *http.Client
+}
+
+func (p *CSVProvider) Name() string {
+	return ProviderCSV
+}
+
+func (p *CSVProvider) FetchRates(ctx context.Context) (*RateSnapshot, error) {
+	body, err := openSource(ctx, p.client, p.source)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	table, err := spreadsheet.Read(body, spreadsheet.FormatCSV)
+	if err != nil {
+		return nil, err
+	}
+	for _, column := range []string{"base", "currency", "rate"} {
+		if table.Column(column) < 0 {
+			return nil, fmt.Errorf("falta la columna obligatoria \"%s\" en el CSV de tipos de cambio", column)
+		}
+	}
+
+	snapshot := &RateSnapshot{Rates: make(map[string]money.Decimal)}
+	for i, row := range table.Rows {
+		base := strings.ToUpper(table.Value(row, "base"))
+		if snapshot.Base == "" {
+			snapshot.Base = base
+		} else if base != snapshot.Base {
+			return nil, fmt.Errorf("fila %d: todas las filas deben tener la misma moneda base", i+2)
+		}
+
+		rate, err := money.NewFromString(table.Value(row, "rate"))
+		if err != nil {
+			return nil, fmt.Errorf("fila %d: tipo de cambio inválido", i+2)
+		}
+		snapshot.Rates[strings.ToUpper(table.Value(row, "currency"))] = rate
+
+		if date, err := time.Parse("2006-01-02", table.Value(row, "date")); err == nil {
+			snapshot.Date = date
+		}
+	}
+	if snapshot.Base == "" {
+		return nil, fmt.Errorf("la fuente no contiene tipos de cambio")
+	}
+
+	return snapshot, nil
+}
+
+// openSource abre una URL http(s) o un archivo local
+func openSource(ctx context.Context, client *http.Client, source string) (io.ReadCloser, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		f, err := os.Open(strings.TrimPrefix(source, "file://"))
+		if err != nil {
+			return nil, fmt.Errorf("error al abrir la fuente de tipos de cambio: %w", err)
+		}
+		return f, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar la fuente de tipos de cambio: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("la fuente de tipos de cambio respondió %d", resp.StatusCode)
+	}
+	return resp.Body, nil
+}
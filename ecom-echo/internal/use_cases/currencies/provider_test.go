@@ -0,0 +1,111 @@
+package currency
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const ecbSample = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<Cube>
+		<Cube time="2026-10-16">
+			<Cube currency="USD" rate="1.0842"/>
+			<Cube currency="gbp" rate="0.8321"/>
+		</Cube>
+		<Cube time="2026-10-15">
+			<Cube currency="USD" rate="1.0790"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+const csvSample = "base,currency,rate,date\nUSD,EUR,0.9223,2026-10-16\nUSD,MXN,18.4512,2026-10-16\n"
+
+func serve(status int, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+}
+
+func TestECBProviderParsesLatestDay(t *testing.T) {
+	srv := serve(http.StatusOK, ecbSample)
+	defer srv.Close()
+
+	provider, err := NewExchangeRateProvider(ProviderECB, srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := provider.FetchRates(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if snapshot.Base != "EUR" || snapshot.Date.Format("2006-01-02") != "2026-10-16" {
+		t.Fatalf("base %s fecha %s, se esperaba EUR 2026-10-16", snapshot.Base, snapshot.Date)
+	}
+	if len(snapshot.Rates) != 2 {
+		t.Fatalf("%d tipos, se esperaban 2", len(snapshot.Rates))
+	}
+	if got := snapshot.Rates["USD"].String(); got != "1.0842" {
+		t.Fatalf("USD = %s, se esperaba 1.0842 de la publicación más reciente", got)
+	}
+	if got := snapshot.Rates["GBP"].String(); got != "0.8321" {
+		t.Fatalf("GBP = %s, se esperaba 0.8321", got)
+	}
+
+	rate, ok := snapshot.Cross("USD", "GBP")
+	if !ok || rate.String() != "0.767478" {
+		t.Fatalf("USD->GBP = %s, se esperaba 0.767478", rate)
+	}
+}
+
+func TestCSVProviderParsesRows(t *testing.T) {
+	srv := serve(http.StatusOK, csvSample)
+	defer srv.Close()
+
+	provider, err := NewExchangeRateProvider(ProviderCSV, srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := provider.FetchRates(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if snapshot.Base != "USD" || snapshot.Date.Format("2006-01-02") != "2026-10-16" {
+		t.Fatalf("base %s fecha %s, se esperaba USD 2026-10-16", snapshot.Base, snapshot.Date)
+	}
+	if got := snapshot.Rates["MXN"].String(); got != "18.4512" {
+		t.Fatalf("MXN = %s, se esperaba 18.4512", got)
+	}
+}
+
+func TestCSVProviderRejectsMixedBases(t *testing.T) {
+	srv := serve(http.StatusOK, "base,currency,rate\nUSD,EUR,0.92\nEUR,GBP,0.83\n")
+	defer srv.Close()
+
+	provider, _ := NewExchangeRateProvider(ProviderCSV, srv.URL)
+	if _, err := provider.FetchRates(context.Background()); err == nil {
+		t.Fatal("se esperaba error por filas con distinta moneda base")
+	}
+}
+
+func TestProvidersFailOnNon200(t *testing.T) {
+	srv := serve(http.StatusServiceUnavailable, "mantenimiento")
+	defer srv.Close()
+
+	for _, name := range []string{ProviderECB, ProviderCSV} {
+		provider, err := NewExchangeRateProvider(name, srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = provider.FetchRates(context.Background())
+		if err == nil || !strings.Contains(err.Error(), "503") {
+			t.Fatalf("%s: error %v, se esperaba la respuesta 503 de la fuente", name, err)
+		}
+	}
+}
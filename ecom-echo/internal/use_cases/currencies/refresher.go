@@ -0,0 +1,52 @@
+// refresher.go
+package currency
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RateRefresher actualiza periódicamente los tipos de cambio desde el proveedor configurado
+type RateRefresher struct {
+	service  *Service
+	interval time.Duration
+}
+
+func NewRateRefresher(service *Service, interval time.Duration) *RateRefresher {
+	return &RateRefresher{service: service, interval: interval}
+}
+
+// Start lanza la actualización en segundo plano hasta que se cancele el contexto.
+// La primera actualización se hace al arrancar
+func (r *RateRefresher) Start(ctx context.Context) {
+	if !r.service.HasRateProvider() || r.interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			r.refresh(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (r *RateRefresher) refresh(ctx context.Context) {
+	result, err := r.service.RefreshExchangeRates(ctx, nil)
+	if err != nil {
+		log.Printf("Error al actualizar tipos de cambio: %v", err)
+		return
+	}
+
+	log.Printf("Tipos de cambio actualizados desde %s: %d nuevos, %d sin cambios, %d descartados",
+		result.Provider, result.Created, result.Unchanged, len(result.Skipped))
+}
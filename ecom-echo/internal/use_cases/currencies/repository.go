@@ -5,6 +5,8 @@ import (
 	"context"
 	"database/sql"
 	"ecom/internal/shared/errors"
+	"ecom/internal/shared/money"
+	"time"
 )
 
 type Repository interface {
@@ -13,6 +15,7 @@ type Repository interface {
 	GetCurrencyByID(ctx context.Context, id int64) (*Currency, error)
 	GetCurrencyByCode(ctx context.Context, code string) (*Currency, error)
 	GetBaseCurrency(ctx context.Context) (*Currency, error)
+	ListActiveCurrencies(ctx context.Context) ([]Currency, error)
 	UpdateCurrency(ctx context.Context, c *Currency) error
 	DeleteCurrency(ctx context.Context, id int64) error
 	ListCurrencies(ctx context.Context, p *Pagination) ([]Currency, int64, error)
@@ -20,11 +23,48 @@ type Repository interface {
 	// Exchange rate operations
 	CreateExchangeRate(ctx context.Context, e *ExchangeRate) error
 	GetLatestExchangeRate(ctx context.Context, fromCurrencyID, toCurrencyID int64) (*ExchangeRate, error)
+	GetExchangeRateAt(ctx context.Context, fromCurrencyID, toCurrencyID int64, at time.Time) (*ExchangeRate, error)
 	ListExchangeRates(ctx context.Context, currencyID int64) ([]ExchangeRate, error)
 	
 	// Special operations
-	SetBaseCurrency(ctx context.Context, currencyID int64) error
-	UpdateProductPricesTx(ctx context.Context, tx *sql.Tx, oldBaseCurrencyID, newBaseCurrencyID int64, rate float64) error
+	SetBaseCurrency(ctx context.Context, currencyID int64, rate *money.Decimal) ([]RepricedAmount, error)
+	ListBaseCurrencyAmounts(ctx context.Context, baseCurrencyID int64) ([]RepricedAmount, error)
+	CountBaseCurrencyPriceLists(ctx context.Context, baseCurrencyID int64) (int64, error)
+}
+
+// querier permite reutilizar las consultas dentro y fuera de una transacción
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+const currencyColumns = `id, name, code, symbol, is_base, active, decimal_places, rounding_mode,
+			rounding_step, charm_ending, symbol_position, decimal_separator, thousands_separator,
+			created_at, updated_at`
+
+// rowScanner lo cumplen tanto *sql.Row como *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanCurrency(row rowScanner) (*Currency, error) {
+	c := &Currency{}
+	var step, charm money.NullDecimal
+	err := row.Scan(
+			&c.ID, &c.Name, &c.Code, &c.Symbol, &c.IsBase, &c.Active,
+			&c.DecimalPlaces, &c.RoundingMode, &step, &charm,
+			&c.SymbolPosition, &c.DecimalSeparator, &c.ThousandsSeparator,
+			&c.CreatedAt, &c.UpdatedAt,
+	)
+	if err != nil {
+			return nil, err
+	}
+	if step.Valid {
+			c.RoundingStep = &step.Decimal
+	}
+	if charm.Valid {
+			c.CharmEnding = &charm.Decimal
+	}
+	return c, nil
 }
 
 type MySQLRepository struct {
@@ -52,11 +92,14 @@ func (r *MySQLRepository) CreateCurrency(ctx context.Context, c *Currency) error
 	}
 
 	query := `
-			INSERT INTO currencies (name, code, symbol, is_base, active, created_at, updated_at)
-			VALUES (?, ?, ?, ?, ?, NOW(), NOW())
+			INSERT INTO currencies (name, code, symbol, is_base, active, decimal_places, rounding_mode,
+				rounding_step, charm_ending, symbol_position, decimal_separator, thousands_separator,
+				created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
 	`
 	result, err := tx.ExecContext(ctx, query,
-			c.Name, c.Code, c.Symbol, c.IsBase, c.Active)
+			c.Name, c.Code, c.Symbol, c.IsBase, c.Active, c.DecimalPlaces, c.RoundingMode,
+			c.RoundingStep, c.CharmEnding, c.SymbolPosition, c.DecimalSeparator, c.ThousandsSeparator)
 	if err != nil {
 			tx.Rollback()
 			return errors.NewMysqlError(err)
@@ -74,15 +117,11 @@ func (r *MySQLRepository) CreateCurrency(ctx context.Context, c *Currency) error
 
 func (r *MySQLRepository) GetCurrencyByID(ctx context.Context, id int64) (*Currency, error) {
 	query := `
-			SELECT id, name, code, symbol, is_base, active, created_at, updated_at
+			SELECT `+currencyColumns+`
 			FROM currencies
 			WHERE id = ? AND deleted_at IS NULL
 	`
-	c := &Currency{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-			&c.ID, &c.Name, &c.Code, &c.Symbol, &c.IsBase, &c.Active,
-			&c.CreatedAt, &c.UpdatedAt,
-	)
+	c, err := scanCurrency(r.db.QueryRowContext(ctx, query, id))
 	if err == sql.ErrNoRows {
 			return nil, errors.NewNotFoundError("Moneda no encontrada")
 	}
@@ -94,15 +133,11 @@ func (r *MySQLRepository) GetCurrencyByID(ctx context.Context, id int64) (*Curre
 
 func (r *MySQLRepository) GetCurrencyByCode(ctx context.Context, code string) (*Currency, error) {
 	query := `
-			SELECT id, name, code, symbol, is_base, active, created_at, updated_at
+			SELECT `+currencyColumns+`
 			FROM currencies
 			WHERE code = ? AND deleted_at IS NULL
 	`
-	c := &Currency{}
-	err := r.db.QueryRowContext(ctx, query, code).Scan(
-			&c.ID, &c.Name, &c.Code, &c.Symbol, &c.IsBase, &c.Active,
-			&c.CreatedAt, &c.UpdatedAt,
-	)
+	c, err := scanCurrency(r.db.QueryRowContext(ctx, query, code))
 	if err == sql.ErrNoRows {
 			return nil, errors.NewNotFoundError("Moneda no encontrada")
 	}
@@ -114,15 +149,11 @@ func (r *MySQLRepository) GetCurrencyByCode(ctx context.Context, code string) (*
 
 func (r *MySQLRepository) GetBaseCurrency(ctx context.Context) (*Currency, error) {
 	query := `
-			SELECT id, name, code, symbol, is_base, active, created_at, updated_at
+			SELECT `+currencyColumns+`
 			FROM currencies
 			WHERE is_base = true AND deleted_at IS NULL
 	`
-	c := &Currency{}
-	err := r.db.QueryRowContext(ctx, query).Scan(
-			&c.ID, &c.Name, &c.Code, &c.Symbol, &c.IsBase, &c.Active,
-			&c.CreatedAt, &c.UpdatedAt,
-	)
+	c, err := scanCurrency(r.db.QueryRowContext(ctx, query))
 	if err == sql.ErrNoRows {
 			return nil, errors.NewNotFoundError("Moneda base no encontrada")
 	}
@@ -151,11 +182,15 @@ func (r *MySQLRepository) UpdateCurrency(ctx context.Context, c *Currency) error
 
 	query := `
 			UPDATE currencies 
-			SET name = ?, code = ?, symbol = ?, is_base = ?, active = ?, updated_at = NOW()
+			SET name = ?, code = ?, symbol = ?, is_base = ?, active = ?, decimal_places = ?,
+				rounding_mode = ?, rounding_step = ?, charm_ending = ?, symbol_position = ?,
+				decimal_separator = ?, thousands_separator = ?, updated_at = NOW()
 			WHERE id = ? AND deleted_at IS NULL
 	`
 	result, err := tx.ExecContext(ctx, query,
-			c.Name, c.Code, c.Symbol, c.IsBase, c.Active, c.ID)
+			c.Name, c.Code, c.Symbol, c.IsBase, c.Active, c.DecimalPlaces,
+			c.RoundingMode, c.RoundingStep, c.CharmEnding, c.SymbolPosition,
+			c.DecimalSeparator, c.ThousandsSeparator, c.ID)
 	if err != nil {
 			tx.Rollback()
 			return errors.NewMysqlError(err)
@@ -200,7 +235,7 @@ func (r *MySQLRepository) ListCurrencies(ctx context.Context, p *Pagination) ([]
 	}
 
 	query := `
-			SELECT id, name, code, symbol, is_base, active, created_at, updated_at
+			SELECT `+currencyColumns+`
 			FROM currencies
 			WHERE deleted_at IS NULL
 	`
@@ -224,20 +259,41 @@ func (r *MySQLRepository) ListCurrencies(ctx context.Context, p *Pagination) ([]
 
 	var currencies []Currency
 	for rows.Next() {
-			var c Currency
-			err := rows.Scan(
-					&c.ID, &c.Name, &c.Code, &c.Symbol, &c.IsBase, &c.Active,
-					&c.CreatedAt, &c.UpdatedAt,
-			)
+			c, err := scanCurrency(rows)
 			if err != nil {
 					return nil, 0, err
 			}
-			currencies = append(currencies, c)
+			currencies = append(currencies, *c)
 	}
 
 	return currencies, total, nil
 }
 
+func (r *MySQLRepository) ListActiveCurrencies(ctx context.Context) ([]Currency, error) {
+	query := `
+			SELECT `+currencyColumns+`
+			FROM currencies
+			WHERE active = true AND deleted_at IS NULL
+			ORDER BY is_base DESC, code ASC
+	`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+			return nil, errors.NewMysqlError(err)
+	}
+	defer rows.Close()
+
+	var currencies []Currency
+	for rows.Next() {
+			c, err := scanCurrency(rows)
+			if err != nil {
+					return nil, errors.NewMysqlError(err)
+			}
+			currencies = append(currencies, *c)
+	}
+
+	return currencies, rows.Err()
+}
+
 func (r *MySQLRepository) CreateExchangeRate(ctx context.Context, e *ExchangeRate) error {
 	query := `
 			INSERT INTO exchange_rates (from_currency_id, to_currency_id, rate, created_at)
@@ -259,6 +315,15 @@ func (r *MySQLRepository) CreateExchangeRate(ctx context.Context, e *ExchangeRat
 }
 
 func (r *MySQLRepository) GetLatestExchangeRate(ctx context.Context, fromCurrencyID, toCurrencyID int64) (*ExchangeRate, error) {
+	return r.queryExchangeRate(ctx, "", fromCurrencyID, toCurrencyID)
+}
+
+// GetExchangeRateAt devuelve el tipo de cambio vigente en un instante: el último registrado antes de at
+func (r *MySQLRepository) GetExchangeRateAt(ctx context.Context, fromCurrencyID, toCurrencyID int64, at time.Time) (*ExchangeRate, error) {
+	return r.queryExchangeRate(ctx, " AND er.created_at <= ?", fromCurrencyID, toCurrencyID, at)
+}
+
+func (r *MySQLRepository) queryExchangeRate(ctx context.Context, condition string, args ...interface{}) (*ExchangeRate, error) {
 	query := `
 			SELECT er.id, er.from_currency_id, er.to_currency_id, er.rate, er.created_at,
 							fc.id, fc.name, fc.code, fc.symbol, fc.is_base, fc.active, fc.created_at, fc.updated_at,
@@ -266,8 +331,8 @@ func (r *MySQLRepository) GetLatestExchangeRate(ctx context.Context, fromCurrenc
 			FROM exchange_rates er
 			JOIN currencies fc ON fc.id = er.from_currency_id
 			JOIN currencies tc ON tc.id = er.to_currency_id
-			WHERE er.from_currency_id = ? AND er.to_currency_id = ?
-			ORDER BY er.created_at DESC
+			WHERE er.from_currency_id = ? AND er.to_currency_id = ?` + condition + `
+			ORDER BY er.created_at DESC, er.id DESC
 			LIMIT 1
 	`
 	
@@ -276,7 +341,7 @@ func (r *MySQLRepository) GetLatestExchangeRate(ctx context.Context, fromCurrenc
 			ToCurrency:   &Currency{},
 	}
 
-	err := r.db.QueryRowContext(ctx, query, fromCurrencyID, toCurrencyID).Scan(
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(
 			&e.ID, &e.FromCurrencyID, &e.ToCurrencyID, &e.Rate, &e.CreatedAt,
 			&e.FromCurrency.ID, &e.FromCurrency.Name, &e.FromCurrency.Code,
 			&e.FromCurrency.Symbol, &e.FromCurrency.IsBase, &e.FromCurrency.Active,
@@ -340,10 +405,12 @@ func (r *MySQLRepository) ListExchangeRates(ctx context.Context, currencyID int6
 	return rates, nil
 }
 
-func (r *MySQLRepository) SetBaseCurrency(ctx context.Context, currencyID int64) error {
+// SetBaseCurrency cambia la moneda base. Las listas de precios en la moneda base anterior pasan
+// a la nueva; si se indica rate, sus importes se convierten en la misma transacción
+func (r *MySQLRepository) SetBaseCurrency(ctx context.Context, currencyID int64, rate *money.Decimal) ([]RepricedAmount, error) {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {		
-		return err
+		return nil, err
 	}
 
 	// Obtener la moneda base actual
@@ -353,24 +420,34 @@ func (r *MySQLRepository) SetBaseCurrency(ctx context.Context, currencyID int64)
 			Scan(&oldBaseCurrencyID)
 	if err != nil && err != sql.ErrNoRows {
 		tx.Rollback()
-		return errors.NewMysqlError(err)
+		return nil, errors.NewMysqlError(err)
 	}
 
-	// Obtener el tipo de cambio entre la antigua y nueva moneda base
-	if oldBaseCurrencyID != 0 {
-		var rate float64
-		err = tx.QueryRowContext(ctx, `
-				SELECT rate FROM exchange_rates 
-				WHERE from_currency_id = ? AND to_currency_id = ?
-				ORDER BY created_at DESC LIMIT 1
-		`, oldBaseCurrencyID, currencyID).Scan(&rate)
-		if err == nil {
-			if err := r.UpdateProductPricesTx(ctx, tx, oldBaseCurrencyID, currencyID, rate); err != nil {
+	var amounts []RepricedAmount
+	if oldBaseCurrencyID != 0 && oldBaseCurrencyID != currencyID {
+		amounts, err = listBaseCurrencyAmounts(ctx, tx, oldBaseCurrencyID, " FOR UPDATE")
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		if rate != nil {
+			if err := r.repriceAmountsTx(ctx, tx, amounts, *rate); err != nil {
 				tx.Rollback()
-				return err
+				return nil, err
 			}
 		}
-		
+
+		// Re-etiquetar las listas con la nueva moneda base
+		_, err = tx.ExecContext(ctx, `
+				UPDATE price_lists
+				SET id_currency = ?
+				WHERE deleted_at IS NULL AND (id_currency IS NULL OR id_currency = ?)
+		`, currencyID, oldBaseCurrencyID)
+		if err != nil {
+			tx.Rollback()
+			return nil, errors.NewMysqlError(err)
+		}
 	}
 
 	// Desactivar la moneda base actual
@@ -378,7 +455,7 @@ func (r *MySQLRepository) SetBaseCurrency(ctx context.Context, currencyID int64)
 			"UPDATE currencies SET is_base = false WHERE is_base = true AND deleted_at IS NULL")
 	if err != nil {		
 		tx.Rollback()
-		return errors.NewMysqlError(err)
+		return nil, errors.NewMysqlError(err)
 	}
 
 	// Establecer la nueva moneda base
@@ -387,31 +464,110 @@ func (r *MySQLRepository) SetBaseCurrency(ctx context.Context, currencyID int64)
 			currencyID)
 	if err != nil {
 			tx.Rollback()
-			return errors.NewMysqlError(err)
+			return nil, errors.NewMysqlError(err)
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return amounts, nil
 }
 
-func (r *MySQLRepository) UpdateProductPricesTx(ctx context.Context, tx *sql.Tx, oldBaseCurrencyID, newBaseCurrencyID int64, rate float64) error {
-	// Actualizar precios de productos que usan la moneda antigua
-	_, err := tx.ExecContext(ctx, `
-			UPDATE products 
-			SET price = price * ?,
-					currency_id = ?
-			WHERE currency_id = ? AND deleted_at IS NULL
-	`, rate, newBaseCurrencyID, oldBaseCurrencyID)
-	
+// repriceAmountsTx convierte los importes con el tipo de cambio y el redondeo configurado
+func (r *MySQLRepository) repriceAmountsTx(ctx context.Context, tx *sql.Tx, amounts []RepricedAmount, rate money.Decimal) error {
+	updatePrice, err := tx.PrepareContext(ctx, "UPDATE prices SET amount = ?, updated_at = NOW() WHERE id = ?")
 	if err != nil {
-			return errors.NewMysqlError(err)
+		return errors.NewMysqlError(err)
 	}
+	defer updatePrice.Close()
 
-	// También podríamos actualizar precios en otras tablas que manejen monedas
-	// Por ejemplo: orders, invoice_items, etc.
+	updateTier, err := tx.PrepareContext(ctx, "UPDATE price_tiers SET amount = ?, updated_at = NOW() WHERE id = ?")
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	defer updateTier.Close()
+
+	for i := range amounts {
+		amounts[i].NewAmount = money.Round(amounts[i].Amount.Mul(rate), money.AmountScale)
+
+		stmt := updatePrice
+		if amounts[i].Source == RepricedSourceTier {
+			stmt = updateTier
+		}
+		if _, err := stmt.ExecContext(ctx, amounts[i].NewAmount, amounts[i].ID); err != nil {
+			return errors.NewMysqlError(err)
+		}
+	}
 
 	return nil
 }
 
+func (r *MySQLRepository) ListBaseCurrencyAmounts(ctx context.Context, baseCurrencyID int64) ([]RepricedAmount, error) {
+	return listBaseCurrencyAmounts(ctx, r.db, baseCurrencyID, "")
+}
+
+func (r *MySQLRepository) CountBaseCurrencyPriceLists(ctx context.Context, baseCurrencyID int64) (int64, error) {
+	var count int64
+	err := r.db.QueryRowContext(ctx, `
+			SELECT COUNT(*) FROM price_lists
+			WHERE deleted_at IS NULL AND (id_currency IS NULL OR id_currency = ?)
+	`, baseCurrencyID).Scan(&count)
+	if err != nil {
+		return 0, errors.NewMysqlError(err)
+	}
+	return count, nil
+}
+
+// listBaseCurrencyAmounts devuelve los precios y tramos de las listas en la moneda base
+// (id_currency NULL o igual a la base)
+func listBaseCurrencyAmounts(ctx context.Context, q querier, baseCurrencyID int64, lock string) ([]RepricedAmount, error) {
+	sources := []struct {
+		source string
+		query  string
+	}{
+		{RepricedSourcePrice, `
+			SELECT p.id, p.id_price_list, p.amount
+			FROM prices p
+			JOIN price_lists pl ON pl.id = p.id_price_list
+			WHERE p.deleted_at IS NULL AND pl.deleted_at IS NULL
+			AND (pl.id_currency IS NULL OR pl.id_currency = ?)
+			ORDER BY p.id_price_list, p.id
+		`},
+		{RepricedSourceTier, `
+			SELECT t.id, t.id_price_list, t.amount
+			FROM price_tiers t
+			JOIN price_lists pl ON pl.id = t.id_price_list
+			WHERE t.deleted_at IS NULL AND pl.deleted_at IS NULL
+			AND (pl.id_currency IS NULL OR pl.id_currency = ?)
+			ORDER BY t.id_price_list, t.id
+		`},
+	}
+
+	var amounts []RepricedAmount
+	for _, src := range sources {
+		rows, err := q.QueryContext(ctx, src.query+lock, baseCurrencyID)
+		if err != nil {
+			return nil, errors.NewMysqlError(err)
+		}
+
+		for rows.Next() {
+			a := RepricedAmount{Source: src.source}
+			if err := rows.Scan(&a.ID, &a.IDPriceList, &a.Amount); err != nil {
+				rows.Close()
+				return nil, errors.NewMysqlError(err)
+			}
+			a.NewAmount = a.Amount
+			amounts = append(amounts, a)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, errors.NewMysqlError(err)
+		}
+	}
+
+	return amounts, nil
+}
+
 func (r *MySQLRepository) DeleteCurrency(ctx context.Context, id int64) error {
 	// Verificar si es moneda base
 	var isBase bool
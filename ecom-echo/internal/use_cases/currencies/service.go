@@ -4,15 +4,27 @@ package currency
 import (
 	"context"
 	"ecom/internal/shared/errors"
+	"ecom/internal/shared/money"
+	"fmt"
+	"log"
+	"strings"
 	"time"
 )
 
+const (
+	BaseSwitchConvert = "convert" // Convierte los importes con el último tipo de cambio
+	BaseSwitchRelabel = "relabel" // Mantiene los importes y los etiqueta con la nueva moneda
+)
+
 type Service struct {
-	repo Repository
+	repo      Repository
+	provider  ExchangeRateProvider
+	maxChange money.Decimal // Variación máxima aceptada (%) al actualizar desde el proveedor
 }
 
-func NewService(repo Repository) *Service {
-	return &Service{repo: repo}
+// NewService crea el servicio; provider puede ser nil si los tipos de cambio sólo se cargan a mano
+func NewService(repo Repository, provider ExchangeRateProvider, maxChange money.Decimal) *Service {
+	return &Service{repo: repo, provider: provider, maxChange: maxChange}
 }
 
 // Currency operations
@@ -43,24 +55,37 @@ func (s *Service) CreateCurrency(ctx context.Context, req *CreateCurrencyRequest
 			Symbol:    req.Symbol,
 			IsBase:    req.IsBase,
 			Active:    req.Active,
+			DecimalPlaces:      int32(money.AmountScale),
+			RoundingMode:       req.RoundingMode,
+			RoundingStep:       req.RoundingStep,
+			CharmEnding:        req.CharmEnding,
+			SymbolPosition:     SymbolBefore,
+			DecimalSeparator:   ".",
+			ThousandsSeparator: ",",
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
 	}
+	if req.DecimalPlaces != nil {
+			currency.DecimalPlaces = *req.DecimalPlaces
+	}
+	if req.SymbolPosition != "" {
+			currency.SymbolPosition = req.SymbolPosition
+	}
+	if req.DecimalSeparator != "" {
+			currency.DecimalSeparator = req.DecimalSeparator
+	}
+	if req.ThousandsSeparator != nil {
+			currency.ThousandsSeparator = *req.ThousandsSeparator
+	}
+	if currency.DecimalSeparator == currency.ThousandsSeparator {
+			return nil, errors.NewBadRequestError("El separador decimal y el de miles deben ser distintos")
+	}
 
 	if err := s.repo.CreateCurrency(ctx, currency); err != nil {
 			return nil, err
 	}
 
-	return &CurrencyResponse{
-			ID:        currency.ID,
-			Name:      currency.Name,
-			Code:      currency.Code,
-			Symbol:    currency.Symbol,
-			IsBase:    currency.IsBase,
-			Active:    currency.Active,
-			CreatedAt: currency.CreatedAt,
-			UpdatedAt: currency.UpdatedAt,
-	}, nil
+	return newCurrencyResponse(currency), nil
 }
 
 func (s *Service) UpdateCurrency(ctx context.Context, id int64, req *UpdateCurrencyRequest) (*CurrencyResponse, error) {
@@ -69,6 +94,23 @@ func (s *Service) UpdateCurrency(ctx context.Context, id int64, req *UpdateCurre
 			return nil, err
 	}
 
+	// El cambio de moneda base reprecia las listas en su propia transacción; si se mezclara con otros
+	// campos, un fallo al guardarlos dejaría las listas convertidas y la petición a medias
+	if req.IsBase != nil && *req.IsBase && !currency.IsBase {
+			if req.changesOtherFields() {
+					return nil, errors.NewBadRequestError(
+							"El cambio de moneda base no puede combinarse con otros campos; use POST /currencies/:id/base-switch")
+			}
+			mode := req.BaseSwitchMode
+			if mode == "" {
+					mode = BaseSwitchRelabel
+			}
+			if _, err := s.SwitchBaseCurrency(ctx, id, &BaseSwitchRequest{Mode: mode}); err != nil {
+					return nil, err
+			}
+			return s.GetCurrency(ctx, id)
+	}
+
 	if req.Name != nil {
 			currency.Name = *req.Name
 	}
@@ -81,17 +123,41 @@ func (s *Service) UpdateCurrency(ctx context.Context, id int64, req *UpdateCurre
 	if req.Active != nil {
 			currency.Active = *req.Active
 	}
+	if req.DecimalPlaces != nil {
+			currency.DecimalPlaces = *req.DecimalPlaces
+	}
+	if req.RoundingMode != nil {
+			currency.RoundingMode = *req.RoundingMode
+	}
+	if req.RoundingStep != nil {
+			currency.RoundingStep = req.RoundingStep
+			if req.RoundingStep.IsZero() {
+					currency.RoundingStep = nil
+			}
+	}
+	if req.CharmEnding != nil {
+			currency.CharmEnding = req.CharmEnding
+			if req.CharmEnding.IsZero() {
+					currency.CharmEnding = nil
+			}
+	}
+	if req.SymbolPosition != nil {
+			currency.SymbolPosition = *req.SymbolPosition
+	}
+	if req.DecimalSeparator != nil {
+			currency.DecimalSeparator = *req.DecimalSeparator
+	}
+	if req.ThousandsSeparator != nil {
+			currency.ThousandsSeparator = *req.ThousandsSeparator
+	}
+	if currency.DecimalSeparator == currency.ThousandsSeparator {
+			return nil, errors.NewBadRequestError("El separador decimal y el de miles deben ser distintos")
+	}
 	if req.IsBase != nil {
 			// Si se está cambiando a moneda base
 			if !*req.IsBase && currency.IsBase {				
 				return nil, errors.NewMessageError("Tiene que haber al menos una moneda base")
 			}
-			if *req.IsBase && !currency.IsBase {
-				if err := s.repo.SetBaseCurrency(ctx, id); err != nil {
-					return nil, err
-				}
-			}
-			currency.IsBase = *req.IsBase
 	}
 
 	currency.UpdatedAt = time.Now()
@@ -99,16 +165,7 @@ func (s *Service) UpdateCurrency(ctx context.Context, id int64, req *UpdateCurre
 			return nil, err
 	}
 
-	return &CurrencyResponse{
-			ID:        currency.ID,
-			Name:      currency.Name,
-			Code:      currency.Code,
-			Symbol:    currency.Symbol,
-			IsBase:    currency.IsBase,
-			Active:    currency.Active,
-			CreatedAt: currency.CreatedAt,
-			UpdatedAt: currency.UpdatedAt,
-	}, nil
+	return newCurrencyResponse(currency), nil
 }
 
 func (s *Service) DeleteCurrency(ctx context.Context, id int64) error {
@@ -121,16 +178,16 @@ func (s *Service) GetCurrency(ctx context.Context, id int64) (*CurrencyResponse,
 			return nil, err
 	}
 
-	return &CurrencyResponse{
-			ID:        currency.ID,
-			Name:      currency.Name,
-			Code:      currency.Code,
-			Symbol:    currency.Symbol,
-			IsBase:    currency.IsBase,
-			Active:    currency.Active,
-			CreatedAt: currency.CreatedAt,
-			UpdatedAt: currency.UpdatedAt,
-	}, nil
+	return newCurrencyResponse(currency), nil
+}
+
+func (s *Service) GetCurrencyByCode(ctx context.Context, code string) (*CurrencyResponse, error) {
+	currency, err := s.repo.GetCurrencyByCode(ctx, strings.ToUpper(code))
+	if err != nil {
+			return nil, err
+	}
+
+	return newCurrencyResponse(currency), nil
 }
 
 func (s *Service) ListCurrencies(ctx context.Context, p *Pagination) ([]CurrencyResponse, int64, error) {
@@ -141,16 +198,7 @@ func (s *Service) ListCurrencies(ctx context.Context, p *Pagination) ([]Currency
 
 	response := make([]CurrencyResponse, len(currencies))
 	for i, currency := range currencies {
-			response[i] = CurrencyResponse{
-					ID:        currency.ID,
-					Name:      currency.Name,
-					Code:      currency.Code,
-					Symbol:    currency.Symbol,
-					IsBase:    currency.IsBase,
-					Active:    currency.Active,
-					CreatedAt: currency.CreatedAt,
-					UpdatedAt: currency.UpdatedAt,
-			}
+			response[i] = *newCurrencyResponse(&currency)
 	}
 
 	return response, total, nil
@@ -177,7 +225,7 @@ func (s *Service) CreateExchangeRate(ctx context.Context, req *CreateExchangeRat
 	rate := &ExchangeRate{
 			FromCurrencyID: req.FromCurrencyID,
 			ToCurrencyID:   req.ToCurrencyID,
-			Rate:           req.Rate,
+			Rate:           money.Round(req.Rate, money.RateScale),
 			FromCurrency:   fromCurrency,
 			ToCurrency:     toCurrency,
 			CreatedAt:      time.Now(),
@@ -191,7 +239,7 @@ func (s *Service) CreateExchangeRate(ctx context.Context, req *CreateExchangeRat
 	inverseRate := &ExchangeRate{
 			FromCurrencyID: req.ToCurrencyID,
 			ToCurrencyID:   req.FromCurrencyID,
-			Rate:           1 / req.Rate,
+			Rate:           money.Round(money.NewFromInt(1).Div(req.Rate), money.RateScale),
 			FromCurrency:   toCurrency,
 			ToCurrency:     fromCurrency,
 			CreatedAt:      time.Now(),
@@ -211,54 +259,382 @@ func (s *Service) CreateExchangeRate(ctx context.Context, req *CreateExchangeRat
 }
 
 func (s *Service) ConvertAmount(ctx context.Context, req *ConvertAmountRequest) (*ConvertAmountResponse, error) {
+	at := time.Now()
+	if req.At != nil {
+			if req.At.After(at) {
+					return nil, errors.NewBadRequestError("La fecha de conversión no puede ser futura")
+			}
+			at = *req.At
+	}
+
+	return s.ConvertAmountAt(ctx, req.Amount, req.FromCurrencyCode, req.ToCurrencyCode, at)
+}
+
+// ConvertAmountAt convierte un importe con el tipo de cambio vigente en el instante indicado.
+// Si no hay tipo directo entre las monedas, se calcula un tipo cruzado a través de la moneda base.
+// Lo usan pedidos e informes para valorar importes con el tipo de la fecha de la operación
+func (s *Service) ConvertAmountAt(ctx context.Context, amount money.Decimal, fromCode, toCode string, at time.Time) (*ConvertAmountResponse, error) {
 	// Obtener monedas
-	fromCurrency, err := s.repo.GetCurrencyByCode(ctx, req.FromCurrencyCode)
+	fromCurrency, err := s.repo.GetCurrencyByCode(ctx, fromCode)
 	if err != nil {
 			return nil, err
 	}
-	toCurrency, err := s.repo.GetCurrencyByCode(ctx, req.ToCurrencyCode)
+	toCurrency, err := s.repo.GetCurrencyByCode(ctx, toCode)
 	if err != nil {
 			return nil, err
 	}
 
-	// Si son la misma moneda, retornar el mismo monto
-	if fromCurrency.ID == toCurrency.ID {
-			return &ConvertAmountResponse{
-					OriginalAmount:   req.Amount,
-					ConvertedAmount:  req.Amount,
-					FromCurrency:     *fromCurrency,
-					ToCurrency:       *toCurrency,
-					ExchangeRate:     1,
-					ConvertedAt:      time.Now(),
-			}, nil
-	}
-
-	// Obtener tipo de cambio
-	rate, err := s.repo.GetLatestExchangeRate(ctx, fromCurrency.ID, toCurrency.ID)
+	rate, err := s.rateAt(ctx, fromCurrency, toCurrency, at)
 	if err != nil {
 			return nil, err
 	}
 
 	return &ConvertAmountResponse{
-			OriginalAmount:   req.Amount,
-			ConvertedAmount:  req.Amount * rate.Rate,
+			OriginalAmount:   amount,
+			ConvertedAmount:  rate.convert(amount, fromCurrency, toCurrency),
 			FromCurrency:     *fromCurrency,
 			ToCurrency:       *toCurrency,
-			ExchangeRate:     rate.Rate,
-			ConvertedAt:      time.Now(),
+			ExchangeRate:     rate.display,
+			Via:              rate.via,
+			RateDate:         rate.date,
+			ConvertedAt:      at,
 	}, nil
 }
 
-func (s *Service) GetExchangeRates(ctx context.Context, currencyID int64) ([]ExchangeRate, error) {
-	return s.repo.ListExchangeRates(ctx, currencyID)
+// conversionRate tipo de cambio entre dos monedas en un instante, directo o cruzado
+type conversionRate struct {
+	identity bool           // Misma moneda: el importe no cambia
+	rate     money.Decimal  // Tipo sin redondear con el que se convierte
+	display  money.Decimal  // Tipo que se muestra al cliente
+	via      *Currency      // Moneda base si el tipo es cruzado
+	date     *time.Time
 }
 
-func (s *Service) GetBaseCurrency(ctx context.Context) (*CurrencyResponse, error) {
-	currency, err := s.repo.GetBaseCurrency(ctx)
+func (r *conversionRate) convert(amount money.Decimal, from, to *Currency) money.Decimal {
+	if r.identity {
+			return amount
+	}
+	return money.New(amount, from.Code).Convert(r.rate, to.Code).Amount
+}
+
+// rateAt busca el tipo vigente en at. Si no hay tipo directo entre las monedas, se calcula un
+// tipo cruzado a través de la moneda base
+func (s *Service) rateAt(ctx context.Context, fromCurrency, toCurrency *Currency, at time.Time) (*conversionRate, error) {
+	// Si son la misma moneda, el importe no cambia
+	if fromCurrency.ID == toCurrency.ID {
+			one := money.NewFromInt(1)
+			return &conversionRate{identity: true, rate: one, display: one}, nil
+	}
+
+	// Tipo de cambio directo vigente en ese momento
+	rate, err := s.repo.GetExchangeRateAt(ctx, fromCurrency.ID, toCurrency.ID, at)
+	if err == nil {
+			return &conversionRate{rate: rate.Rate, display: rate.Rate, date: &rate.CreatedAt}, nil
+	}
+	if !errors.IsNotFound(err) {
+			return nil, err
+	}
+
+	// Tipo cruzado a través de la moneda base
+	base, err := s.repo.GetBaseCurrency(ctx)
+	if err != nil {
+			return nil, err
+	}
+	if base.ID == fromCurrency.ID || base.ID == toCurrency.ID {
+			return nil, errors.NewNotFoundError("Tipo de cambio no encontrado")
+	}
+
+	toBase, err := s.repo.GetExchangeRateAt(ctx, fromCurrency.ID, base.ID, at)
+	if err != nil {
+			return nil, err
+	}
+	fromBase, err := s.repo.GetExchangeRateAt(ctx, base.ID, toCurrency.ID, at)
+	if err != nil {
+			return nil, err
+	}
+
+	rateDate := toBase.CreatedAt
+	if fromBase.CreatedAt.Before(rateDate) {
+			rateDate = fromBase.CreatedAt
+	}
+
+	// Se redondea una sola vez sobre el producto de ambos tipos para no acumular errores
+	crossRate := toBase.Rate.Mul(fromBase.Rate)
+	return &conversionRate{
+			rate:    crossRate,
+			display: money.Round(crossRate, money.RateScale),
+			via:     base,
+			date:    &rateDate,
+	}, nil
+}
+
+// PreviewBaseSwitch muestra qué precios cambiarían al pasar la moneda a base, sin aplicar nada
+func (s *Service) PreviewBaseSwitch(ctx context.Context, id int64, req *BaseSwitchRequest) (*BaseSwitchResponse, error) {
+	result, err := s.prepareBaseSwitch(ctx, id, req.Mode)
+	if err != nil {
+		return nil, err
+	}
+	if result.FromCurrency == nil {
+		return result, nil
+	}
+
+	amounts, err := s.repo.ListBaseCurrencyAmounts(ctx, result.FromCurrency.ID)
+	if err != nil {
+		return nil, err
+	}
+	if result.ExchangeRate != nil {
+		for i := range amounts {
+			amounts[i].NewAmount = money.Round(amounts[i].Amount.Mul(*result.ExchangeRate), money.AmountScale)
+		}
+	}
+
+	result.setRows(amounts)
+	return result, nil
+}
+
+// SwitchBaseCurrency establece la moneda base. Con convert, los importes de las listas en la
+// base anterior se convierten en la misma transacción; con relabel se conservan los importes
+func (s *Service) SwitchBaseCurrency(ctx context.Context, id int64, req *BaseSwitchRequest) (*BaseSwitchResponse, error) {
+	result, err := s.prepareBaseSwitch(ctx, id, req.Mode)
+	if err != nil {
+		return nil, err
+	}
+
+	amounts, err := s.repo.SetBaseCurrency(ctx, id, result.ExchangeRate)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Applied = true
+	result.ToCurrency.IsBase = true
+	result.setRows(amounts)
+	return result, nil
+}
+
+func (s *Service) prepareBaseSwitch(ctx context.Context, id int64, mode string) (*BaseSwitchResponse, error) {
+	newBase, err := s.repo.GetCurrencyByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if newBase.IsBase {
+		return nil, errors.NewBadRequestError("La moneda ya es la moneda base")
+	}
+
+	result := &BaseSwitchResponse{ToCurrency: newBase, Mode: mode, Rows: []RepricedAmount{}}
+
+	oldBase, err := s.repo.GetBaseCurrency(ctx)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return result, nil
+		}
+		return nil, err
+	}
+	result.FromCurrency = oldBase
+
+	if mode == BaseSwitchConvert {
+		rate, err := s.repo.GetLatestExchangeRate(ctx, oldBase.ID, newBase.ID)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return nil, errors.NewBadRequestError(fmt.Sprintf(
+					"No hay tipo de cambio de %s a %s para convertir los precios", oldBase.Code, newBase.Code))
+			}
+			return nil, err
+		}
+		result.ExchangeRate = &rate.Rate
+	}
+
+	result.PriceLists, err = s.repo.CountBaseCurrencyPriceLists(ctx, oldBase.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *BaseSwitchResponse) setRows(amounts []RepricedAmount) {
+	for _, a := range amounts {
+		if a.Source == RepricedSourceTier {
+			r.Tiers++
+		} else {
+			r.Prices++
+		}
+	}
+	if amounts != nil {
+		r.Rows = amounts
+	}
+}
+
+// HasRateProvider indica si hay un proveedor externo de tipos de cambio configurado
+func (s *Service) HasRateProvider() bool {
+	return s.provider != nil
+}
+
+// RefreshExchangeRates consulta el proveedor y guarda los tipos de cambio nuevos entre
+// todas las monedas activas que publica. Las variaciones por encima del umbral se descartan,
+// salvo las de los pares que req pide aceptar; req puede ser nil
+func (s *Service) RefreshExchangeRates(ctx context.Context, req *RefreshExchangeRatesRequest) (*RefreshExchangeRatesResponse, error) {
+	if s.provider == nil {
+		return nil, errors.NewBadRequestError("No hay un proveedor de tipos de cambio configurado")
+	}
+
+	snapshot, err := s.provider.FetchRates(ctx)
+	if err != nil {
+		return nil, errors.NewInternalError("Error al obtener los tipos de cambio del proveedor", err)
+	}
+
+	currencies, err := s.repo.ListActiveCurrencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RefreshExchangeRatesResponse{
+		Provider:  s.provider.Name(),
+		Base:      snapshot.Base,
+		RatesDate: snapshot.Date,
+		Skipped:   []SkippedExchangeRate{},
+	}
+
+	accept := make(map[string]bool)
+	if req != nil {
+			for _, pair := range req.Accept {
+					accept[strings.ToUpper(pair.FromCurrency)+"/"+strings.ToUpper(pair.ToCurrency)] = true
+			}
+	}
+
+	var available []Currency
+	for _, c := range currencies {
+		if _, ok := snapshot.Cross(c.Code, c.Code); ok {
+			available = append(available, c)
+		} else {
+			result.Missing = append(result.Missing, c.Code)
+		}
+	}
+
+	for i := range available {
+		for j := range available {
+			if i == j {
+				continue
+			}
+			from, to := available[i], available[j]
+			rate, _ := snapshot.Cross(from.Code, to.Code)
+
+			previous, err := s.repo.GetLatestExchangeRate(ctx, from.ID, to.ID)
+			if err != nil && !errors.IsNotFound(err) {
+				return nil, err
+			}
+
+			if previous != nil {
+				if previous.Rate.Equal(rate) {
+					result.Unchanged++
+					continue
+				}
+				change := money.Round(
+					rate.Sub(previous.Rate).Div(previous.Rate).Mul(money.Hundred).Abs(), money.PercentageScale)
+				if s.maxChange.Sign() > 0 && change.GreaterThan(s.maxChange) {
+					anomaly := SkippedExchangeRate{
+						FromCurrency:  from.Code,
+						ToCurrency:    to.Code,
+						Rate:          rate,
+						PreviousRate:  previous.Rate,
+						ChangePercent: change,
+					}
+					// Sin aceptarlo expresamente se sigue comparando con el último tipo guardado, así que
+					// un cambio real se descarta en cada actualización hasta que alguien lo acepte
+					if !accept[from.Code+"/"+to.Code] {
+						log.Printf("Tipo de cambio %s->%s descartado: %s (anterior %s, variación %s%%)",
+							from.Code, to.Code, rate, previous.Rate, change)
+						result.Skipped = append(result.Skipped, anomaly)
+						continue
+					}
+					log.Printf("Tipo de cambio %s->%s aceptado pese a la variación: %s (anterior %s, variación %s%%)",
+						from.Code, to.Code, rate, previous.Rate, change)
+					result.Accepted = append(result.Accepted, anomaly)
+				}
+			}
+
+			if err := s.repo.CreateExchangeRate(ctx, &ExchangeRate{
+				FromCurrencyID: from.ID,
+				ToCurrencyID:   to.ID,
+				Rate:           rate,
+			}); err != nil {
+				return nil, err
+			}
+			result.Created++
+		}
+	}
+
+	return result, nil
+}
+
+// Localize convierte un importe a la moneda pedida por el cliente con el tipo de cambio vigente,
+// aplica las reglas de redondeo de esa moneda y lo formatea para mostrarlo.
+// Si toCode está vacío o coincide con la moneda de origen sólo se formatea
+func (s *Service) Localize(ctx context.Context, amount money.Decimal, fromCode, toCode string) (*LocalizedPrice, error) {
+	l, err := s.NewLocalizer(ctx, fromCode, toCode)
+	if err != nil {
+			return nil, err
+	}
+	return l.Localize(amount), nil
+}
+
+// Localizer convierte importes entre dos monedas con el tipo de cambio obtenido una sola vez, para
+// localizar los precios de un listado sin consultar monedas y tipos por cada importe
+type Localizer struct {
+	source *Currency
+	target *Currency        // nil si no hay que convertir
+	rate   *conversionRate
+}
+
+// NewLocalizer prepara la conversión de fromCode a toCode con el tipo de cambio vigente.
+// Devuelve NotFound si no hay tipo de cambio entre las monedas
+func (s *Service) NewLocalizer(ctx context.Context, fromCode, toCode string) (*Localizer, error) {
+	source, err := s.repo.GetCurrencyByCode(ctx, fromCode)
+	if err != nil {
+			return nil, err
+	}
+	if toCode == "" || strings.EqualFold(toCode, fromCode) {
+			return &Localizer{source: source}, nil
+	}
+
+	target, err := s.repo.GetCurrencyByCode(ctx, strings.ToUpper(toCode))
+	if err != nil {
+			return nil, err
+	}
+	if !target.Active {
+			return nil, errors.NewBadRequestError(fmt.Sprintf("La moneda %s no está disponible", target.Code))
+	}
+
+	rate, err := s.rateAt(ctx, source, target, time.Now())
 	if err != nil {
 			return nil, err
 	}
+	return &Localizer{source: source, target: target, rate: rate}, nil
+}
+
+// Localize convierte y formatea un importe en la moneda de origen
+func (l *Localizer) Localize(amount money.Decimal) *LocalizedPrice {
+	if l.target == nil {
+			amount = money.RoundWith(amount, l.source.DecimalPlaces, l.source.roundingMode())
+			return newLocalizedPrice(l.source, amount, nil)
+	}
 
+	// Las reglas comerciales sólo se aplican a importes convertidos; los de la propia lista se respetan
+	converted := l.rate.convert(amount, l.source, l.target)
+	rate := l.rate.display
+	return newLocalizedPrice(l.target, l.target.ApplyPricing(converted), &rate)
+}
+
+func newLocalizedPrice(c *Currency, amount money.Decimal, rate *money.Decimal) *LocalizedPrice {
+	return &LocalizedPrice{
+			Amount:       amount,
+			CurrencyCode: c.Code,
+			Symbol:       c.Symbol,
+			Formatted:    c.Format(amount),
+			ExchangeRate: rate,
+	}
+}
+
+func newCurrencyResponse(currency *Currency) *CurrencyResponse {
 	return &CurrencyResponse{
 			ID:        currency.ID,
 			Name:      currency.Name,
@@ -266,7 +642,27 @@ func (s *Service) GetBaseCurrency(ctx context.Context) (*CurrencyResponse, error
 			Symbol:    currency.Symbol,
 			IsBase:    currency.IsBase,
 			Active:    currency.Active,
+			DecimalPlaces:      currency.DecimalPlaces,
+			RoundingMode:       currency.RoundingMode,
+			RoundingStep:       currency.RoundingStep,
+			CharmEnding:        currency.CharmEnding,
+			SymbolPosition:     currency.SymbolPosition,
+			DecimalSeparator:   currency.DecimalSeparator,
+			ThousandsSeparator: currency.ThousandsSeparator,
 			CreatedAt: currency.CreatedAt,
 			UpdatedAt: currency.UpdatedAt,
-	}, nil
+	}
+}
+
+func (s *Service) GetExchangeRates(ctx context.Context, currencyID int64) ([]ExchangeRate, error) {
+	return s.repo.ListExchangeRates(ctx, currencyID)
+}
+
+func (s *Service) GetBaseCurrency(ctx context.Context) (*CurrencyResponse, error) {
+	currency, err := s.repo.GetBaseCurrency(ctx)
+	if err != nil {
+			return nil, err
+	}
+
+	return newCurrencyResponse(currency), nil
 }
\ No newline at end of file
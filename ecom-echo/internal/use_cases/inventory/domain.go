@@ -0,0 +1,73 @@
+// domain.go
+package inventory
+
+import "time"
+
+// Warehouse almacén con stock propio. El predeterminado recibe el stock inicial de las variantes y
+// es el primero del que salen las ventas; después, los de mayor prioridad
+type Warehouse struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Code      string    `json:"code"`
+	Address   string    `json:"address,omitempty"`
+	Active    bool      `json:"active"`
+	IsDefault bool      `json:"is_default"`
+	Priority  int       `json:"priority"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// StockLevel existencias de una variante en un almacén
+type StockLevel struct {
+	IDWarehouse      int64     `json:"id_warehouse"`
+	WarehouseCode    string    `json:"warehouse_code"`
+	WarehouseName    string    `json:"warehouse_name"`
+	WarehouseActive  bool      `json:"warehouse_active"`
+	IDProductVariant int64     `json:"id_product_variant"`
+	SKU              string    `json:"sku"`
+	VariantName      string    `json:"variant_name"`
+	Quantity         int       `json:"quantity"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// VariantStock stock de la variante por almacén. OnHand es la suma de los almacenes activos y
+// Available lo que se puede vender: OnHand menos lo reservado por carritos
+type VariantStock struct {
+	IDProductVariant int64        `json:"id_product_variant"`
+	SKU              string       `json:"sku"`
+	Name             string       `json:"name"`
+	OnHand           int          `json:"on_hand"`
+	Reserved         int          `json:"reserved"`
+	Available        int          `json:"available"`
+	Levels           []StockLevel `json:"levels"`
+}
+
+// Movement apunte del libro de stock. Quantity lleva signo y BalanceAfter es el stock del almacén
+// tras el movimiento
+type Movement struct {
+	ID                     int64     `json:"id"`
+	IDWarehouse            int64     `json:"id_warehouse"`
+	WarehouseCode          string    `json:"warehouse_code"`
+	IDProductVariant       int64     `json:"id_product_variant"`
+	SKU                    string    `json:"sku"`
+	Type                   string    `json:"type"`
+	Quantity               int       `json:"quantity"`
+	BalanceAfter           int       `json:"balance_after"`
+	IDCounterpartWarehouse *int64    `json:"id_counterpart_warehouse,omitempty"` // Origen o destino de un traspaso
+	Reason                 string    `json:"reason"`
+	Actor                  string    `json:"actor"`
+	Reference              string    `json:"reference,omitempty"`
+	CreatedAt              time.Time `json:"created_at"`
+}
+
+// MovementFilter filtros del listado de movimientos
+type MovementFilter struct {
+	IDProductVariant *int64
+	IDWarehouse      *int64
+	Type             string
+	Reference        string
+	From             *time.Time
+	To               *time.Time
+	Page             int
+	PerPage          int
+}
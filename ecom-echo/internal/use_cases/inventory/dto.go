@@ -0,0 +1,56 @@
+// dto.go
+package inventory
+
+import "time"
+
+type CreateWarehouseRequest struct {
+	Name      string `json:"name" validate:"required,min=2,max=150"`
+	Code      string `json:"code" validate:"required,min=2,max=50"`
+	Address   string `json:"address" validate:"omitempty,max=255"`
+	Active    *bool  `json:"active"`
+	IsDefault bool   `json:"is_default"`
+	Priority  int    `json:"priority"`
+}
+
+type UpdateWarehouseRequest struct {
+	Name      *string `json:"name,omitempty" validate:"omitempty,min=2,max=150"`
+	Code      *string `json:"code,omitempty" validate:"omitempty,min=2,max=50"`
+	Address   *string `json:"address,omitempty" validate:"omitempty,max=255"`
+	Active    *bool   `json:"active,omitempty"`
+	IsDefault *bool   `json:"is_default,omitempty"`
+	Priority  *int    `json:"priority,omitempty"`
+}
+
+// CreateMovementRequest entrada, devolución o ajuste manual. Las recepciones y devoluciones son
+// siempre entradas; los ajustes llevan signo (negativo para mermas, roturas, recuentos a la baja...)
+type CreateMovementRequest struct {
+	IDWarehouse      int64  `json:"id_warehouse" validate:"required"`
+	IDProductVariant int64  `json:"id_product_variant" validate:"required"`
+	Type             string `json:"type" validate:"required,oneof=receipt return adjustment"`
+	Quantity         int    `json:"quantity" validate:"required"`
+	Reason           string `json:"reason" validate:"required,max=255"`
+	Actor            string `json:"actor" validate:"required,max=150"`
+	Reference        string `json:"reference" validate:"omitempty,max=100"`
+}
+
+// TransferRequest traspaso de unidades entre dos almacenes
+type TransferRequest struct {
+	IDFromWarehouse  int64  `json:"id_from_warehouse" validate:"required"`
+	IDToWarehouse    int64  `json:"id_to_warehouse" validate:"required,nefield=IDFromWarehouse"`
+	IDProductVariant int64  `json:"id_product_variant" validate:"required"`
+	Quantity         int    `json:"quantity" validate:"required,min=1"`
+	Reason           string `json:"reason" validate:"required,max=255"`
+	Actor            string `json:"actor" validate:"required,max=150"`
+	Reference        string `json:"reference" validate:"omitempty,max=100"`
+}
+
+type ListMovementsRequest struct {
+	Page             int        `query:"page" validate:"min=1"`
+	PerPage          int        `query:"per_page" validate:"min=1,max=100"`
+	IDProductVariant *int64     `query:"id_product_variant"`
+	IDWarehouse      *int64     `query:"id_warehouse"`
+	Type             string     `query:"type" validate:"omitempty,oneof=receipt sale return adjustment transfer"`
+	Reference        string     `query:"reference"`
+	From             *time.Time `query:"from"`
+	To               *time.Time `query:"to"`
+}
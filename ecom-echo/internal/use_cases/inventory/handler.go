@@ -0,0 +1,254 @@
+// handler.go
+package inventory
+
+import (
+	"ecom/internal/shared/errors"
+	"ecom/internal/shared/response"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(e *echo.Group, s *Service) {
+	h := &Handler{service: s}
+
+	// Almacenes
+	e.POST("/warehouses", h.CreateWarehouse)
+	e.GET("/warehouses", h.ListWarehouses)
+	e.GET("/warehouses/:id", h.GetWarehouse)
+	e.PUT("/warehouses/:id", h.UpdateWarehouse)
+	e.DELETE("/warehouses/:id", h.DeleteWarehouse)
+	e.GET("/warehouses/:id/stock", h.ListWarehouseLevels)
+
+	// Stock de variantes y libro de movimientos
+	e.GET("/variants/:id", h.GetVariantStock)
+	e.POST("/movements", h.CreateMovement)
+	e.GET("/movements", h.ListMovements)
+	e.GET("/movements/:id", h.GetMovement)
+	e.POST("/transfers", h.Transfer)
+}
+
+func (h *Handler) CreateWarehouse(c echo.Context) error {
+	var req CreateWarehouseRequest
+	if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error al procesar la solicitud", err.Error()))
+	}
+
+	if err := c.Validate(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error de validación", err.Error()))
+	}
+
+	warehouse, err := h.service.CreateWarehouse(c.Request().Context(), &req)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, 
+			response.Success("Almacén creado exitosamente", warehouse))
+}
+
+func (h *Handler) ListWarehouses(c echo.Context) error {
+	warehouses, err := h.service.ListWarehouses(c.Request().Context())
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Almacenes obtenidos exitosamente", warehouses))
+}
+
+func (h *Handler) GetWarehouse(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID inválido", err.Error()))
+	}
+
+	warehouse, err := h.service.GetWarehouse(c.Request().Context(), id)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Almacén obtenido exitosamente", warehouse))
+}
+
+func (h *Handler) UpdateWarehouse(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID inválido", err.Error()))
+	}
+
+	var req UpdateWarehouseRequest
+	if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error al procesar la solicitud", err.Error()))
+	}
+
+	if err := c.Validate(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error de validación", err.Error()))
+	}
+
+	warehouse, err := h.service.UpdateWarehouse(c.Request().Context(), id, &req)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Almacén actualizado exitosamente", warehouse))
+}
+
+func (h *Handler) DeleteWarehouse(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID inválido", err.Error()))
+	}
+
+	if err := h.service.DeleteWarehouse(c.Request().Context(), id); err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Almacén eliminado exitosamente", nil))
+}
+
+func (h *Handler) ListWarehouseLevels(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID inválido", err.Error()))
+	}
+
+	levels, err := h.service.ListWarehouseLevels(c.Request().Context(), id)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Stock del almacén obtenido exitosamente", levels))
+}
+
+func (h *Handler) GetVariantStock(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID inválido", err.Error()))
+	}
+
+	variantStock, err := h.service.GetVariantStock(c.Request().Context(), id)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Stock de la variante obtenido exitosamente", variantStock))
+}
+
+func (h *Handler) CreateMovement(c echo.Context) error {
+	var req CreateMovementRequest
+	if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error al procesar la solicitud", err.Error()))
+	}
+
+	if err := c.Validate(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error de validación", err.Error()))
+	}
+
+	movement, err := h.service.CreateMovement(c.Request().Context(), &req)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, 
+			response.Success("Movimiento registrado exitosamente", movement))
+}
+
+func (h *Handler) Transfer(c echo.Context) error {
+	var req TransferRequest
+	if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error al procesar la solicitud", err.Error()))
+	}
+
+	if err := c.Validate(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error de validación", err.Error()))
+	}
+
+	movements, err := h.service.Transfer(c.Request().Context(), &req)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, 
+			response.Success("Traspaso registrado exitosamente", movements))
+}
+
+func (h *Handler) GetMovement(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID inválido", err.Error()))
+	}
+
+	movement, err := h.service.GetMovement(c.Request().Context(), id)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Movimiento obtenido exitosamente", movement))
+}
+
+func (h *Handler) ListMovements(c echo.Context) error {
+	req := ListMovementsRequest{Page: 1, PerPage: 20}
+	if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error en paginación", err.Error()))
+	}
+
+	if err := c.Validate(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error de validación", err.Error()))
+	}
+
+	movements, total, err := h.service.ListMovements(c.Request().Context(), &req)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Movimientos obtenidos exitosamente", 
+					map[string]interface{}{
+							"items":    movements,
+							"total":    total,
+							"page":     req.Page,
+							"per_page": req.PerPage,
+					}))
+}
+
+func (h *Handler) handleError(c echo.Context, err error) error {
+	switch e := err.(type) {
+	case *errors.AppError:
+			return c.JSON(e.Code, response.Error(e.Message, nil))
+	default:
+			if errors.IsNotFound(err) {
+					return c.JSON(http.StatusNotFound, 
+							response.Error("Recurso no encontrado", nil))
+			}
+			return c.JSON(http.StatusInternalServerError, 
+					response.Error("Error interno del servidor", nil))
+	}
+}
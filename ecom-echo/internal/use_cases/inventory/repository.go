@@ -0,0 +1,438 @@
+// repository.go
+package inventory
+
+import (
+	"context"
+	"database/sql"
+	"ecom/internal/shared/errors"
+	"ecom/internal/shared/stock"
+)
+
+type Repository interface {
+	// Almacenes
+	CreateWarehouse(ctx context.Context, w *Warehouse) error
+	GetWarehouse(ctx context.Context, id int64) (*Warehouse, error)
+	ListWarehouses(ctx context.Context) ([]Warehouse, error)
+	UpdateWarehouse(ctx context.Context, w *Warehouse, activeChanged bool) error
+	DeleteWarehouse(ctx context.Context, id int64) error
+	WarehouseStock(ctx context.Context, warehouseID int64) (int, error)
+
+	// Stock y libro de movimientos
+	GetVariantStock(ctx context.Context, variantID int64) (*VariantStock, error)
+	ListWarehouseLevels(ctx context.Context, warehouseID int64) ([]StockLevel, error)
+	PostMovement(ctx context.Context, m stock.Movement) (int64, error)
+	Transfer(ctx context.Context, req *TransferRequest) ([]int64, error)
+	GetMovement(ctx context.Context, id int64) (*Movement, error)
+	ListMovements(ctx context.Context, f *MovementFilter) ([]Movement, int64, error)
+	VariantProductID(ctx context.Context, variantID int64) (int64, error)
+}
+
+type MySQLRepository struct {
+	db *sql.DB
+}
+
+func NewMySQLRepository(db *sql.DB) Repository {
+	return &MySQLRepository{db: db}
+}
+
+const (
+	querySelectWarehouse = `
+		SELECT id, name, code, COALESCE(address, ''), active, is_default, priority, created_at, updated_at
+		FROM warehouses
+	`
+
+	querySelectMovement = `
+		SELECT m.id, m.id_warehouse, w.code, m.id_product_variant, pv.sku, m.type, m.quantity, m.balance_after,
+			m.id_counterpart_warehouse, m.reason, m.actor, COALESCE(m.reference, ''), m.created_at
+		FROM stock_movements m
+		INNER JOIN warehouses w ON w.id = m.id_warehouse
+		INNER JOIN product_variants pv ON pv.id = m.id_product_variant
+	`
+
+	querySelectLevel = `
+		SELECT ws.id_warehouse, w.code, w.name, w.active, ws.id_product_variant, pv.sku, pv.name,
+			ws.quantity, ws.updated_at
+		FROM warehouse_stock ws
+		INNER JOIN warehouses w ON w.id = ws.id_warehouse AND w.deleted_at IS NULL
+		INNER JOIN product_variants pv ON pv.id = ws.id_product_variant AND pv.deleted_at IS NULL
+	`
+)
+
+// CreateWarehouse crea el almacén; si es el predeterminado, el anterior deja de serlo
+func (r *MySQLRepository) CreateWarehouse(ctx context.Context, w *Warehouse) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	defer tx.Rollback()
+
+	if w.IsDefault {
+		if err := clearDefault(ctx, tx); err != nil {
+			return err
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO warehouses (name, code, address, active, is_default, priority, created_at, updated_at)
+		VALUES (?, ?, NULLIF(?, ''), ?, ?, ?, NOW(), NOW())
+	`, w.Name, w.Code, w.Address, w.Active, w.IsDefault, w.Priority)
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return errors.NewInternalError("Error al obtener el id creado", err)
+	}
+	w.ID = id
+
+	if err := tx.Commit(); err != nil {
+		return errors.NewInternalError("Error al crear el almacén", err)
+	}
+	return nil
+}
+
+func (r *MySQLRepository) GetWarehouse(ctx context.Context, id int64) (*Warehouse, error) {
+	w, err := scanWarehouse(r.db.QueryRowContext(ctx, querySelectWarehouse+" WHERE id = ? AND deleted_at IS NULL", id))
+	if err == sql.ErrNoRows {
+		return nil, errors.NewNotFoundError("Almacén no encontrado")
+	}
+	if err != nil {
+		return nil, errors.NewMysqlError(err)
+	}
+	return w, nil
+}
+
+func (r *MySQLRepository) ListWarehouses(ctx context.Context) ([]Warehouse, error) {
+	rows, err := r.db.QueryContext(ctx,
+		querySelectWarehouse+" WHERE deleted_at IS NULL ORDER BY is_default DESC, priority DESC, name")
+	if err != nil {
+		return nil, errors.NewMysqlError(err)
+	}
+	defer rows.Close()
+
+	warehouses := []Warehouse{}
+	for rows.Next() {
+		w, err := scanWarehouse(rows)
+		if err != nil {
+			return nil, err
+		}
+		warehouses = append(warehouses, *w)
+	}
+	return warehouses, rows.Err()
+}
+
+// UpdateWarehouse guarda el almacén. Si cambia su estado, el stock vendible de sus variantes se recalcula
+func (r *MySQLRepository) UpdateWarehouse(ctx context.Context, w *Warehouse, activeChanged bool) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	defer tx.Rollback()
+
+	if w.IsDefault {
+		if err := clearDefault(ctx, tx); err != nil {
+			return err
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE warehouses
+		SET name = ?, code = ?, address = NULLIF(?, ''), active = ?, is_default = ?, priority = ?, updated_at = NOW()
+		WHERE id = ? AND deleted_at IS NULL
+	`, w.Name, w.Code, w.Address, w.Active, w.IsDefault, w.Priority, w.ID)
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewInternalError("Error al actualizar el almacén", err)
+	}
+	if rows == 0 {
+		return errors.NewNotFoundError("Almacén no encontrado")
+	}
+
+	if activeChanged {
+		if err := stock.RefreshWarehouseVariants(ctx, tx, w.ID); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.NewInternalError("Error al actualizar el almacén", err)
+	}
+	return nil
+}
+
+// DeleteWarehouse elimina el almacén (borrado lógico). El servicio comprueba antes que esté vacío
+func (r *MySQLRepository) DeleteWarehouse(ctx context.Context, id int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE warehouses SET deleted_at = NOW(), active = false, is_default = false
+		WHERE id = ? AND deleted_at IS NULL
+	`, id)
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewInternalError("Error al eliminar el almacén", err)
+	}
+	if rows == 0 {
+		return errors.NewNotFoundError("Almacén no encontrado")
+	}
+
+	if err := stock.RefreshWarehouseVariants(ctx, tx, id); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.NewInternalError("Error al eliminar el almacén", err)
+	}
+	return nil
+}
+
+// WarehouseStock devuelve el total de unidades del almacén
+func (r *MySQLRepository) WarehouseStock(ctx context.Context, warehouseID int64) (int, error) {
+	var total int
+	err := r.db.QueryRowContext(ctx,
+		"SELECT COALESCE(SUM(quantity), 0) FROM warehouse_stock WHERE id_warehouse = ?", warehouseID).Scan(&total)
+	if err != nil {
+		return 0, errors.NewMysqlError(err)
+	}
+	return total, nil
+}
+
+// GetVariantStock devuelve las existencias de la variante en cada almacén
+func (r *MySQLRepository) GetVariantStock(ctx context.Context, variantID int64) (*VariantStock, error) {
+	vs := &VariantStock{IDProductVariant: variantID}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT sku, name, COALESCE(stock, 0), reserved, GREATEST(COALESCE(stock, 0) - reserved, 0)
+		FROM product_variants
+		WHERE id = ? AND deleted_at IS NULL
+	`, variantID).Scan(&vs.SKU, &vs.Name, &vs.OnHand, &vs.Reserved, &vs.Available)
+	if err == sql.ErrNoRows {
+		return nil, errors.NewNotFoundError("Variante no encontrada")
+	}
+	if err != nil {
+		return nil, errors.NewMysqlError(err)
+	}
+
+	levels, err := r.listLevels(ctx, " WHERE ws.id_product_variant = ? ORDER BY w.is_default DESC, w.priority DESC, w.name", variantID)
+	if err != nil {
+		return nil, err
+	}
+	vs.Levels = levels
+	return vs, nil
+}
+
+// ListWarehouseLevels devuelve las variantes con existencias en el almacén
+func (r *MySQLRepository) ListWarehouseLevels(ctx context.Context, warehouseID int64) ([]StockLevel, error) {
+	return r.listLevels(ctx, " WHERE ws.id_warehouse = ? AND ws.quantity <> 0 ORDER BY pv.sku", warehouseID)
+}
+
+func (r *MySQLRepository) listLevels(ctx context.Context, where string, args ...interface{}) ([]StockLevel, error) {
+	rows, err := r.db.QueryContext(ctx, querySelectLevel+where, args...)
+	if err != nil {
+		return nil, errors.NewMysqlError(err)
+	}
+	defer rows.Close()
+
+	levels := []StockLevel{}
+	for rows.Next() {
+		var l StockLevel
+		if err := rows.Scan(
+			&l.IDWarehouse, &l.WarehouseCode, &l.WarehouseName, &l.WarehouseActive,
+			&l.IDProductVariant, &l.SKU, &l.VariantName, &l.Quantity, &l.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		levels = append(levels, l)
+	}
+	return levels, rows.Err()
+}
+
+// PostMovement registra un movimiento manual en su propia transacción
+func (r *MySQLRepository) PostMovement(ctx context.Context, m stock.Movement) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, errors.NewMysqlError(err)
+	}
+	defer tx.Rollback()
+
+	id, err := stock.Post(ctx, tx, m)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errors.NewInternalError("Error al registrar el movimiento", err)
+	}
+	return id, nil
+}
+
+// Transfer registra la salida del almacén de origen y la entrada en el de destino en la misma
+// transacción. Los almacenes se bloquean en orden de id para no interbloquear traspasos opuestos
+func (r *MySQLRepository) Transfer(ctx context.Context, req *TransferRequest) ([]int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.NewMysqlError(err)
+	}
+	defer tx.Rollback()
+
+	from, to := req.IDFromWarehouse, req.IDToWarehouse
+	out := stock.Movement{
+		IDWarehouse:            from,
+		IDProductVariant:       req.IDProductVariant,
+		Type:                   stock.MovementTransfer,
+		Quantity:               -req.Quantity,
+		IDCounterpartWarehouse: &to,
+		Reason:                 req.Reason,
+		Actor:                  req.Actor,
+		Reference:              req.Reference,
+	}
+	in := out
+	in.IDWarehouse = to
+	in.Quantity = req.Quantity
+	in.IDCounterpartWarehouse = &from
+
+	movements := []stock.Movement{out, in}
+	if to < from {
+		movements = []stock.Movement{in, out}
+	}
+
+	ids := make([]int64, 0, 2)
+	for _, m := range movements {
+		id, err := stock.Post(ctx, tx, m)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.NewInternalError("Error al registrar el traspaso", err)
+	}
+	return ids, nil
+}
+
+func (r *MySQLRepository) GetMovement(ctx context.Context, id int64) (*Movement, error) {
+	m, err := scanMovement(r.db.QueryRowContext(ctx, querySelectMovement+" WHERE m.id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, errors.NewNotFoundError("Movimiento no encontrado")
+	}
+	if err != nil {
+		return nil, errors.NewMysqlError(err)
+	}
+	return m, nil
+}
+
+// ListMovements devuelve el libro filtrado, los movimientos más recientes primero
+func (r *MySQLRepository) ListMovements(ctx context.Context, f *MovementFilter) ([]Movement, int64, error) {
+	where := " WHERE 1 = 1"
+	args := []interface{}{}
+
+	if f.IDProductVariant != nil {
+		where += " AND m.id_product_variant = ?"
+		args = append(args, *f.IDProductVariant)
+	}
+	if f.IDWarehouse != nil {
+		where += " AND m.id_warehouse = ?"
+		args = append(args, *f.IDWarehouse)
+	}
+	if f.Type != "" {
+		where += " AND m.type = ?"
+		args = append(args, f.Type)
+	}
+	if f.Reference != "" {
+		where += " AND m.reference = ?"
+		args = append(args, f.Reference)
+	}
+	if f.From != nil {
+		where += " AND m.created_at >= ?"
+		args = append(args, *f.From)
+	}
+	if f.To != nil {
+		where += " AND m.created_at < ?"
+		args = append(args, *f.To)
+	}
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM stock_movements m"+where, args...).Scan(&total); err != nil {
+		return nil, 0, errors.NewMysqlError(err)
+	}
+
+	query := querySelectMovement + where + " ORDER BY m.id DESC LIMIT ? OFFSET ?"
+	args = append(args, f.PerPage, (f.Page-1)*f.PerPage)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, errors.NewMysqlError(err)
+	}
+	defer rows.Close()
+
+	movements := []Movement{}
+	for rows.Next() {
+		m, err := scanMovement(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		movements = append(movements, *m)
+	}
+	return movements, total, rows.Err()
+}
+
+// VariantProductID devuelve el producto de la variante, para avisar de sus cambios de stock
+func (r *MySQLRepository) VariantProductID(ctx context.Context, variantID int64) (int64, error) {
+	var productID int64
+	err := r.db.QueryRowContext(ctx,
+		"SELECT id_product FROM product_variants WHERE id = ? AND deleted_at IS NULL", variantID).Scan(&productID)
+	if err == sql.ErrNoRows {
+		return 0, errors.NewNotFoundError("Variante no encontrada")
+	}
+	if err != nil {
+		return 0, errors.NewMysqlError(err)
+	}
+	return productID, nil
+}
+
+func clearDefault(ctx context.Context, tx *sql.Tx) error {
+	if _, err := tx.ExecContext(ctx, "UPDATE warehouses SET is_default = false WHERE is_default = true"); err != nil {
+		return errors.NewMysqlError(err)
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanWarehouse(row rowScanner) (*Warehouse, error) {
+	w := &Warehouse{}
+	err := row.Scan(&w.ID, &w.Name, &w.Code, &w.Address, &w.Active, &w.IsDefault, &w.Priority, &w.CreatedAt, &w.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func scanMovement(row rowScanner) (*Movement, error) {
+	m := &Movement{}
+	var counterpart sql.NullInt64
+	err := row.Scan(
+		&m.ID, &m.IDWarehouse, &m.WarehouseCode, &m.IDProductVariant, &m.SKU, &m.Type, &m.Quantity, &m.BalanceAfter,
+		&counterpart, &m.Reason, &m.Actor, &m.Reference, &m.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if counterpart.Valid {
+		m.IDCounterpartWarehouse = &counterpart.Int64
+	}
+	return m, nil
+}
@@ -0,0 +1,195 @@
+// service.go
+package inventory
+
+import (
+	"context"
+	"ecom/internal/shared/errors"
+	"ecom/internal/shared/stock"
+	"fmt"
+	"strings"
+
+	"ecom/internal/use_cases/product"
+)
+
+type Service struct {
+	repo     Repository
+	listener product.ChangeListener
+}
+
+// NewService crea el servicio; listener puede ser nil
+func NewService(repo Repository, listener product.ChangeListener) *Service {
+	return &Service{repo: repo, listener: listener}
+}
+
+func (s *Service) notifyVariantChanged(ctx context.Context, variantID int64) {
+	if s.listener == nil {
+		return
+	}
+	productID, err := s.repo.VariantProductID(ctx, variantID)
+	if err != nil {
+		return
+	}
+	s.listener.ProductChanged(ctx, productID)
+}
+
+func (s *Service) CreateWarehouse(ctx context.Context, req *CreateWarehouseRequest) (*Warehouse, error) {
+	w := &Warehouse{
+		Name:      req.Name,
+		Code:      strings.ToUpper(strings.TrimSpace(req.Code)),
+		Address:   req.Address,
+		Active:    true,
+		IsDefault: req.IsDefault,
+		Priority:  req.Priority,
+	}
+	if req.Active != nil {
+		w.Active = *req.Active
+	}
+	if w.IsDefault && !w.Active {
+		return nil, errors.NewBadRequestError("El almacén predeterminado debe estar activo")
+	}
+
+	if err := s.repo.CreateWarehouse(ctx, w); err != nil {
+		return nil, err
+	}
+	return s.repo.GetWarehouse(ctx, w.ID)
+}
+
+func (s *Service) GetWarehouse(ctx context.Context, id int64) (*Warehouse, error) {
+	return s.repo.GetWarehouse(ctx, id)
+}
+
+func (s *Service) ListWarehouses(ctx context.Context) ([]Warehouse, error) {
+	return s.repo.ListWarehouses(ctx)
+}
+
+// UpdateWarehouse actualiza el almacén. El predeterminado no se puede desactivar ni dejar de serlo
+// sin marcar otro: siempre tiene que haber uno donde entre el stock inicial
+func (s *Service) UpdateWarehouse(ctx context.Context, id int64, req *UpdateWarehouseRequest) (*Warehouse, error) {
+	w, err := s.repo.GetWarehouse(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	wasDefault, wasActive := w.IsDefault, w.Active
+
+	if req.Name != nil {
+		w.Name = *req.Name
+	}
+	if req.Code != nil {
+		w.Code = strings.ToUpper(strings.TrimSpace(*req.Code))
+	}
+	if req.Address != nil {
+		w.Address = *req.Address
+	}
+	if req.Active != nil {
+		w.Active = *req.Active
+	}
+	if req.IsDefault != nil {
+		w.IsDefault = *req.IsDefault
+	}
+	if req.Priority != nil {
+		w.Priority = *req.Priority
+	}
+
+	if wasDefault && !w.IsDefault {
+		return nil, errors.NewBadRequestError("Marca otro almacén como predeterminado en lugar de desmarcar éste")
+	}
+	if w.IsDefault && !w.Active {
+		return nil, errors.NewBadRequestError("El almacén predeterminado debe estar activo")
+	}
+
+	if err := s.repo.UpdateWarehouse(ctx, w, w.Active != wasActive); err != nil {
+		return nil, err
+	}
+	return s.repo.GetWarehouse(ctx, id)
+}
+
+// DeleteWarehouse elimina un almacén vacío que no sea el predeterminado
+func (s *Service) DeleteWarehouse(ctx context.Context, id int64) error {
+	w, err := s.repo.GetWarehouse(ctx, id)
+	if err != nil {
+		return err
+	}
+	if w.IsDefault {
+		return errors.NewBadRequestError("No se puede eliminar el almacén predeterminado")
+	}
+
+	total, err := s.repo.WarehouseStock(ctx, id)
+	if err != nil {
+		return err
+	}
+	if total > 0 {
+		return errors.NewConflictError(fmt.Sprintf("El almacén tiene %d unidades; traspásalas antes de eliminarlo", total))
+	}
+
+	return s.repo.DeleteWarehouse(ctx, id)
+}
+
+func (s *Service) GetVariantStock(ctx context.Context, variantID int64) (*VariantStock, error) {
+	return s.repo.GetVariantStock(ctx, variantID)
+}
+
+func (s *Service) ListWarehouseLevels(ctx context.Context, warehouseID int64) ([]StockLevel, error) {
+	if _, err := s.repo.GetWarehouse(ctx, warehouseID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListWarehouseLevels(ctx, warehouseID)
+}
+
+// CreateMovement registra una recepción, devolución o ajuste manual en el libro
+func (s *Service) CreateMovement(ctx context.Context, req *CreateMovementRequest) (*Movement, error) {
+	if req.Type != stock.MovementAdjustment && req.Quantity < 0 {
+		return nil, errors.NewBadRequestError("Las recepciones y devoluciones deben tener una cantidad positiva")
+	}
+
+	id, err := s.repo.PostMovement(ctx, stock.Movement{
+		IDWarehouse:      req.IDWarehouse,
+		IDProductVariant: req.IDProductVariant,
+		Type:             req.Type,
+		Quantity:         req.Quantity,
+		Reason:           req.Reason,
+		Actor:            req.Actor,
+		Reference:        req.Reference,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.notifyVariantChanged(ctx, req.IDProductVariant)
+
+	return s.repo.GetMovement(ctx, id)
+}
+
+// Transfer mueve unidades de un almacén a otro; devuelve la salida y la entrada
+func (s *Service) Transfer(ctx context.Context, req *TransferRequest) ([]Movement, error) {
+	ids, err := s.repo.Transfer(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyVariantChanged(ctx, req.IDProductVariant)
+
+	movements := make([]Movement, 0, len(ids))
+	for _, id := range ids {
+		m, err := s.repo.GetMovement(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		movements = append(movements, *m)
+	}
+	return movements, nil
+}
+
+func (s *Service) GetMovement(ctx context.Context, id int64) (*Movement, error) {
+	return s.repo.GetMovement(ctx, id)
+}
+
+func (s *Service) ListMovements(ctx context.Context, req *ListMovementsRequest) ([]Movement, int64, error) {
+	return s.repo.ListMovements(ctx, &MovementFilter{
+		IDProductVariant: req.IDProductVariant,
+		IDWarehouse:      req.IDWarehouse,
+		Type:             req.Type,
+		Reference:        req.Reference,
+		From:             req.From,
+		To:               req.To,
+		Page:             req.Page,
+		PerPage:          req.PerPage,
+	})
+}
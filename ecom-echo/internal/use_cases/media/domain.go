@@ -10,4 +10,10 @@ type Media struct {
 	Size        float64   `json:"size"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
-}
\ No newline at end of file
+}
+
+// References cuenta los registros que usan un archivo
+type References struct {
+	Variants   int64 `json:"variants"`
+	Categories int64 `json:"categories"`
+}
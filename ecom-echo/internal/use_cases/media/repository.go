@@ -18,6 +18,7 @@ type Repository interface {
 	Update(ctx context.Context, m *Media) error
 	Delete(ctx context.Context, id int64) error
 	List(ctx context.Context, p *Pagination) ([]Media, int64, error)
+	CountReferences(ctx context.Context, id int64) (*References, error)
 }
 
 // MySQLRepository implementation of Repository
@@ -61,6 +62,15 @@ const (
 		WHERE deleted_at IS NULL
 	`
 
+	queryCountMediaReferences = `
+		SELECT
+			(SELECT COUNT(*) FROM n_product_variant_media vm
+			 INNER JOIN product_variants v ON v.id = vm.id_product_variant
+			 WHERE vm.id_media = ? AND v.deleted_at IS NULL),
+			(SELECT COUNT(*) FROM categories
+			 WHERE id_media = ? AND deleted_at IS NULL)
+	`
+
 	queryCountMedia = `
 		SELECT COUNT(*) 
 		FROM media 
@@ -151,6 +161,15 @@ func (r *MySQLRepository) Delete(ctx context.Context, id int64) error {
 
 
 
+// CountReferences counts the live variants and categories that use a media record
+func (r *MySQLRepository) CountReferences(ctx context.Context, id int64) (*References, error) {
+	refs := &References{}
+	if err := r.db.QueryRowContext(ctx, queryCountMediaReferences, id, id).Scan(&refs.Variants, &refs.Categories); err != nil {
+		return nil, err
+	}
+	return refs, nil
+}
+
 // List retrieves media records with pagination and filtering
 func (r *MySQLRepository) List(ctx context.Context, p *Pagination) ([]Media, int64, error) {
 	// Base queries
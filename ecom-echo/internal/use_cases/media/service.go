@@ -171,6 +171,16 @@ func (s *Service) Delete(ctx context.Context, id int64) error {
 		return errors.NewInternalError("Error al buscar el archivo", err)
 	}
 
+	// No eliminar archivos que siguen en uso por variantes o categorías
+	refs, err := s.repo.CountReferences(ctx, id)
+	if err != nil {
+		return errors.NewInternalError("Error al comprobar el uso del archivo", err)
+	}
+	if refs.Variants > 0 || refs.Categories > 0 {
+		return errors.NewConflictError(fmt.Sprintf(
+			"El archivo está en uso por %d variantes y %d categorías", refs.Variants, refs.Categories))
+	}
+
 	// Eliminar registro de base de datos
 	if err := s.repo.Delete(ctx, id); err != nil {
 		return errors.NewInternalError("Error al eliminar el archivo", err)
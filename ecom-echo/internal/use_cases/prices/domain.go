@@ -2,6 +2,7 @@
 package prices
 
 import (
+	"ecom/internal/shared/money"
 	"time"
 )
 
@@ -12,6 +13,7 @@ type PriceList struct {
 	IsDefault   bool      `json:"is_default"`
 	Priority    int       `json:"priority"`
 	Status      bool      `json:"status"`
+	IDCurrency  *int64    `json:"id_currency"` // nil: importes en la moneda base
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
@@ -19,7 +21,7 @@ type PriceList struct {
 type Price struct {
 	ID           int64      `json:"id"`
 	IDPriceList  int64      `json:"id_price_list"`
-	Amount       float64    `json:"amount"`
+	Amount       money.Decimal    `json:"amount"`
 	StartsAt     time.Time  `json:"starts_at"`
 	EndsAt       *time.Time `json:"ends_at,omitempty"`
 	PriceList    *PriceList `json:"price_list,omitempty"`
@@ -27,11 +29,111 @@ type Price struct {
 	UpdatedAt    time.Time  `json:"updated_at"`
 }
 
+// Overlaps indica si los periodos de vigencia de dos precios coinciden en algún momento.
+// Un precio sin fecha de fin se considera vigente indefinidamente
+func (p *Price) Overlaps(other *Price) bool {
+	if p.EndsAt != nil && !other.StartsAt.Before(*p.EndsAt) {
+		return false
+	}
+	if other.EndsAt != nil && !p.StartsAt.Before(*other.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// IsEffectiveAt indica si el precio está vigente en el instante dado
+func (p *Price) IsEffectiveAt(t time.Time) bool {
+	if t.Before(p.StartsAt) {
+		return false
+	}
+	return p.EndsAt == nil || t.Before(*p.EndsAt)
+}
+
 type ProductVariantPrice struct {
 	IDProductVariant int64     `json:"id_product_variant"`
 	IDPrice         int64     `json:"id_price"`
 	IsActive        bool      `json:"is_active"`
 	Price           *Price    `json:"price,omitempty"`
+	Tiers           []PriceTier `json:"tiers,omitempty"`
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
 }
+
+// GroupPriceList representa la lista de precios asociada a un grupo de clientes
+type GroupPriceList struct {
+	IDCustomerGroup int64      `json:"id_customer_group"`
+	GroupName       string     `json:"group_name"`
+	PriceList       *PriceList `json:"price_list"`
+}
+
+// PriceTier es un tramo de precio por cantidad para una variante dentro de una lista
+type PriceTier struct {
+	ID               int64     `json:"id"`
+	IDPriceList      int64     `json:"id_price_list"`
+	IDProductVariant int64     `json:"id_product_variant"`
+	MinQuantity      int       `json:"min_quantity"`
+	MaxQuantity      *int      `json:"max_quantity"`
+	Amount           money.Decimal   `json:"amount"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// Matches indica si la cantidad cae dentro del tramo
+func (t *PriceTier) Matches(quantity int) bool {
+	if quantity < t.MinQuantity {
+		return false
+	}
+	return t.MaxQuantity == nil || quantity <= *t.MaxQuantity
+}
+
+// DefaultPriceSet precios vigentes de un grupo de variantes en la lista por defecto, con sus
+// tramos por volumen. Se carga de una vez para los listados
+type DefaultPriceSet struct {
+	SourceCode string // Moneda de la lista o, si no tiene, la base; vacío si no hay ninguna
+	prices     map[int64]money.Decimal
+	tiers      map[int64][]PriceTier
+}
+
+// Empty indica si ninguna variante del conjunto tiene precio vigente
+func (d *DefaultPriceSet) Empty() bool {
+	return len(d.prices) == 0
+}
+
+// UnitAmount importe unitario de la variante para la cantidad, en la moneda de origen.
+// Devuelve false si la variante no tiene precio vigente
+func (d *DefaultPriceSet) UnitAmount(variantID int64, quantity int) (money.Decimal, bool) {
+	amount, ok := d.prices[variantID]
+	if !ok {
+		return money.Zero, false
+	}
+	for i := range d.tiers[variantID] {
+		if d.tiers[variantID][i].Matches(quantity) {
+			return d.tiers[variantID][i].Amount, true
+		}
+	}
+	return amount, true
+}
+
+// PriceImportItem es una fila validada lista para aplicarse en la importación masiva.
+// Close son los precios vigentes de la variante que el archivo sustituye: terminan donde
+// empieza este precio
+type PriceImportItem struct {
+	IDProductVariant int64
+	Price            *Price
+	Close            []int64
+}
+
+// PriceExportRow es un precio vigente de una lista junto con la variante que lo usa
+type PriceExportRow struct {
+	IDProductVariant int64
+	SKU              string
+	VariantName      string
+	Price            *Price
+}
+
+// ClonedPrice es un precio copiado desde otra lista junto con las variantes a las que se asigna
+type ClonedPrice struct {
+	SourcePriceID int64
+	Price         *Price
+	VariantIDs    []int64
+}
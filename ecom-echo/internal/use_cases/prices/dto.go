@@ -1,7 +1,11 @@
 // dto.go
 package prices
 
-import "time"
+import (
+	"ecom/internal/shared/money"
+	currency "ecom/internal/use_cases/currencies"
+	"time"
+)
 
 type CreatePriceListRequest struct {
 	Name        string `json:"name" validate:"required,min=2"`
@@ -9,6 +13,7 @@ type CreatePriceListRequest struct {
 	IsDefault   bool   `json:"is_default"`
 	Priority    int    `json:"priority" validate:"required,min=0"`
 	Status      bool   `json:"status"`
+	IDCurrency  *int64 `json:"id_currency,omitempty"` // Si se omite, la lista está en la moneda base
 }
 
 type UpdatePriceListRequest struct {
@@ -17,17 +22,19 @@ type UpdatePriceListRequest struct {
 	IsDefault   *bool   `json:"is_default,omitempty"`
 	Priority    *int    `json:"priority,omitempty" validate:"omitempty,min=0"`
 	Status      *bool   `json:"status,omitempty"`
+	IDCurrency  *int64  `json:"id_currency,omitempty"`
 }
 
 type CreatePriceRequest struct {
-	IDPriceList int64      `json:"id_price_list" validate:"required"`
-	Amount      float64    `json:"amount" validate:"required,min=0"`
-	StartsAt    time.Time  `json:"starts_at" validate:"required"`
-	EndsAt      *time.Time `json:"ends_at,omitempty" validate:"omitempty,gtfield=StartsAt"`
+	IDPriceList      int64      `json:"id_price_list" validate:"required"`
+	Amount           money.Decimal    `json:"amount" validate:"required,min=0"`
+	StartsAt         time.Time  `json:"starts_at" validate:"required"`
+	EndsAt           *time.Time `json:"ends_at,omitempty" validate:"omitempty,gtfield=StartsAt"`
+	IDProductVariant *int64     `json:"id_product_variant,omitempty"` // Si se indica, el precio se asigna a la variante al crearse
 }
 
 type UpdatePriceRequest struct {
-	Amount   *float64    `json:"amount,omitempty" validate:"omitempty,min=0"`
+	Amount   *money.Decimal    `json:"amount,omitempty" validate:"omitempty,min=0"`
 	StartsAt *time.Time  `json:"starts_at,omitempty"`
 	EndsAt   *time.Time  `json:"ends_at,omitempty" validate:"omitempty,gtfield=StartsAt"`
 }
@@ -45,6 +52,7 @@ type PriceListResponse struct {
 	IsDefault   bool      `json:"is_default"`
 	Priority    int       `json:"priority"`
 	Status      bool      `json:"status"`
+	IDCurrency  *int64    `json:"id_currency"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
@@ -52,7 +60,7 @@ type PriceListResponse struct {
 type PriceResponse struct {
 	ID          int64            `json:"id"`
 	IDPriceList int64            `json:"id_price_list"`
-	Amount      float64          `json:"amount"`
+	Amount      money.Decimal          `json:"amount"`
 	StartsAt    time.Time        `json:"starts_at"`
 	EndsAt      *time.Time       `json:"ends_at,omitempty"`
 	PriceList   *PriceListResponse `json:"price_list,omitempty"`
@@ -60,6 +68,117 @@ type PriceResponse struct {
 	UpdatedAt   time.Time        `json:"updated_at"`
 }
 
+type PriceTierRequest struct {
+	MinQuantity int      `json:"min_quantity" validate:"required,min=1"`
+	MaxQuantity *int     `json:"max_quantity,omitempty" validate:"omitempty,min=1"`
+	Amount      money.Decimal  `json:"amount" validate:"min=0"`
+}
+
+type SetPriceTiersRequest struct {
+	IDProductVariant int64              `json:"id_product_variant" validate:"required"`
+	Tiers            []PriceTierRequest `json:"tiers" validate:"dive"`
+}
+
+type ResolvePriceRequest struct {
+	IDCustomer   *int64  `query:"customer_id"`
+	GroupIDs     []int64 `query:"group_ids"`
+	Quantity     int     `query:"quantity" validate:"min=1"`
+	CurrencyCode string  `query:"currency" validate:"omitempty,len=3"`
+}
+
+// PriceCandidate describe una lista evaluada durante la resolución del precio
+type PriceCandidate struct {
+	IDPriceList     int64    `json:"id_price_list"`
+	PriceListName   string   `json:"price_list_name"`
+	Priority        int      `json:"priority"`
+	Source          string   `json:"source"`
+	IDCustomerGroup *int64   `json:"id_customer_group,omitempty"`
+	GroupName       string   `json:"group_name,omitempty"`
+	IDPrice         *int64   `json:"id_price,omitempty"`
+	Amount          *money.Decimal `json:"amount,omitempty"`
+	Selected        bool     `json:"selected"`
+	Reason          string   `json:"reason"`
+}
+
+type ResolvedPriceResponse struct {
+	IDProductVariant int64            `json:"id_product_variant"`
+	Quantity         int              `json:"quantity"`
+	UnitAmount       money.Decimal          `json:"unit_amount"`
+	TotalAmount      money.Decimal          `json:"total_amount"`
+	CurrencyCode     string           `json:"currency_code,omitempty"`
+	ExchangeRate     *money.Decimal         `json:"exchange_rate,omitempty"`
+	Display          *currency.LocalizedPrice `json:"display,omitempty"` // Precio unitario formateado
+	Source           string           `json:"source"`
+	IDCustomerGroup  *int64           `json:"id_customer_group,omitempty"`
+	Price            *PriceResponse   `json:"price"`
+	Tier             *PriceTier       `json:"tier,omitempty"`
+	Explanation      string           `json:"explanation"`
+	Candidates       []PriceCandidate `json:"candidates"`
+}
+
+type PriceImportRow struct {
+	Row              int        `json:"row"`
+	SKU              string     `json:"sku"`
+	IDProductVariant int64      `json:"id_product_variant,omitempty"`
+	Amount           money.Decimal    `json:"amount"`
+	StartsAt         *time.Time `json:"starts_at,omitempty"`
+	EndsAt           *time.Time `json:"ends_at,omitempty"`
+	Errors           []string   `json:"errors,omitempty"`
+}
+
+type PriceImportReport struct {
+	IDPriceList int64            `json:"id_price_list"`
+	DryRun      bool             `json:"dry_run"`
+	TotalRows   int              `json:"total_rows"`
+	ValidRows   int              `json:"valid_rows"`
+	InvalidRows int              `json:"invalid_rows"`
+	Imported    int              `json:"imported"`
+	Rows        []PriceImportRow `json:"rows"`
+}
+
+type ClonePriceListRequest struct {
+	Name            string   `json:"name" validate:"required,min=2"`
+	Description     string   `json:"description"`
+	Priority        int      `json:"priority" validate:"min=0"`
+	Status          bool     `json:"status"`
+	AdjustmentType  string   `json:"adjustment_type" validate:"omitempty,oneof=percentage fixed"`
+	AdjustmentValue money.Decimal  `json:"adjustment_value"`
+	Rounding        string   `json:"rounding" validate:"omitempty,oneof=none nearest up down"`
+	RoundingStep    money.Decimal  `json:"rounding_step" validate:"omitempty,gt=0"`
+	IDCategory      *int64   `json:"id_category,omitempty"`
+	IDTag           *int64   `json:"id_tag,omitempty"`
+}
+
+type ClonePriceListResponse struct {
+	PriceList         *PriceListResponse `json:"price_list"`
+	SourcePriceListID int64              `json:"source_price_list_id"`
+	PricesCloned      int                `json:"prices_cloned"`
+	AssignmentsCloned int                `json:"assignments_cloned"`
+	TiersCloned       int                `json:"tiers_cloned"`
+}
+
+// PriceCalendarRequest rango del calendario. Sin from empieza ahora y sin to no tiene fin
+type PriceCalendarRequest struct {
+	From *time.Time `query:"from" validate:"omitempty"`
+	To   *time.Time `query:"to" validate:"omitempty,gtfield=From"`
+}
+
+// PriceCalendarEntry es un periodo en el que el precio efectivo de la variante no cambia.
+// Price es nil si en ese periodo la variante no tiene ningún precio vigente
+type PriceCalendarEntry struct {
+	StartsAt time.Time      `json:"starts_at"`
+	EndsAt   *time.Time     `json:"ends_at"`
+	Price    *PriceResponse `json:"price"`
+	Shadowed []int64        `json:"shadowed_price_ids,omitempty"` // Precios vigentes en el periodo que pierden por prioridad
+}
+
+type PriceCalendarResponse struct {
+	IDProductVariant int64                `json:"id_product_variant"`
+	From             time.Time            `json:"from"`
+	To               *time.Time           `json:"to"`
+	Entries          []PriceCalendarEntry `json:"entries"`
+}
+
 type Pagination struct {
 	Page     int    `query:"page" validate:"min=1"`
 	PerPage  int    `query:"per_page" validate:"min=1,max=100"`
@@ -2,10 +2,14 @@
 package prices
 
 import (
+	"bytes"
 	"ecom/internal/shared/errors"
 	"ecom/internal/shared/response"
+	"ecom/internal/shared/spreadsheet"
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
@@ -23,6 +27,7 @@ func NewHandler(e *echo.Group, s *Service) {
 	e.GET("/lists/:id", h.GetPriceList)
 	e.PUT("/lists/:id", h.UpdatePriceList)
 	e.DELETE("/lists/:id", h.DeletePriceList)
+	e.POST("/lists/:id/clone", h.ClonePriceList)
 
 	// Price routes
 	e.POST("/lists/:id/value", h.CreatePrice)
@@ -36,6 +41,17 @@ func NewHandler(e *echo.Group, s *Service) {
 	e.DELETE("/variants/:variant_id/prices/:price_id", h.UnassignPrice)
 	e.GET("/variant/:id/active-price", h.GetActivePrice)
 	e.GET("/variant/:id/prices", h.GetAllPrices)
+	e.GET("/variant/:id/resolve", h.ResolvePrice)
+	e.GET("/variant/:id/calendar", h.GetPriceCalendar)
+
+	// PriceTier routes (precios por volumen)
+	e.PUT("/lists/:id/tiers", h.SetPriceTiers)
+	e.GET("/lists/:id/tiers/:variant_id", h.GetPriceTiers)
+	e.DELETE("/lists/:id/tiers/:variant_id", h.DeletePriceTiers)
+
+	// Bulk routes
+	e.POST("/lists/:id/import", h.ImportPrices)
+	e.GET("/lists/:id/export", h.ExportPrices)
 }
 
 // PriceList handlers
@@ -140,6 +156,33 @@ func (h *Handler) ListPriceLists(c echo.Context) error {
 					}))
 }
 
+func (h *Handler) ClonePriceList(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID inválido", err.Error()))
+	}
+
+	var req ClonePriceListRequest
+	if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error al procesar la solicitud", err.Error()))
+	}
+
+	if err := c.Validate(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error de validación", err.Error()))
+	}
+
+	result, err := h.service.ClonePriceList(c.Request().Context(), id, &req)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, 
+			response.Success("Lista de precios clonada exitosamente", result))
+}
+
 // Price handlers
 func (h *Handler) CreatePrice(c echo.Context) error {
 	priceListID, err := strconv.ParseInt(c.Param("id"), 10, 64)
@@ -330,6 +373,199 @@ func (h *Handler) GetAllPrices(c echo.Context) error {
 			response.Success("Precios obtenidos exitosamente", prices))
 }
 
+func (h *Handler) GetPriceCalendar(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID inválido", err.Error()))
+	}
+
+	var req PriceCalendarRequest
+	if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error al procesar la solicitud", err.Error()))
+	}
+
+	// Sin from el calendario empieza ahora; se fija antes de validar que to sea posterior
+	if req.From == nil {
+			now := time.Now()
+			req.From = &now
+	}
+	if err := c.Validate(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error de validación", err.Error()))
+	}
+
+	calendar, err := h.service.GetPriceCalendar(c.Request().Context(), id, &req)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Calendario de precios obtenido exitosamente", calendar))
+}
+
+func (h *Handler) ResolvePrice(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID inválido", err.Error()))
+	}
+
+	req := ResolvePriceRequest{Quantity: 1}
+	if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error al procesar la solicitud", err.Error()))
+	}
+
+	if err := c.Validate(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error de validación", err.Error()))
+	}
+
+	price, err := h.service.ResolvePrice(c.Request().Context(), id, &req)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Precio resuelto exitosamente", price))
+}
+
+// PriceTier handlers
+func (h *Handler) SetPriceTiers(c echo.Context) error {
+	priceListID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID de lista de precios inválido", err.Error()))
+	}
+
+	var req SetPriceTiersRequest
+	if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error al procesar la solicitud", err.Error()))
+	}
+
+	if err := c.Validate(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error de validación", err.Error()))
+	}
+
+	tiers, err := h.service.SetPriceTiers(c.Request().Context(), priceListID, &req)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Tramos de precio guardados exitosamente", tiers))
+}
+
+func (h *Handler) GetPriceTiers(c echo.Context) error {
+	priceListID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID de lista de precios inválido", err.Error()))
+	}
+
+	variantID, err := strconv.ParseInt(c.Param("variant_id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID de variante inválido", err.Error()))
+	}
+
+	tiers, err := h.service.GetPriceTiers(c.Request().Context(), priceListID, variantID)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Tramos de precio obtenidos exitosamente", tiers))
+}
+
+func (h *Handler) DeletePriceTiers(c echo.Context) error {
+	priceListID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID de lista de precios inválido", err.Error()))
+	}
+
+	variantID, err := strconv.ParseInt(c.Param("variant_id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID de variante inválido", err.Error()))
+	}
+
+	if err := h.service.DeletePriceTiers(c.Request().Context(), priceListID, variantID); err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Tramos de precio eliminados exitosamente", nil))
+}
+
+// Bulk handlers
+func (h *Handler) ImportPrices(c echo.Context) error {
+	priceListID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID de lista de precios inválido", err.Error()))
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error al procesar el archivo", err.Error()))
+	}
+
+	dryRun, _ := strconv.ParseBool(c.FormValue("dry_run"))
+
+	src, err := file.Open()
+	if err != nil {
+			return h.handleError(c, err)
+	}
+	defer src.Close()
+
+	report, err := h.service.ImportPrices(c.Request().Context(), priceListID, file.Filename, src, dryRun)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	if report.InvalidRows > 0 && !dryRun {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("El archivo contiene filas inválidas, no se importó ningún precio", report))
+	}
+
+	message := "Precios importados exitosamente"
+	if dryRun {
+			message = "Validación del archivo completada"
+	}
+
+	return c.JSON(http.StatusOK, response.Success(message, report))
+}
+
+func (h *Handler) ExportPrices(c echo.Context) error {
+	priceListID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID de lista de precios inválido", err.Error()))
+	}
+
+	format := c.QueryParam("format")
+	if format == "" {
+			format = spreadsheet.FormatCSV
+	}
+
+	// Se genera en memoria para poder responder con JSON si falla
+	var buf bytes.Buffer
+	if err := h.service.ExportPrices(c.Request().Context(), priceListID, format, &buf); err != nil {
+			return h.handleError(c, err)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition,
+			fmt.Sprintf("attachment; filename=\"price-list-%d.%s\"", priceListID, format))
+	return c.Blob(http.StatusOK, spreadsheet.ContentType(format), buf.Bytes())
+}
+
 func (h *Handler) handleError(c echo.Context, err error) error {
 	switch e := err.(type) {
 	case *errors.AppError:
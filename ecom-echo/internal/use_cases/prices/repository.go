@@ -5,6 +5,9 @@ import (
 	"context"
 	"database/sql"
 	"ecom/internal/shared/errors"
+	"ecom/internal/shared/pricing"
+	"strings"
+	"time"
 )
 
 type Repository interface {
@@ -18,9 +21,9 @@ type Repository interface {
 	CountPriceLists(ctx context.Context) (int64, error)
 	
 	// Price operations
-	CreatePrice(ctx context.Context, p *Price) error
+	CreatePrice(ctx context.Context, p *Price, productVariantID *int64) error
 	GetPriceByID(ctx context.Context, id int64) (*Price, error)
-	UpdatePrice(ctx context.Context, p *Price) error
+	UpdatePrice(ctx context.Context, p *Price, checkOverlap bool) error
 	DeletePrice(ctx context.Context, id int64) error
 	ListPrices(ctx context.Context, priceListID int64, p *Pagination) ([]Price, int64, error)
 	
@@ -29,6 +32,27 @@ type Repository interface {
 	UnassignPrice(ctx context.Context, productVariantID, priceID int64) error
 	GetActivePrice(ctx context.Context, productVariantID int64) (*Price, error)
 	GetAllPrices(ctx context.Context, productVariantID int64) ([]ProductVariantPrice, error)
+
+	// Price resolution operations
+	GetActivePriceInList(ctx context.Context, productVariantID, priceListID int64) (*Price, error)
+	GetGroupPriceLists(ctx context.Context, customerID *int64, groupIDs []int64) ([]GroupPriceList, error)
+
+	// PriceTier operations
+	ReplacePriceTiers(ctx context.Context, priceListID, productVariantID int64, tiers []PriceTier) error
+	ListPriceTiers(ctx context.Context, priceListID, productVariantID int64) ([]PriceTier, error)
+	ListVariantPriceTiers(ctx context.Context, productVariantID int64) ([]PriceTier, error)
+	DeletePriceTiers(ctx context.Context, priceListID, productVariantID int64) error
+
+	// Bulk operations
+	GetVariantIDsBySKU(ctx context.Context, skus []string) (map[string]int64, error)
+	ListActivePricesForVariants(ctx context.Context, priceListID int64, productVariantIDs []int64) (map[int64][]Price, error)
+	ImportPrices(ctx context.Context, priceListID int64, items []PriceImportItem) error
+	ListCurrentPrices(ctx context.Context, priceListID int64) ([]PriceExportRow, error)
+
+	// Clone operations
+	ListActiveAssignments(ctx context.Context, priceListID int64, categoryID, tagID *int64) ([]ProductVariantPrice, error)
+	ListPriceTiersForVariants(ctx context.Context, priceListID int64, productVariantIDs []int64) ([]PriceTier, error)
+	ClonePriceList(ctx context.Context, pl *PriceList, prices []ClonedPrice, tiers []PriceTier) error
 }
 
 type MySQLRepository struct {
@@ -54,11 +78,11 @@ func (r *MySQLRepository) CreatePriceList(ctx context.Context, pl *PriceList) er
 	}
 
 	query := `
-			INSERT INTO price_lists (name, description, is_default, priority, status, created_at, updated_at)
-			VALUES (?, ?, ?, ?, ?, NOW(), NOW())
+			INSERT INTO price_lists (name, description, is_default, priority, status, id_currency, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, NOW(), NOW())
 	`
 	result, err := r.db.ExecContext(ctx, query,
-			pl.Name, pl.Description, pl.IsDefault, pl.Priority, pl.Status)
+			pl.Name, pl.Description, pl.IsDefault, pl.Priority, pl.Status, pl.IDCurrency)
 	if err != nil {
 			return errors.NewMysqlError(err)
 	}
@@ -74,14 +98,14 @@ func (r *MySQLRepository) CreatePriceList(ctx context.Context, pl *PriceList) er
 
 func (r *MySQLRepository) GetPriceListByID(ctx context.Context, id int64) (*PriceList, error) {
 	query := `
-			SELECT id, name, description, is_default, priority, status, created_at, updated_at
+			SELECT id, name, description, is_default, priority, status, id_currency, created_at, updated_at
 			FROM price_lists
 			WHERE id = ? AND deleted_at IS NULL
 	`
 	pl := &PriceList{}
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 			&pl.ID, &pl.Name, &pl.Description, &pl.IsDefault,
-			&pl.Priority, &pl.Status, &pl.CreatedAt, &pl.UpdatedAt,
+			&pl.Priority, &pl.Status, &pl.IDCurrency, &pl.CreatedAt, &pl.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
 			return nil, errors.NewNotFoundError("Lista de precios no encontrada")
@@ -113,11 +137,11 @@ func (r *MySQLRepository) UpdatePriceList(ctx context.Context, pl *PriceList) er
 
 	query := `
 			UPDATE price_lists 
-			SET name = ?, description = ?, is_default = ?, priority = ?, status = ?, updated_at = NOW()
+			SET name = ?, description = ?, is_default = ?, priority = ?, status = ?, id_currency = ?, updated_at = NOW()
 			WHERE id = ? AND deleted_at IS NULL
 	`
 	result, err := tx.ExecContext(ctx, query,
-			pl.Name, pl.Description, pl.IsDefault, pl.Priority, pl.Status, pl.ID)
+			pl.Name, pl.Description, pl.IsDefault, pl.Priority, pl.Status, pl.IDCurrency, pl.ID)
 	if err != nil {
 			tx.Rollback()
 			return errors.NewMysqlError(err)
@@ -206,7 +230,7 @@ func (r *MySQLRepository) ListPriceLists(ctx context.Context, p *Pagination) ([]
 	}
 
 	query := `
-			SELECT id, name, description, is_default, priority, status, created_at, updated_at
+			SELECT id, name, description, is_default, priority, status, id_currency, created_at, updated_at
 			FROM price_lists
 			WHERE deleted_at IS NULL
 	`
@@ -233,7 +257,7 @@ func (r *MySQLRepository) ListPriceLists(ctx context.Context, p *Pagination) ([]
 			var pl PriceList
 			err := rows.Scan(
 					&pl.ID, &pl.Name, &pl.Description, &pl.IsDefault,
-					&pl.Priority, &pl.Status, &pl.CreatedAt, &pl.UpdatedAt,
+					&pl.Priority, &pl.Status, &pl.IDCurrency, &pl.CreatedAt, &pl.UpdatedAt,
 			)
 			if err != nil {
 					return nil, 0, err
@@ -246,14 +270,14 @@ func (r *MySQLRepository) ListPriceLists(ctx context.Context, p *Pagination) ([]
 
 func (r *MySQLRepository) GetDefaultPriceList(ctx context.Context) (*PriceList, error) {
 	query := `
-			SELECT id, name, description, is_default, priority, status, created_at, updated_at
+			SELECT id, name, description, is_default, priority, status, id_currency, created_at, updated_at
 			FROM price_lists
 			WHERE is_default = true AND deleted_at IS NULL
 	`
 	pl := &PriceList{}
 	err := r.db.QueryRowContext(ctx, query).Scan(
 			&pl.ID, &pl.Name, &pl.Description, &pl.IsDefault,
-			&pl.Priority, &pl.Status, &pl.CreatedAt, &pl.UpdatedAt,
+			&pl.Priority, &pl.Status, &pl.IDCurrency, &pl.CreatedAt, &pl.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
 			return nil, errors.NewNotFoundError("Lista de precios por defecto no encontrada")
@@ -265,12 +289,27 @@ func (r *MySQLRepository) GetDefaultPriceList(ctx context.Context) (*PriceList,
 }
 
 // Price Methods
-func (r *MySQLRepository) CreatePrice(ctx context.Context, p *Price) error {
+
+// CreatePrice crea el precio y, si se indica la variante, se lo asigna activo en la misma transacción,
+// de modo que un solapamiento no deja el precio huérfano
+func (r *MySQLRepository) CreatePrice(ctx context.Context, p *Price, productVariantID *int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+			return errors.NewMysqlError(err)
+	}
+	defer tx.Rollback()
+
+	if productVariantID != nil {
+			if err := checkPriceOverlapTx(ctx, tx, *productVariantID, p); err != nil {
+					return err
+			}
+	}
+
 	query := `
 			INSERT INTO prices (id_price_list, amount, starts_at, ends_at, created_at, updated_at)
 			VALUES (?, ?, ?, ?, NOW(), NOW())
 	`
-	result, err := r.db.ExecContext(ctx, query,
+	result, err := tx.ExecContext(ctx, query,
 			p.IDPriceList, p.Amount, p.StartsAt, p.EndsAt)
 	if err != nil {
 			return errors.NewMysqlError(err)
@@ -281,6 +320,20 @@ func (r *MySQLRepository) CreatePrice(ctx context.Context, p *Price) error {
 			return errors.NewInternalError("Error al obtener el id creado", err)
 	}
 
+	if productVariantID != nil {
+			if _, err := tx.ExecContext(ctx, `
+					INSERT INTO n_product_variant_prices
+					(id_product_variant, id_price, is_active, created_at, updated_at)
+					VALUES (?, ?, true, NOW(), NOW())
+			`, *productVariantID, id); err != nil {
+					return errors.NewMysqlError(err)
+			}
+	}
+
+	if err := tx.Commit(); err != nil {
+			return errors.NewMysqlError(err)
+	}
+
 	p.ID = id
 	return nil
 }
@@ -288,7 +341,7 @@ func (r *MySQLRepository) CreatePrice(ctx context.Context, p *Price) error {
 func (r *MySQLRepository) GetPriceByID(ctx context.Context, id int64) (*Price, error) {
 	query := `
 			SELECT p.id, p.id_price_list, p.amount, p.starts_at, p.ends_at, p.created_at, p.updated_at,
-							pl.id, pl.name, pl.description, pl.is_default, pl.priority, pl.status,
+							pl.id, pl.name, pl.description, pl.is_default, pl.priority, pl.status, pl.id_currency,
 							pl.created_at, pl.updated_at
 			FROM prices p
 			JOIN price_lists pl ON pl.id = p.id_price_list
@@ -301,7 +354,7 @@ func (r *MySQLRepository) GetPriceByID(ctx context.Context, id int64) (*Price, e
 			&price.ID, &price.IDPriceList, &price.Amount, &price.StartsAt, &price.EndsAt,
 			&price.CreatedAt, &price.UpdatedAt,
 			&price.PriceList.ID, &price.PriceList.Name, &price.PriceList.Description,
-			&price.PriceList.IsDefault, &price.PriceList.Priority, &price.PriceList.Status,
+			&price.PriceList.IsDefault, &price.PriceList.Priority, &price.PriceList.Status, &price.PriceList.IDCurrency,
 			&price.PriceList.CreatedAt, &price.PriceList.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
@@ -313,13 +366,52 @@ func (r *MySQLRepository) GetPriceByID(ctx context.Context, id int64) (*Price, e
 	return &price, nil
 }
 
-func (r *MySQLRepository) UpdatePrice(ctx context.Context, p *Price) error {
+// UpdatePrice guarda el precio. Con checkOverlap comprueba antes, en la misma transacción y con las
+// variantes bloqueadas, que las nuevas fechas no lo solapen con otros precios de las variantes que lo usan
+func (r *MySQLRepository) UpdatePrice(ctx context.Context, p *Price, checkOverlap bool) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+			return errors.NewMysqlError(err)
+	}
+	defer tx.Rollback()
+
+	if checkOverlap {
+			variantIDs, err := assignedVariantIDs(ctx, tx, p.ID)
+			if err != nil {
+					return err
+			}
+			checked := make(map[int64]bool, len(variantIDs))
+			for _, variantID := range variantIDs {
+					if err := checkPriceOverlapTx(ctx, tx, variantID, p); err != nil {
+							return err
+					}
+					checked[variantID] = true
+			}
+
+			// Con el precio bloqueado ninguna asignación nueva puede comprobarse con las fechas anteriores;
+			// se revisan las que llegaron a confirmarse antes del bloqueo
+			if err := lockPrice(ctx, tx, p.ID, nil); err != nil {
+					return err
+			}
+			if variantIDs, err = assignedVariantIDs(ctx, tx, p.ID); err != nil {
+					return err
+			}
+			for _, variantID := range variantIDs {
+					if checked[variantID] {
+							continue
+					}
+					if err := checkPriceOverlapTx(ctx, tx, variantID, p); err != nil {
+							return err
+					}
+			}
+	}
+
 	query := `
 			UPDATE prices 
 			SET amount = ?, starts_at = ?, ends_at = ?, updated_at = NOW()
 			WHERE id = ? AND deleted_at IS NULL
 	`
-	result, err := r.db.ExecContext(ctx, query,
+	result, err := tx.ExecContext(ctx, query,
 			p.Amount, p.StartsAt, p.EndsAt, p.ID)
 	if err != nil {
 			return errors.NewMysqlError(err)
@@ -332,6 +424,28 @@ func (r *MySQLRepository) UpdatePrice(ctx context.Context, p *Price) error {
 	if rows == 0 {
 			return errors.NewNotFoundError("Precio no encontrado")
 	}
+
+	if err := tx.Commit(); err != nil {
+			return errors.NewMysqlError(err)
+	}
+	return nil
+}
+
+// lockPrice bloquea la fila del precio. Si se pasa price, se rellenan sus fechas con las guardadas
+func lockPrice(ctx context.Context, tx *sql.Tx, priceID int64, price *Price) error {
+	var startsAt time.Time
+	var endsAt *time.Time
+	err := tx.QueryRowContext(ctx,
+			"SELECT starts_at, ends_at FROM prices WHERE id = ? AND deleted_at IS NULL FOR UPDATE", priceID).Scan(&startsAt, &endsAt)
+	if err == sql.ErrNoRows {
+			return errors.NewNotFoundError("Precio no encontrado")
+	}
+	if err != nil {
+			return errors.NewMysqlError(err)
+	}
+	if price != nil {
+			price.StartsAt, price.EndsAt = startsAt, endsAt
+	}
 	return nil
 }
 
@@ -369,7 +483,7 @@ func (r *MySQLRepository) ListPrices(ctx context.Context, priceListID int64, p *
 
 	query := `
 			SELECT p.id, p.id_price_list, p.amount, p.starts_at, p.ends_at, p.created_at, p.updated_at,
-							pl.id, pl.name, pl.description, pl.is_default, pl.priority, pl.status,
+							pl.id, pl.name, pl.description, pl.is_default, pl.priority, pl.status, pl.id_currency,
 							pl.created_at, pl.updated_at
 			FROM prices p
 			JOIN price_lists pl ON pl.id = p.id_price_list
@@ -391,7 +505,7 @@ func (r *MySQLRepository) ListPrices(ctx context.Context, priceListID int64, p *
 					&price.ID, &price.IDPriceList, &price.Amount, &price.StartsAt, &price.EndsAt,
 					&price.CreatedAt, &price.UpdatedAt,
 					&price.PriceList.ID, &price.PriceList.Name, &price.PriceList.Description,
-					&price.PriceList.IsDefault, &price.PriceList.Priority, &price.PriceList.Status,
+					&price.PriceList.IsDefault, &price.PriceList.Priority, &price.PriceList.Status, &price.PriceList.IDCurrency,
 					&price.PriceList.CreatedAt, &price.PriceList.UpdatedAt,
 			)
 			if err != nil {
@@ -403,65 +517,71 @@ func (r *MySQLRepository) ListPrices(ctx context.Context, priceListID int64, p *
 	return prices, total, nil
 }
 
+// AssignPrice asigna el precio a la variante. Si la asignación es activa, pvp.Price debe traer el
+// precio: la comprobación de solapamientos y la inserción van en la misma transacción
 func (r *MySQLRepository) AssignPrice(ctx context.Context, pvp *ProductVariantPrice) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-			return err
-	}
-
-	// Verificar si ya existe algún precio activo para esta variante en la misma lista de precios
-	query := `
-			SELECT COUNT(*) 
-			FROM n_product_variant_prices pvp
-			JOIN prices p ON p.id = pvp.id_price
-			WHERE pvp.id_product_variant = ? AND p.id_price_list = (
-					SELECT id_price_list FROM prices WHERE id = ?
-			) AND pvp.is_active = true
-	`
-	var count int
-	err = tx.QueryRowContext(ctx, query, pvp.IDProductVariant, pvp.IDPrice).Scan(&count)
-	if err != nil {
-			tx.Rollback()
 			return errors.NewMysqlError(err)
 	}
+	defer tx.Rollback()
 
-	// Si ya existe un precio activo, desactivarlo
-	if count > 0 {
-			_, err = tx.ExecContext(ctx, `
-					UPDATE n_product_variant_prices pvp
-					JOIN prices p ON p.id = pvp.id_price
-					SET pvp.is_active = false
-					WHERE pvp.id_product_variant = ? AND p.id_price_list = (
-							SELECT id_price_list FROM prices WHERE id = ?
-					)
-			`, pvp.IDProductVariant, pvp.IDPrice)
+	if pvp.IsActive {
+			// Primero la variante y después el precio, releyendo sus fechas: una edición simultánea del
+			// precio espera a esta asignación o se comprueba después contra ella
+			periods, err := pricing.LockVariantPrices(ctx, tx, pvp.IDProductVariant, pvp.Price.IDPriceList)
 			if err != nil {
-					tx.Rollback()
-					return errors.NewMysqlError(err)
+					return err
+			}
+			if err := lockPrice(ctx, tx, pvp.IDPrice, pvp.Price); err != nil {
+					return err
+			}
+			if err := overlapError(pvp.IDProductVariant, pvp.Price, periodPrices(periods)); err != nil {
+					return err
 			}
 	}
 
-	// Insertar el nuevo precio
-	query = `
+	query := `
 			INSERT INTO n_product_variant_prices 
 			(id_product_variant, id_price, is_active, created_at, updated_at)
 			VALUES (?, ?, ?, NOW(), NOW())
 	`
 	result, err := tx.ExecContext(ctx, query, pvp.IDProductVariant, pvp.IDPrice, pvp.IsActive)
 	if err != nil {
-			tx.Rollback()
 			return errors.NewMysqlError(err)
 	}
 	rows, err := result.RowsAffected()
 	if err != nil {
-		tx.Rollback()
 		return errors.NewInternalError("Error al verificar la inserción del precio", err)
 	}
 	if rows == 0 {
-		tx.Rollback()
 		return errors.NewInternalError("No se pudo asignar el precio", nil)
 	}
-	return tx.Commit()
+
+	if err := tx.Commit(); err != nil {
+			return errors.NewMysqlError(err)
+	}
+	return nil
+}
+
+// checkPriceOverlapTx bloquea la variante y sus precios activos de la lista del precio y comprueba
+// que ninguno coincida en el tiempo con él. Con la variante bloqueada, dos asignaciones simultáneas
+// no pueden pasar ambas la comprobación
+func checkPriceOverlapTx(ctx context.Context, tx *sql.Tx, productVariantID int64, price *Price) error {
+	periods, err := pricing.LockVariantPrices(ctx, tx, productVariantID, price.IDPriceList)
+	if err != nil {
+			return err
+	}
+
+	return overlapError(productVariantID, price, periodPrices(periods))
+}
+
+func periodPrices(periods []pricing.Period) []Price {
+	existing := make([]Price, len(periods))
+	for i, p := range periods {
+			existing[i] = Price{ID: p.IDPrice, StartsAt: p.StartsAt, EndsAt: p.EndsAt}
+	}
+	return existing
 }
 
 func (r *MySQLRepository) UnassignPrice(ctx context.Context, productVariantID, priceID int64) error {
@@ -490,7 +610,7 @@ func (r *MySQLRepository) GetActivePrice(ctx context.Context, productVariantID i
 	// Obtener el precio activo con mayor prioridad y más reciente
 	query := `
 			SELECT p.id, p.id_price_list, p.amount, p.starts_at, p.ends_at, p.created_at, p.updated_at,
-							pl.id, pl.name, pl.description, pl.is_default, pl.priority, pl.status,
+							pl.id, pl.name, pl.description, pl.is_default, pl.priority, pl.status, pl.id_currency,
 							pl.created_at, pl.updated_at
 			FROM prices p
 			JOIN price_lists pl ON pl.id = p.id_price_list
@@ -510,7 +630,7 @@ func (r *MySQLRepository) GetActivePrice(ctx context.Context, productVariantID i
 			&price.ID, &price.IDPriceList, &price.Amount, &price.StartsAt, &price.EndsAt,
 			&price.CreatedAt, &price.UpdatedAt,
 			&price.PriceList.ID, &price.PriceList.Name, &price.PriceList.Description,
-			&price.PriceList.IsDefault, &price.PriceList.Priority, &price.PriceList.Status,
+			&price.PriceList.IsDefault, &price.PriceList.Priority, &price.PriceList.Status, &price.PriceList.IDCurrency,
 			&price.PriceList.CreatedAt, &price.PriceList.UpdatedAt,
 	)
 	
@@ -531,7 +651,7 @@ func (r *MySQLRepository) GetAllPrices(ctx context.Context, productVariantID int
 							p.id, p.id_price_list, p.amount, p.starts_at, p.ends_at, 
 							p.created_at, p.updated_at,
 							pl.id, pl.name, pl.description, pl.is_default, pl.priority, 
-							pl.status, pl.created_at, pl.updated_at
+							pl.status, pl.id_currency, pl.created_at, pl.updated_at
 			FROM n_product_variant_prices pvp
 			JOIN prices p ON p.id = pvp.id_price
 			JOIN price_lists pl ON pl.id = p.id_price_list
@@ -560,7 +680,7 @@ func (r *MySQLRepository) GetAllPrices(ctx context.Context, productVariantID int
 					&pvp.Price.CreatedAt, &pvp.Price.UpdatedAt,
 					&pvp.Price.PriceList.ID, &pvp.Price.PriceList.Name,
 					&pvp.Price.PriceList.Description, &pvp.Price.PriceList.IsDefault,
-					&pvp.Price.PriceList.Priority, &pvp.Price.PriceList.Status,
+					&pvp.Price.PriceList.Priority, &pvp.Price.PriceList.Status, &pvp.Price.PriceList.IDCurrency,
 					&pvp.Price.PriceList.CreatedAt, &pvp.Price.PriceList.UpdatedAt,
 			)
 			if err != nil {
@@ -572,6 +692,31 @@ func (r *MySQLRepository) GetAllPrices(ctx context.Context, productVariantID int
 	return prices, nil
 }
 
+// assignedVariantIDs devuelve, en orden de id, las variantes que tienen el precio asignado y activo
+func assignedVariantIDs(ctx context.Context, tx *sql.Tx, priceID int64) ([]int64, error) {
+	rows, err := tx.QueryContext(ctx, `
+			SELECT id_product_variant
+			FROM n_product_variant_prices
+			WHERE id_price = ? AND is_active = true
+			ORDER BY id_product_variant
+	`, priceID)
+	if err != nil {
+			return nil, errors.NewMysqlError(err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+					return nil, errors.NewMysqlError(err)
+			}
+			ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
 func (r *MySQLRepository) CountPriceLists(ctx context.Context) (int64, error) {
 	var count int64
 	err := r.db.QueryRowContext(ctx, 
@@ -581,4 +726,557 @@ func (r *MySQLRepository) CountPriceLists(ctx context.Context) (int64, error) {
 			return 0, errors.NewMysqlError(err)
 	}
 	return count, nil
-}
\ No newline at end of file
+}
+
+func (r *MySQLRepository) GetActivePriceInList(ctx context.Context, productVariantID, priceListID int64) (*Price, error) {
+	// Precio vigente más reciente de la variante dentro de una lista concreta
+	query := `
+			SELECT p.id, p.id_price_list, p.amount, p.starts_at, p.ends_at, p.created_at, p.updated_at,
+							pl.id, pl.name, pl.description, pl.is_default, pl.priority, pl.status, pl.id_currency,
+							pl.created_at, pl.updated_at
+			FROM prices p
+			JOIN price_lists pl ON pl.id = p.id_price_list
+			JOIN n_product_variant_prices pvp ON pvp.id_price = p.id
+			WHERE pvp.id_product_variant = ?
+			AND p.id_price_list = ?
+			AND pvp.is_active = true
+			AND p.deleted_at IS NULL
+			AND pl.deleted_at IS NULL
+			AND p.starts_at <= NOW()
+			AND (p.ends_at IS NULL OR p.ends_at > NOW())
+			ORDER BY p.created_at DESC
+			LIMIT 1
+	`
+
+	price := &Price{PriceList: &PriceList{}}
+	err := r.db.QueryRowContext(ctx, query, productVariantID, priceListID).Scan(
+			&price.ID, &price.IDPriceList, &price.Amount, &price.StartsAt, &price.EndsAt,
+			&price.CreatedAt, &price.UpdatedAt,
+			&price.PriceList.ID, &price.PriceList.Name, &price.PriceList.Description,
+			&price.PriceList.IsDefault, &price.PriceList.Priority, &price.PriceList.Status, &price.PriceList.IDCurrency,
+			&price.PriceList.CreatedAt, &price.PriceList.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("No hay precio activo para esta variante en la lista")
+	}
+	if err != nil {
+			return nil, errors.NewMysqlError(err)
+	}
+
+	return price, nil
+}
+
+func (r *MySQLRepository) GetGroupPriceLists(ctx context.Context, customerID *int64, groupIDs []int64) ([]GroupPriceList, error) {
+	// Listas de precios activas vinculadas a los grupos del cliente (o a los grupos indicados)
+	query := `
+			SELECT DISTINCT cg.id, cg.name,
+							pl.id, pl.name, pl.description, pl.is_default, pl.priority, pl.status, pl.id_currency,
+							pl.created_at, pl.updated_at
+			FROM customer_groups cg
+			JOIN price_lists pl ON pl.id = cg.id_price_list
+			LEFT JOIN n_customers_groups ncg ON ncg.id_customer_group = cg.id
+			WHERE cg.deleted_at IS NULL
+			AND pl.deleted_at IS NULL
+			AND pl.status = true
+	`
+	conditions := []string{}
+	args := []interface{}{}
+
+	if customerID != nil {
+			conditions = append(conditions, "ncg.id_customer = ?")
+			args = append(args, *customerID)
+	}
+
+	if len(groupIDs) > 0 {
+			placeholders := make([]string, len(groupIDs))
+			for i, id := range groupIDs {
+					placeholders[i] = "?"
+					args = append(args, id)
+			}
+			conditions = append(conditions, "cg.id IN ("+strings.Join(placeholders, ",")+")")
+	}
+
+	if len(conditions) == 0 {
+			return nil, nil
+	}
+
+	query += " AND (" + strings.Join(conditions, " OR ") + ")"
+	query += " ORDER BY pl.priority DESC, cg.id ASC"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+			return nil, errors.NewMysqlError(err)
+	}
+	defer rows.Close()
+
+	var lists []GroupPriceList
+	for rows.Next() {
+			gpl := GroupPriceList{PriceList: &PriceList{}}
+			var description sql.NullString
+			err := rows.Scan(
+					&gpl.IDCustomerGroup, &gpl.GroupName,
+					&gpl.PriceList.ID, &gpl.PriceList.Name, &description,
+					&gpl.PriceList.IsDefault, &gpl.PriceList.Priority, &gpl.PriceList.Status, &gpl.PriceList.IDCurrency,
+					&gpl.PriceList.CreatedAt, &gpl.PriceList.UpdatedAt,
+			)
+			if err != nil {
+					return nil, errors.NewMysqlError(err)
+			}
+			gpl.PriceList.Description = description.String
+			lists = append(lists, gpl)
+	}
+
+	return lists, rows.Err()
+}
+
+func (r *MySQLRepository) ReplacePriceTiers(ctx context.Context, priceListID, productVariantID int64, tiers []PriceTier) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+			return err
+	}
+
+	var variantID int64
+	err = tx.QueryRowContext(ctx,
+			"SELECT id FROM product_variants WHERE id = ? AND deleted_at IS NULL FOR UPDATE", productVariantID).Scan(&variantID)
+	if err == sql.ErrNoRows {
+			tx.Rollback()
+			return errors.NewNotFoundError("Variante de producto no encontrada")
+	}
+	if err != nil {
+			tx.Rollback()
+			return errors.NewMysqlError(err)
+	}
+
+	// Los tramos se reemplazan completos para poder validarlos como conjunto
+	_, err = tx.ExecContext(ctx, `
+			UPDATE price_tiers
+			SET deleted_at = NOW()
+			WHERE id_price_list = ? AND id_product_variant = ? AND deleted_at IS NULL
+	`, priceListID, productVariantID)
+	if err != nil {
+			tx.Rollback()
+			return errors.NewMysqlError(err)
+	}
+
+	query := `
+			INSERT INTO price_tiers
+			(id_price_list, id_product_variant, min_quantity, max_quantity, amount, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, NOW(), NOW())
+	`
+	for i := range tiers {
+			result, err := tx.ExecContext(ctx, query,
+					priceListID, productVariantID, tiers[i].MinQuantity, tiers[i].MaxQuantity, tiers[i].Amount)
+			if err != nil {
+					tx.Rollback()
+					return errors.NewMysqlError(err)
+			}
+
+			id, err := result.LastInsertId()
+			if err != nil {
+					tx.Rollback()
+					return errors.NewInternalError("Error al obtener el id creado", err)
+			}
+			tiers[i].ID = id
+			tiers[i].IDPriceList = priceListID
+			tiers[i].IDProductVariant = productVariantID
+	}
+
+	return tx.Commit()
+}
+
+func (r *MySQLRepository) ListPriceTiers(ctx context.Context, priceListID, productVariantID int64) ([]PriceTier, error) {
+	query := `
+			SELECT id, id_price_list, id_product_variant, min_quantity, max_quantity, amount,
+							created_at, updated_at
+			FROM price_tiers
+			WHERE id_price_list = ? AND id_product_variant = ? AND deleted_at IS NULL
+			ORDER BY min_quantity ASC
+	`
+	return r.queryPriceTiers(ctx, query, priceListID, productVariantID)
+}
+
+func (r *MySQLRepository) ListVariantPriceTiers(ctx context.Context, productVariantID int64) ([]PriceTier, error) {
+	query := `
+			SELECT t.id, t.id_price_list, t.id_product_variant, t.min_quantity, t.max_quantity, t.amount,
+							t.created_at, t.updated_at
+			FROM price_tiers t
+			JOIN price_lists pl ON pl.id = t.id_price_list
+			WHERE t.id_product_variant = ? AND t.deleted_at IS NULL AND pl.deleted_at IS NULL
+			ORDER BY t.id_price_list ASC, t.min_quantity ASC
+	`
+	return r.queryPriceTiers(ctx, query, productVariantID)
+}
+
+func (r *MySQLRepository) DeletePriceTiers(ctx context.Context, priceListID, productVariantID int64) error {
+	result, err := r.db.ExecContext(ctx, `
+			UPDATE price_tiers
+			SET deleted_at = NOW()
+			WHERE id_price_list = ? AND id_product_variant = ? AND deleted_at IS NULL
+	`, priceListID, productVariantID)
+	if err != nil {
+			return errors.NewMysqlError(err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+			return err
+	}
+	if rows == 0 {
+			return errors.NewNotFoundError("La variante no tiene tramos de precio en esta lista")
+	}
+	return nil
+}
+
+func (r *MySQLRepository) queryPriceTiers(ctx context.Context, query string, args ...interface{}) ([]PriceTier, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+			return nil, errors.NewMysqlError(err)
+	}
+	defer rows.Close()
+
+	var tiers []PriceTier
+	for rows.Next() {
+			var t PriceTier
+			var maxQuantity sql.NullInt64
+			err := rows.Scan(
+					&t.ID, &t.IDPriceList, &t.IDProductVariant, &t.MinQuantity, &maxQuantity,
+					&t.Amount, &t.CreatedAt, &t.UpdatedAt,
+			)
+			if err != nil {
+					return nil, errors.NewMysqlError(err)
+			}
+			if maxQuantity.Valid {
+					max := int(maxQuantity.Int64)
+					t.MaxQuantity = &max
+			}
+			tiers = append(tiers, t)
+	}
+
+	return tiers, rows.Err()
+}
+
+func (r *MySQLRepository) GetVariantIDsBySKU(ctx context.Context, skus []string) (map[string]int64, error) {
+	ids := make(map[string]int64, len(skus))
+	if len(skus) == 0 {
+			return ids, nil
+	}
+
+	placeholders := make([]string, len(skus))
+	args := make([]interface{}, len(skus))
+	for i, sku := range skus {
+			placeholders[i] = "?"
+			args[i] = sku
+	}
+
+	query := `
+			SELECT id, sku
+			FROM product_variants
+			WHERE deleted_at IS NULL AND sku IN (` + strings.Join(placeholders, ",") + `)
+	`
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+			return nil, errors.NewMysqlError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+			var id int64
+			var sku string
+			if err := rows.Scan(&id, &sku); err != nil {
+					return nil, errors.NewMysqlError(err)
+			}
+			ids[sku] = id
+	}
+
+	return ids, rows.Err()
+}
+
+// ImportPrices crea y asigna los precios de la importación en una transacción. Antes de cada
+// precio cierra los vigentes que sustituye y vuelve a comprobar los solapamientos con la variante
+// bloqueada, por si otra petición le asignó precios después de validar el archivo
+func (r *MySQLRepository) ImportPrices(ctx context.Context, priceListID int64, items []PriceImportItem) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+			return errors.NewMysqlError(err)
+	}
+	defer tx.Rollback()
+
+	insertPrice := `
+			INSERT INTO prices (id_price_list, amount, starts_at, ends_at, created_at, updated_at)
+			VALUES (?, ?, ?, ?, NOW(), NOW())
+	`
+	assign := `
+			INSERT INTO n_product_variant_prices 
+			(id_product_variant, id_price, is_active, created_at, updated_at)
+			VALUES (?, ?, true, NOW(), NOW())
+	`
+
+	for _, item := range items {
+			item.Price.IDPriceList = priceListID
+
+			for _, priceID := range item.Close {
+					if err := pricing.ClosePrice(ctx, tx, item.IDProductVariant, priceID, item.Price.StartsAt); err != nil {
+							return err
+					}
+			}
+			if err := checkPriceOverlapTx(ctx, tx, item.IDProductVariant, item.Price); err != nil {
+					return err
+			}
+
+			result, err := tx.ExecContext(ctx, insertPrice,
+					priceListID, item.Price.Amount, item.Price.StartsAt, item.Price.EndsAt)
+			if err != nil {
+					return errors.NewMysqlError(err)
+			}
+
+			priceID, err := result.LastInsertId()
+			if err != nil {
+					return errors.NewInternalError("Error al obtener el id creado", err)
+			}
+			item.Price.ID = priceID
+
+			if _, err := tx.ExecContext(ctx, assign, item.IDProductVariant, priceID); err != nil {
+					return errors.NewMysqlError(err)
+			}
+	}
+
+	if err := tx.Commit(); err != nil {
+			return errors.NewMysqlError(err)
+	}
+	return nil
+}
+
+// ListActivePricesForVariants devuelve, por variante, sus precios activos en la lista que no
+// han terminado: los vigentes y los programados
+func (r *MySQLRepository) ListActivePricesForVariants(ctx context.Context, priceListID int64, productVariantIDs []int64) (map[int64][]Price, error) {
+	prices := make(map[int64][]Price)
+	if len(productVariantIDs) == 0 {
+			return prices, nil
+	}
+
+	placeholders := make([]string, len(productVariantIDs))
+	args := []interface{}{priceListID}
+	for i, id := range productVariantIDs {
+			placeholders[i] = "?"
+			args = append(args, id)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+			SELECT pvp.id_product_variant, p.id, p.id_price_list, p.amount, p.starts_at, p.ends_at,
+							p.created_at, p.updated_at
+			FROM n_product_variant_prices pvp
+			JOIN prices p ON p.id = pvp.id_price
+			WHERE p.id_price_list = ?
+			AND pvp.id_product_variant IN (`+strings.Join(placeholders, ",")+`)
+			AND pvp.is_active = true
+			AND p.deleted_at IS NULL
+			AND (p.ends_at IS NULL OR p.ends_at > NOW())
+			ORDER BY p.starts_at
+	`, args...)
+	if err != nil {
+			return nil, errors.NewMysqlError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+			var variantID int64
+			var p Price
+			if err := rows.Scan(&variantID, &p.ID, &p.IDPriceList, &p.Amount, &p.StartsAt, &p.EndsAt,
+					&p.CreatedAt, &p.UpdatedAt); err != nil {
+					return nil, errors.NewMysqlError(err)
+			}
+			prices[variantID] = append(prices[variantID], p)
+	}
+	return prices, rows.Err()
+}
+
+func (r *MySQLRepository) ListCurrentPrices(ctx context.Context, priceListID int64) ([]PriceExportRow, error) {
+	query := `
+			SELECT v.id, v.sku, v.name,
+							p.id, p.id_price_list, p.amount, p.starts_at, p.ends_at, p.created_at, p.updated_at
+			FROM n_product_variant_prices pvp
+			JOIN prices p ON p.id = pvp.id_price
+			JOIN product_variants v ON v.id = pvp.id_product_variant
+			WHERE p.id_price_list = ?
+			AND pvp.is_active = true
+			AND p.deleted_at IS NULL
+			AND v.deleted_at IS NULL
+			AND (p.ends_at IS NULL OR p.ends_at > NOW())
+			ORDER BY v.sku ASC, p.starts_at ASC
+	`
+	rows, err := r.db.QueryContext(ctx, query, priceListID)
+	if err != nil {
+			return nil, errors.NewMysqlError(err)
+	}
+	defer rows.Close()
+
+	var result []PriceExportRow
+	for rows.Next() {
+			row := PriceExportRow{Price: &Price{}}
+			err := rows.Scan(
+					&row.IDProductVariant, &row.SKU, &row.VariantName,
+					&row.Price.ID, &row.Price.IDPriceList, &row.Price.Amount,
+					&row.Price.StartsAt, &row.Price.EndsAt, &row.Price.CreatedAt, &row.Price.UpdatedAt,
+			)
+			if err != nil {
+					return nil, errors.NewMysqlError(err)
+			}
+			result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *MySQLRepository) ListActiveAssignments(ctx context.Context, priceListID int64, categoryID, tagID *int64) ([]ProductVariantPrice, error) {
+	// Asignaciones activas con precio no expirado, opcionalmente acotadas a una categoría o tag
+	query := `
+			SELECT DISTINCT pvp.id_product_variant, pvp.id_price, pvp.is_active,
+							pvp.created_at, pvp.updated_at,
+							p.id, p.id_price_list, p.amount, p.starts_at, p.ends_at,
+							p.created_at, p.updated_at
+			FROM n_product_variant_prices pvp
+			JOIN prices p ON p.id = pvp.id_price
+			JOIN product_variants v ON v.id = pvp.id_product_variant
+			JOIN products pr ON pr.id = v.id_product
+	`
+	args := []interface{}{}
+
+	if tagID != nil {
+			query += " JOIN n_products_tags pt ON pt.id_product = pr.id AND pt.id_tag = ?"
+			args = append(args, *tagID)
+	}
+
+	query += `
+			WHERE p.id_price_list = ?
+			AND pvp.is_active = true
+			AND p.deleted_at IS NULL
+			AND v.deleted_at IS NULL
+			AND pr.deleted_at IS NULL
+			AND (p.ends_at IS NULL OR p.ends_at > NOW())
+	`
+	args = append(args, priceListID)
+
+	if categoryID != nil {
+			query += " AND pr.id_category = ?"
+			args = append(args, *categoryID)
+	}
+
+	query += " ORDER BY p.id ASC, pvp.id_product_variant ASC"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+			return nil, errors.NewMysqlError(err)
+	}
+	defer rows.Close()
+
+	var assignments []ProductVariantPrice
+	for rows.Next() {
+			pvp := ProductVariantPrice{Price: &Price{}}
+			err := rows.Scan(
+					&pvp.IDProductVariant, &pvp.IDPrice, &pvp.IsActive,
+					&pvp.CreatedAt, &pvp.UpdatedAt,
+					&pvp.Price.ID, &pvp.Price.IDPriceList, &pvp.Price.Amount,
+					&pvp.Price.StartsAt, &pvp.Price.EndsAt,
+					&pvp.Price.CreatedAt, &pvp.Price.UpdatedAt,
+			)
+			if err != nil {
+					return nil, errors.NewMysqlError(err)
+			}
+			assignments = append(assignments, pvp)
+	}
+
+	return assignments, rows.Err()
+}
+
+func (r *MySQLRepository) ListPriceTiersForVariants(ctx context.Context, priceListID int64, productVariantIDs []int64) ([]PriceTier, error) {
+	if len(productVariantIDs) == 0 {
+			return nil, nil
+	}
+
+	placeholders := make([]string, len(productVariantIDs))
+	args := []interface{}{priceListID}
+	for i, id := range productVariantIDs {
+			placeholders[i] = "?"
+			args = append(args, id)
+	}
+
+	query := `
+			SELECT id, id_price_list, id_product_variant, min_quantity, max_quantity, amount,
+							created_at, updated_at
+			FROM price_tiers
+			WHERE id_price_list = ? AND deleted_at IS NULL
+			AND id_product_variant IN (` + strings.Join(placeholders, ",") + `)
+			ORDER BY id_product_variant ASC, min_quantity ASC
+	`
+	return r.queryPriceTiers(ctx, query, args...)
+}
+
+func (r *MySQLRepository) ClonePriceList(ctx context.Context, pl *PriceList, prices []ClonedPrice, tiers []PriceTier) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+			return err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+			INSERT INTO price_lists (name, description, is_default, priority, status, id_currency, created_at, updated_at)
+			VALUES (?, ?, false, ?, ?, ?, NOW(), NOW())
+	`, pl.Name, pl.Description, pl.Priority, pl.Status, pl.IDCurrency)
+	if err != nil {
+			tx.Rollback()
+			return errors.NewMysqlError(err)
+	}
+
+	listID, err := result.LastInsertId()
+	if err != nil {
+			tx.Rollback()
+			return errors.NewInternalError("Error al obtener el id creado", err)
+	}
+	pl.ID = listID
+
+	for _, cp := range prices {
+			result, err := tx.ExecContext(ctx, `
+					INSERT INTO prices (id_price_list, amount, starts_at, ends_at, created_at, updated_at)
+					VALUES (?, ?, ?, ?, NOW(), NOW())
+			`, listID, cp.Price.Amount, cp.Price.StartsAt, cp.Price.EndsAt)
+			if err != nil {
+					tx.Rollback()
+					return errors.NewMysqlError(err)
+			}
+
+			priceID, err := result.LastInsertId()
+			if err != nil {
+					tx.Rollback()
+					return errors.NewInternalError("Error al obtener el id creado", err)
+			}
+			cp.Price.ID = priceID
+			cp.Price.IDPriceList = listID
+
+			for _, variantID := range cp.VariantIDs {
+					_, err := tx.ExecContext(ctx, `
+							INSERT INTO n_product_variant_prices 
+							(id_product_variant, id_price, is_active, created_at, updated_at)
+							VALUES (?, ?, true, NOW(), NOW())
+					`, variantID, priceID)
+					if err != nil {
+							tx.Rollback()
+							return errors.NewMysqlError(err)
+					}
+			}
+	}
+
+	for i := range tiers {
+			_, err := tx.ExecContext(ctx, `
+					INSERT INTO price_tiers
+					(id_price_list, id_product_variant, min_quantity, max_quantity, amount, created_at, updated_at)
+					VALUES (?, ?, ?, ?, ?, NOW(), NOW())
+			`, listID, tiers[i].IDProductVariant, tiers[i].MinQuantity, tiers[i].MaxQuantity, tiers[i].Amount)
+			if err != nil {
+					tx.Rollback()
+					return errors.NewMysqlError(err)
+			}
+			tiers[i].IDPriceList = listID
+	}
+
+	return tx.Commit()
+}
@@ -4,15 +4,32 @@ package prices
 import (
 	"context"
 	"ecom/internal/shared/errors"
+	"ecom/internal/shared/money"
+	"ecom/internal/shared/spreadsheet"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
 	"time"
+
+	currency "ecom/internal/use_cases/currencies"
+)
+
+const (
+	PriceSourceCustomerGroup = "customer_group"
+	PriceSourceDefault       = "default"
 )
 
 type Service struct {
-	repo Repository
+	repo            Repository
+	currencyService *currency.Service
 }
 
-func NewService(repo Repository) *Service {
-	return &Service{repo: repo}
+func NewService(repo Repository, currencyService *currency.Service) *Service {
+	return &Service{
+		repo:            repo,
+		currencyService: currencyService,
+	}
 }
 
 // PriceList methods
@@ -32,6 +49,12 @@ func (s *Service) CreatePriceList(ctx context.Context, req *CreatePriceListReque
 		// Si ya existe una lista por defecto y esta nueva será default
 		return nil, errors.NewBadRequestError("Ya existe una lista de precios por defecto")
 	}
+
+	if req.IDCurrency != nil {
+		if _, err := s.currencyService.GetCurrency(ctx, *req.IDCurrency); err != nil {
+			return nil, err
+		}
+	}
 	
 	
 
@@ -41,6 +64,7 @@ func (s *Service) CreatePriceList(ctx context.Context, req *CreatePriceListReque
 			IsDefault:   req.IsDefault,
 			Priority:    req.Priority,
 			Status:      req.Status,
+			IDCurrency:  req.IDCurrency,
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
 	}
@@ -81,6 +105,13 @@ func (s *Service) UpdatePriceList(ctx context.Context, id int64, req *UpdatePric
 	if req.Status != nil {
 		priceList.Status = *req.Status
 	}
+	if req.IDCurrency != nil {
+		// Cambiar la moneda no convierte los importes: sólo cambia cómo se interpretan
+		if _, err := s.currencyService.GetCurrency(ctx, *req.IDCurrency); err != nil {
+			return nil, err
+		}
+		priceList.IDCurrency = req.IDCurrency
+	}
 	if req.IsDefault != nil {
 		if wasDefault && !*req.IsDefault {
 				// Verificar si hay otras listas que podrían ser default
@@ -171,14 +202,15 @@ func (s *Service) CreatePrice(ctx context.Context, req *CreatePriceRequest) (*Pr
 
 	price := &Price{
 			IDPriceList: req.IDPriceList,
-			Amount:      req.Amount,
+			Amount:      money.Round(req.Amount, money.AmountScale),
 			StartsAt:    req.StartsAt,
 			EndsAt:      req.EndsAt,
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
 	}
 
-	if err := s.repo.CreatePrice(ctx, price); err != nil {
+	// Con variante, el repositorio valida los solapamientos y asigna el precio en la misma transacción
+	if err := s.repo.CreatePrice(ctx, price, req.IDProductVariant); err != nil {
 			return nil, err
 	}
 
@@ -201,7 +233,7 @@ func (s *Service) UpdatePrice(ctx context.Context, id int64, req *UpdatePriceReq
 	}
 
 	if req.Amount != nil {
-			price.Amount = *req.Amount
+			price.Amount = money.Round(*req.Amount, money.AmountScale)
 	}
 	if req.StartsAt != nil {
 			price.StartsAt = *req.StartsAt
@@ -215,8 +247,10 @@ func (s *Service) UpdatePrice(ctx context.Context, id int64, req *UpdatePriceReq
 			return nil, err
 	}
 
+	// Un cambio de fechas no puede solapar el precio con otros de las variantes que lo usan; el
+	// repositorio lo comprueba en la misma transacción que la actualización
 	price.UpdatedAt = time.Now()
-	if err := s.repo.UpdatePrice(ctx, price); err != nil {
+	if err := s.repo.UpdatePrice(ctx, price, req.StartsAt != nil || req.EndsAt != nil); err != nil {
 			return nil, err
 	}
 
@@ -248,24 +282,22 @@ func (s *Service) ListPrices(ctx context.Context, priceListID int64, p *Paginati
 
 // ProductVariantPrice methods
 func (s *Service) AssignPrice(ctx context.Context, req *AssignPriceRequest) error {
-	// Validar que el precio exista y esté vigente
+	// Validar que el precio exista y no haya expirado; los precios futuros quedan programados
 	price, err := s.repo.GetPriceByID(ctx, req.IDPrice)
 	if err != nil {
 			return err
 	}
 
-	now := time.Now()
-	if price.StartsAt.After(now) {
-			return errors.NewBadRequestError("El precio aún no está vigente")
-	}
-	if price.EndsAt != nil && price.EndsAt.Before(now) {
+	if price.EndsAt != nil && price.EndsAt.Before(time.Now()) {
 			return errors.NewBadRequestError("El precio ha expirado")
 	}
 
+	// Los solapamientos se validan en el repositorio, en la misma transacción que la asignación
 	pvp := &ProductVariantPrice{
 			IDProductVariant: req.IDProductVariant,
 			IDPrice:         req.IDPrice,
 			IsActive:        req.IsActive,
+			Price:           price,
 			CreatedAt:       time.Now(),
 			UpdatedAt:       time.Now(),
 	}
@@ -287,10 +319,812 @@ func (s *Service) GetActivePrice(ctx context.Context, productVariantID int64) (*
 }
 
 func (s *Service) GetAllPrices(ctx context.Context, productVariantID int64) ([]ProductVariantPrice, error) {
-	return s.repo.GetAllPrices(ctx, productVariantID)
+	prices, err := s.repo.GetAllPrices(ctx, productVariantID)
+	if err != nil {
+			return nil, err
+	}
+
+	tiers, err := s.repo.ListVariantPriceTiers(ctx, productVariantID)
+	if err != nil {
+			return nil, err
+	}
+
+	// Adjuntar la tabla de tramos de cada lista a sus precios
+	tiersByList := make(map[int64][]PriceTier)
+	for _, t := range tiers {
+			tiersByList[t.IDPriceList] = append(tiersByList[t.IDPriceList], t)
+	}
+	for i := range prices {
+			prices[i].Tiers = tiersByList[prices[i].Price.IDPriceList]
+	}
+
+	return prices, nil
+}
+
+// GetPriceCalendar construye la línea temporal de precios efectivos de una variante
+// considerando todas sus listas, con el mismo criterio de prioridad que GetActivePrice
+func (s *Service) GetPriceCalendar(ctx context.Context, productVariantID int64, req *PriceCalendarRequest) (*PriceCalendarResponse, error) {
+	from := time.Now()
+	if req.From != nil {
+			from = *req.From
+	}
+	if req.To != nil && !req.To.After(from) {
+			return nil, errors.NewBadRequestError("La fecha de fin debe ser posterior a la fecha de inicio")
+	}
+
+	assignments, err := s.repo.GetAllPrices(ctx, productVariantID)
+	if err != nil {
+			return nil, err
+	}
+
+	var prices []*Price
+	for i := range assignments {
+			if assignments[i].IsActive {
+					prices = append(prices, assignments[i].Price)
+			}
+	}
+
+	// Mismo orden que GetActivePrice: prioridad de la lista y después el precio más reciente
+	sort.SliceStable(prices, func(i, j int) bool {
+			if prices[i].PriceList.Priority != prices[j].PriceList.Priority {
+					return prices[i].PriceList.Priority > prices[j].PriceList.Priority
+			}
+			return prices[i].CreatedAt.After(prices[j].CreatedAt)
+	})
+
+	// Los cambios de precio sólo pueden ocurrir al inicio o al fin de algún precio
+	inRange := func(t time.Time) bool {
+			return t.After(from) && (req.To == nil || t.Before(*req.To))
+	}
+	boundaries := []time.Time{from}
+	for _, p := range prices {
+			if inRange(p.StartsAt) {
+					boundaries = append(boundaries, p.StartsAt)
+			}
+			if p.EndsAt != nil && inRange(*p.EndsAt) {
+					boundaries = append(boundaries, *p.EndsAt)
+			}
+	}
+	sort.Slice(boundaries, func(i, j int) bool { return boundaries[i].Before(boundaries[j]) })
+
+	var entries []PriceCalendarEntry
+	for i, start := range boundaries {
+			if i > 0 && start.Equal(boundaries[i-1]) {
+					continue
+			}
+
+			var end *time.Time
+			for _, b := range boundaries[i+1:] {
+					if b.After(start) {
+							next := b
+							end = &next
+							break
+					}
+			}
+			if end == nil && req.To != nil {
+					end = req.To
+			}
+
+			var winner *Price
+			var shadowed []int64
+			for _, p := range prices {
+					if !p.IsEffectiveAt(start) {
+							continue
+					}
+					if winner == nil {
+							winner = p
+					} else {
+							shadowed = append(shadowed, p.ID)
+					}
+			}
+
+			// Unir periodos consecutivos con el mismo resultado
+			if n := len(entries); n > 0 && samePriceID(entries[n-1].Price, winner) &&
+					equalIDs(entries[n-1].Shadowed, shadowed) {
+					entries[n-1].EndsAt = end
+					continue
+			}
+
+			entry := PriceCalendarEntry{StartsAt: start, EndsAt: end, Shadowed: shadowed}
+			if winner != nil {
+					entry.Price = mapPriceToResponse(winner)
+			}
+			entries = append(entries, entry)
+	}
+
+	return &PriceCalendarResponse{
+			IDProductVariant: productVariantID,
+			From:             from,
+			To:               req.To,
+			Entries:          entries,
+	}, nil
+}
+
+// overlapError devuelve un conflicto si price coincide en el tiempo con otro de los precios existing
+// de la variante
+func overlapError(productVariantID int64, price *Price, existing []Price) error {
+	for i := range existing {
+			if existing[i].ID == price.ID {
+					continue
+			}
+			if price.Overlaps(&existing[i]) {
+					return errors.NewConflictError(fmt.Sprintf(
+							"El periodo del precio se solapa con el precio %d de la variante %d en la misma lista",
+							existing[i].ID, productVariantID))
+			}
+	}
+
+	return nil
+}
+
+// PriceTier methods
+
+// SetPriceTiers reemplaza los tramos por volumen de la variante en la lista. Los tramos sólo
+// sustituyen al precio base de la misma lista: mientras la variante no tenga un precio vigente en
+// ella, la lista no participa en ResolvePrice y sus tramos no se aplican
+func (s *Service) SetPriceTiers(ctx context.Context, priceListID int64, req *SetPriceTiersRequest) ([]PriceTier, error) {
+	if _, err := s.repo.GetPriceListByID(ctx, priceListID); err != nil {
+			return nil, err
+	}
+
+	tiers := make([]PriceTier, len(req.Tiers))
+	for i, t := range req.Tiers {
+			tiers[i] = PriceTier{
+					MinQuantity: t.MinQuantity,
+					MaxQuantity: t.MaxQuantity,
+					Amount:      money.Round(t.Amount, money.AmountScale),
+					CreatedAt:   time.Now(),
+					UpdatedAt:   time.Now(),
+			}
+	}
+
+	if err := validatePriceTiers(tiers); err != nil {
+			return nil, err
+	}
+
+	if err := s.repo.ReplacePriceTiers(ctx, priceListID, req.IDProductVariant, tiers); err != nil {
+			return nil, err
+	}
+
+	return s.repo.ListPriceTiers(ctx, priceListID, req.IDProductVariant)
+}
+
+func (s *Service) GetPriceTiers(ctx context.Context, priceListID, productVariantID int64) ([]PriceTier, error) {
+	if _, err := s.repo.GetPriceListByID(ctx, priceListID); err != nil {
+			return nil, err
+	}
+
+	return s.repo.ListPriceTiers(ctx, priceListID, productVariantID)
+}
+
+func (s *Service) DeletePriceTiers(ctx context.Context, priceListID, productVariantID int64) error {
+	return s.repo.DeletePriceTiers(ctx, priceListID, productVariantID)
+}
+
+// ResolvePrice obtiene el precio efectivo de una variante para un cliente (o conjunto de grupos),
+// evaluando primero las listas de sus grupos y después la lista por defecto. Sólo compiten las
+// listas con precio base vigente para la variante; los tramos por volumen se aplican después sobre
+// la lista ganadora, así que los de una lista sin precio base se ignoran
+func (s *Service) ResolvePrice(ctx context.Context, productVariantID int64, req *ResolvePriceRequest) (*ResolvedPriceResponse, error) {
+	if req.Quantity < 1 {
+		req.Quantity = 1
+	}
+
+	var candidates []PriceCandidate
+	var winner *Price
+	var winnerGroup *GroupPriceList
+
+	groupLists, err := s.repo.GetGroupPriceLists(ctx, req.IDCustomer, req.GroupIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Listas de los grupos: gana la de mayor prioridad y, a igual prioridad, el menor importe
+	for i := range groupLists {
+		gpl := &groupLists[i]
+		groupID := gpl.IDCustomerGroup
+		candidate := PriceCandidate{
+			IDPriceList:     gpl.PriceList.ID,
+			PriceListName:   gpl.PriceList.Name,
+			Priority:        gpl.PriceList.Priority,
+			Source:          PriceSourceCustomerGroup,
+			IDCustomerGroup: &groupID,
+			GroupName:       gpl.GroupName,
+		}
+
+		price, err := s.repo.GetActivePriceInList(ctx, productVariantID, gpl.PriceList.ID)
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				return nil, err
+			}
+			candidate.Reason = "La lista no tiene un precio vigente para la variante"
+			candidates = append(candidates, candidate)
+			continue
+		}
+
+		candidate.IDPrice = &price.ID
+		candidate.Amount = &price.Amount
+		candidate.Reason = "Precio vigente en la lista del grupo"
+		candidates = append(candidates, candidate)
+
+		if winner == nil ||
+			price.PriceList.Priority > winner.PriceList.Priority ||
+			(price.PriceList.Priority == winner.PriceList.Priority && price.Amount.LessThan(winner.Amount)) {
+			winner = price
+			winnerGroup = gpl
+		}
+	}
+
+	source := PriceSourceCustomerGroup
+	if winner == nil {
+		// Sin precio de grupo, se usa la lista por defecto
+		source = PriceSourceDefault
+		defaultList, err := s.repo.GetDefaultPriceList(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		candidate := PriceCandidate{
+			IDPriceList:   defaultList.ID,
+			PriceListName: defaultList.Name,
+			Priority:      defaultList.Priority,
+			Source:        PriceSourceDefault,
+		}
+
+		price, err := s.repo.GetActivePriceInList(ctx, productVariantID, defaultList.ID)
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				return nil, err
+			}
+			return nil, errors.NewNotFoundError("No hay precio vigente para esta variante")
+		}
+
+		candidate.IDPrice = &price.ID
+		candidate.Amount = &price.Amount
+		candidate.Reason = "Precio vigente en la lista por defecto"
+		candidates = append(candidates, candidate)
+		winner = price
+	}
+
+	for i := range candidates {
+		if candidates[i].IDPrice != nil && *candidates[i].IDPrice == winner.ID &&
+			candidates[i].IDPriceList == winner.IDPriceList {
+			candidates[i].Selected = true
+			break
+		}
+	}
+
+	resolved := &ResolvedPriceResponse{
+		IDProductVariant: productVariantID,
+		Quantity:         req.Quantity,
+		UnitAmount:       winner.Amount,
+		Source:           source,
+		Price:            mapPriceToResponse(winner),
+		Candidates:       candidates,
+	}
+
+	if winnerGroup != nil {
+		resolved.IDCustomerGroup = &winnerGroup.IDCustomerGroup
+		resolved.Explanation = fmt.Sprintf(
+			"Se aplicó la lista \"%s\" (prioridad %d) del grupo \"%s\"",
+			winner.PriceList.Name, winner.PriceList.Priority, winnerGroup.GroupName)
+	} else if len(groupLists) > 0 {
+		resolved.Explanation = fmt.Sprintf(
+			"Ninguna lista de los grupos del cliente tiene precio vigente; se aplicó la lista por defecto \"%s\"",
+			winner.PriceList.Name)
+	} else {
+		resolved.Explanation = fmt.Sprintf(
+			"El cliente no pertenece a grupos con lista de precios; se aplicó la lista por defecto \"%s\"",
+			winner.PriceList.Name)
+	}
+
+	// Precio por volumen: si la cantidad cae en un tramo de la lista ganadora, se usa su importe
+	tiers, err := s.repo.ListPriceTiers(ctx, winner.IDPriceList, productVariantID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tiers {
+		if tiers[i].Matches(req.Quantity) {
+			resolved.UnitAmount = tiers[i].Amount
+			resolved.Tier = &tiers[i]
+			resolved.Explanation += fmt.Sprintf(" con el tramo por volumen desde %d unidades", tiers[i].MinQuantity)
+			break
+		}
+	}
+
+	if err := s.applyCurrency(ctx, resolved, winner.PriceList, req.CurrencyCode); err != nil {
+		return nil, err
+	}
+
+	resolved.TotalAmount = money.Round(
+		resolved.UnitAmount.Mul(money.NewFromInt(int64(resolved.Quantity))), money.AmountScale)
+
+	return resolved, nil
+}
+
+// applyCurrency convierte el importe unitario desde la moneda de la lista (o la base si no tiene)
+// a la moneda solicitada, con las reglas de redondeo y el formato de esa moneda
+func (s *Service) applyCurrency(ctx context.Context, resolved *ResolvedPriceResponse, priceList *PriceList, currencyCode string) error {
+	var sourceCode string
+	if priceList != nil && priceList.IDCurrency != nil {
+		listCurrency, err := s.currencyService.GetCurrency(ctx, *priceList.IDCurrency)
+		if err != nil {
+			return err
+		}
+		sourceCode = listCurrency.Code
+	} else {
+		base, err := s.currencyService.GetBaseCurrency(ctx)
+		if err != nil {
+			if errors.IsNotFound(err) && currencyCode == "" {
+				return nil
+			}
+			return err
+		}
+		sourceCode = base.Code
+	}
+
+	localized, err := s.currencyService.Localize(ctx, resolved.UnitAmount, sourceCode, currencyCode)
+	if err != nil {
+		return err
+	}
+
+	resolved.UnitAmount = localized.Amount
+	resolved.CurrencyCode = localized.CurrencyCode
+	resolved.ExchangeRate = localized.ExchangeRate
+	resolved.Display = localized
+	return nil
+}
+
+// DefaultPrices carga en dos consultas los precios vigentes y los tramos de las variantes en la
+// lista por defecto, como los resolvería ResolvePrice para un cliente sin grupos
+func (s *Service) DefaultPrices(ctx context.Context, productVariantIDs []int64) (*DefaultPriceSet, error) {
+	set := &DefaultPriceSet{prices: map[int64]money.Decimal{}, tiers: map[int64][]PriceTier{}}
+	if len(productVariantIDs) == 0 {
+		return set, nil
+	}
+
+	defaultList, err := s.repo.GetDefaultPriceList(ctx)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return set, nil
+		}
+		return nil, err
+	}
+
+	if defaultList.IDCurrency != nil {
+		listCurrency, err := s.currencyService.GetCurrency(ctx, *defaultList.IDCurrency)
+		if err != nil {
+			return nil, err
+		}
+		set.SourceCode = listCurrency.Code
+	} else {
+		base, err := s.currencyService.GetBaseCurrency(ctx)
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, err
+		}
+		if base != nil {
+			set.SourceCode = base.Code
+		}
+	}
+
+	active, err := s.repo.ListActivePricesForVariants(ctx, defaultList.ID, productVariantIDs)
+	if err != nil {
+		return nil, err
+	}
+	// Igual que GetActivePriceInList: entre los vigentes gana el creado más tarde
+	now := time.Now()
+	for variantID, list := range active {
+		var winner *Price
+		for i := range list {
+			p := &list[i]
+			if p.StartsAt.After(now) {
+				continue
+			}
+			if winner == nil || p.CreatedAt.After(winner.CreatedAt) {
+				winner = p
+			}
+		}
+		if winner != nil {
+			set.prices[variantID] = winner.Amount
+		}
+	}
+
+	tiers, err := s.repo.ListPriceTiersForVariants(ctx, defaultList.ID, productVariantIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tiers {
+		set.tiers[t.IDProductVariant] = append(set.tiers[t.IDProductVariant], t)
+	}
+	return set, nil
+}
+
+// ClonePriceList crea una lista nueva a partir de los precios activos de otra, aplicando
+// opcionalmente un ajuste porcentual o fijo y una regla de redondeo
+func (s *Service) ClonePriceList(ctx context.Context, sourceID int64, req *ClonePriceListRequest) (*ClonePriceListResponse, error) {
+	source, err := s.repo.GetPriceListByID(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments, err := s.repo.ListActiveAssignments(ctx, sourceID, req.IDCategory, req.IDTag)
+	if err != nil {
+		return nil, err
+	}
+
+	// Un mismo precio puede estar asignado a varias variantes: se copia una sola vez
+	var cloned []ClonedPrice
+	index := make(map[int64]int)
+	variantSet := make(map[int64]bool)
+	for _, a := range assignments {
+		variantSet[a.IDProductVariant] = true
+		if i, ok := index[a.IDPrice]; ok {
+			cloned[i].VariantIDs = append(cloned[i].VariantIDs, a.IDProductVariant)
+			continue
+		}
+		index[a.IDPrice] = len(cloned)
+		cloned = append(cloned, ClonedPrice{
+			SourcePriceID: a.IDPrice,
+			Price: &Price{
+				Amount:   adjustAmount(a.Price.Amount, req),
+				StartsAt: a.Price.StartsAt,
+				EndsAt:   a.Price.EndsAt,
+			},
+			VariantIDs: []int64{a.IDProductVariant},
+		})
+	}
+
+	variantIDs := make([]int64, 0, len(variantSet))
+	for id := range variantSet {
+		variantIDs = append(variantIDs, id)
+	}
+
+	tiers, err := s.repo.ListPriceTiersForVariants(ctx, sourceID, variantIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tiers {
+		tiers[i].Amount = adjustAmount(tiers[i].Amount, req)
+	}
+
+	priceList := &PriceList{
+		Name:        req.Name,
+		Description: req.Description,
+		IsDefault:   false,
+		Priority:    req.Priority,
+		Status:      req.Status,
+		IDCurrency:  source.IDCurrency,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	if err := s.repo.ClonePriceList(ctx, priceList, cloned, tiers); err != nil {
+		return nil, err
+	}
+
+	return &ClonePriceListResponse{
+		PriceList:         mapPriceListToResponse(priceList),
+		SourcePriceListID: sourceID,
+		PricesCloned:      len(cloned),
+		AssignmentsCloned: len(assignments),
+		TiersCloned:       len(tiers),
+	}, nil
+}
+
+// Bulk import/export
+var importDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ImportPrices valida un archivo CSV/XLSX (sku, amount, starts_at, ends_at) y, si no es dry-run
+// y todas las filas son válidas, crea y asigna los precios en una única transacción
+func (s *Service) ImportPrices(ctx context.Context, priceListID int64, fileName string, content io.Reader, dryRun bool) (*PriceImportReport, error) {
+	if _, err := s.repo.GetPriceListByID(ctx, priceListID); err != nil {
+		return nil, err
+	}
+
+	format, err := spreadsheet.FormatFromFileName(fileName)
+	if err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+
+	table, err := spreadsheet.Read(content, format)
+	if err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+
+	for _, column := range []string{"sku", "amount"} {
+		if table.Column(column) < 0 {
+			return nil, errors.NewBadRequestError(fmt.Sprintf("Falta la columna obligatoria \"%s\"", column))
+		}
+	}
+
+	skus := make([]string, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		if sku := table.Value(row, "sku"); sku != "" {
+			skus = append(skus, sku)
+		}
+	}
+	variantIDs, err := s.repo.GetVariantIDsBySKU(ctx, skus)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &PriceImportReport{
+		IDPriceList: priceListID,
+		DryRun:      dryRun,
+		TotalRows:   len(table.Rows),
+		Rows:        make([]PriceImportRow, 0, len(table.Rows)),
+	}
+	now := time.Now()
+
+	for i, row := range table.Rows {
+		// +2: la fila 1 es la cabecera y las filas se numeran desde 1
+		line := PriceImportRow{Row: i + 2, SKU: table.Value(row, "sku")}
+
+		// Un SKU puede repetirse para programar varios periodos que no se solapen
+		if line.SKU == "" {
+			line.Errors = append(line.Errors, "El SKU es obligatorio")
+		} else if id, ok := variantIDs[line.SKU]; ok {
+			line.IDProductVariant = id
+		} else {
+			line.Errors = append(line.Errors, "No existe una variante con ese SKU")
+		}
+
+		amount, err := parseImportAmount(table.Value(row, "amount"))
+		if err != nil {
+			line.Errors = append(line.Errors, err.Error())
+		}
+		line.Amount = amount
+
+		startsAt := now
+		if value := table.Value(row, "starts_at"); value != "" {
+			if startsAt, err = parseImportDate(value); err != nil {
+				line.Errors = append(line.Errors, "starts_at: "+err.Error())
+			}
+		}
+		line.StartsAt = &startsAt
+
+		if value := table.Value(row, "ends_at"); value != "" {
+			endsAt, err := parseImportDate(value)
+			if err != nil {
+				line.Errors = append(line.Errors, "ends_at: "+err.Error())
+			} else {
+				line.EndsAt = &endsAt
+				if !endsAt.After(now) {
+					line.Errors = append(line.Errors, "La fecha de fin ya pasó")
+				}
+			}
+		}
+
+		if err := validatePriceDates(startsAt, line.EndsAt); err != nil {
+			line.Errors = append(line.Errors, err.Error())
+		}
+
+		report.Rows = append(report.Rows, line)
+	}
+
+	closes, err := s.checkImportOverlaps(ctx, priceListID, report.Rows, now)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]PriceImportItem, 0, len(report.Rows))
+	for i, line := range report.Rows {
+		if len(line.Errors) > 0 {
+			report.InvalidRows++
+			continue
+		}
+		report.ValidRows++
+		items = append(items, PriceImportItem{
+			IDProductVariant: line.IDProductVariant,
+			Price: &Price{
+				IDPriceList: priceListID,
+				Amount:      line.Amount,
+				StartsAt:    *line.StartsAt,
+				EndsAt:      line.EndsAt,
+			},
+			Close: closes[i],
+		})
+	}
+
+	// La importación es todo o nada: con errores o en dry-run no se aplica nada
+	if dryRun || report.InvalidRows > 0 || len(items) == 0 {
+		return report, nil
+	}
+
+	// Por variante y fecha: los precios que se cierran van en la primera fila de la variante,
+	// y las variantes se bloquean siempre en el mismo orden
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].IDProductVariant != items[j].IDProductVariant {
+			return items[i].IDProductVariant < items[j].IDProductVariant
+		}
+		return items[i].Price.StartsAt.Before(items[j].Price.StartsAt)
+	})
+
+	if err := s.repo.ImportPrices(ctx, priceListID, items); err != nil {
+		return nil, err
+	}
+	report.Imported = len(items)
+
+	return report, nil
+}
+
+// checkImportOverlaps añade a las filas los errores de solapamiento entre filas de la misma
+// variante y con sus precios activos en la lista. Un precio vigente sólo se cierra si las filas
+// de la variante lo sustituyen por completo, desde su primera fecha y sin huecos; los programados
+// y los que el archivo sólo cubre en parte se informan como error. Devuelve, por índice de fila,
+// los precios que se cierran al aplicar esa fila
+func (s *Service) checkImportOverlaps(ctx context.Context, priceListID int64, rows []PriceImportRow, now time.Time) (map[int][]int64, error) {
+	byVariant := make(map[int64][]int)
+	var variantIDs []int64
+	for i, line := range rows {
+		if line.IDProductVariant == 0 || line.StartsAt == nil {
+			continue
+		}
+		if _, ok := byVariant[line.IDProductVariant]; !ok {
+			variantIDs = append(variantIDs, line.IDProductVariant)
+		}
+		byVariant[line.IDProductVariant] = append(byVariant[line.IDProductVariant], i)
+	}
+
+	existing, err := s.repo.ListActivePricesForVariants(ctx, priceListID, variantIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	closes := make(map[int][]int64)
+	for _, variantID := range variantIDs {
+		group := byVariant[variantID]
+		sort.SliceStable(group, func(a, b int) bool { return rows[group[a]].StartsAt.Before(*rows[group[b]].StartsAt) })
+		period := func(i int) *Price {
+			return &Price{StartsAt: *rows[i].StartsAt, EndsAt: rows[i].EndsAt}
+		}
+
+		for a, i := range group {
+			for _, j := range group[:a] {
+				if period(i).Overlaps(period(j)) {
+					rows[i].Errors = append(rows[i].Errors, fmt.Sprintf("Se solapa con la fila %d del archivo", rows[j].Row))
+					break
+				}
+			}
+		}
+
+		// Tramo continuo que cubren las filas desde la primera fecha; nil = sin fin
+		first := group[0]
+		coveredUntil := rows[first].EndsAt
+		for _, i := range group[1:] {
+			if coveredUntil == nil || !rows[i].StartsAt.Equal(*coveredUntil) {
+				break
+			}
+			coveredUntil = rows[i].EndsAt
+		}
+
+		for k := range existing[variantID] {
+			current := &existing[variantID][k]
+			var overlapping []int
+			for _, i := range group {
+				if current.Overlaps(period(i)) {
+					overlapping = append(overlapping, i)
+				}
+			}
+			if len(overlapping) == 0 {
+				continue
+			}
+
+			replaced := current.IsEffectiveAt(now) && current.StartsAt.Before(*rows[first].StartsAt) &&
+				(coveredUntil == nil || (current.EndsAt != nil && !current.EndsAt.After(*coveredUntil)))
+			if replaced {
+				closes[first] = append(closes[first], current.ID)
+				continue
+			}
+			for _, i := range overlapping {
+				rows[i].Errors = append(rows[i].Errors, fmt.Sprintf(
+					"Se solapa con el precio %d de la lista, que no se puede sustituir: está programado o el archivo sólo cubre parte de su periodo",
+					current.ID))
+			}
+		}
+	}
+	return closes, nil
+}
+
+// ExportPrices escribe los precios vigentes y programados de una lista en CSV o XLSX
+func (s *Service) ExportPrices(ctx context.Context, priceListID int64, format string, w io.Writer) error {
+	if _, err := s.repo.GetPriceListByID(ctx, priceListID); err != nil {
+		return err
+	}
+
+	if format != spreadsheet.FormatCSV && format != spreadsheet.FormatXLSX {
+		return errors.NewBadRequestError("Formato no soportado, use csv o xlsx")
+	}
+
+	rows, err := s.repo.ListCurrentPrices(ctx, priceListID)
+	if err != nil {
+		return err
+	}
+
+	table := &spreadsheet.Table{
+		Header: []string{"sku", "variant_name", "amount", "starts_at", "ends_at"},
+		Rows:   make([][]string, 0, len(rows)),
+	}
+	for _, row := range rows {
+		endsAt := ""
+		if row.Price.EndsAt != nil {
+			endsAt = row.Price.EndsAt.Format(time.RFC3339)
+		}
+		table.Rows = append(table.Rows, []string{
+			row.SKU,
+			row.VariantName,
+			row.Price.Amount.StringFixed(money.AmountScale),
+			row.Price.StartsAt.Format(time.RFC3339),
+			endsAt,
+		})
+	}
+
+	return spreadsheet.Write(w, format, table)
 }
 
 // Helper functions
+
+// adjustAmount aplica el ajuste y el redondeo de la clonación; nunca devuelve importes negativos
+func adjustAmount(amount money.Decimal, req *ClonePriceListRequest) money.Decimal {
+	switch req.AdjustmentType {
+	case "percentage":
+		amount = amount.Add(money.Percentage(amount, req.AdjustmentValue))
+	case "fixed":
+		amount = amount.Add(req.AdjustmentValue)
+	}
+	if amount.IsNegative() {
+		amount = money.Zero
+	}
+
+	step := req.RoundingStep
+	if step.Sign() <= 0 {
+		step = money.NewFromFloat(0.01)
+	}
+
+	switch req.Rounding {
+	case "nearest":
+		amount = money.RoundToStep(amount, step, money.RoundHalfUp)
+	case "up":
+		amount = money.RoundToStep(amount, step, money.RoundUp)
+	case "down":
+		amount = money.RoundToStep(amount, step, money.RoundDown)
+	}
+
+	return money.Round(amount, money.AmountScale)
+}
+
+func parseImportAmount(value string) (money.Decimal, error) {
+	if value == "" {
+		return money.Zero, errors.NewValidationError("El importe es obligatorio")
+	}
+	if !strings.Contains(value, ".") {
+		value = strings.Replace(value, ",", ".", 1)
+	}
+	amount, err := money.NewFromString(value)
+	if err != nil {
+		return money.Zero, errors.NewValidationError("El importe no es un número válido")
+	}
+	if amount.IsNegative() {
+		return money.Zero, errors.NewValidationError("El importe no puede ser negativo")
+	}
+	return money.Round(amount, money.AmountScale), nil
+}
+
+func parseImportDate(value string) (time.Time, error) {
+	for _, layout := range importDateLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.NewValidationError("Fecha inválida, use el formato AAAA-MM-DD o RFC3339")
+}
+
 func validatePriceDates(startsAt time.Time, endsAt *time.Time) error {
 	if endsAt == nil {
 			return nil
@@ -303,6 +1137,57 @@ func validatePriceDates(startsAt time.Time, endsAt *time.Time) error {
 	return nil
 }
 
+// validatePriceTiers ordena los tramos por cantidad mínima y verifica que no se solapen
+func validatePriceTiers(tiers []PriceTier) error {
+	sort.Slice(tiers, func(i, j int) bool {
+			return tiers[i].MinQuantity < tiers[j].MinQuantity
+	})
+
+	for i, t := range tiers {
+			if t.MaxQuantity != nil && *t.MaxQuantity < t.MinQuantity {
+					return errors.NewBadRequestError(fmt.Sprintf(
+							"El tramo desde %d unidades tiene una cantidad máxima menor que la mínima", t.MinQuantity))
+			}
+
+			if i == 0 {
+					continue
+			}
+
+			prev := tiers[i-1]
+			if prev.MaxQuantity == nil {
+					return errors.NewBadRequestError(fmt.Sprintf(
+							"El tramo desde %d unidades no tiene límite y se solapa con el tramo desde %d unidades",
+							prev.MinQuantity, t.MinQuantity))
+			}
+			if t.MinQuantity <= *prev.MaxQuantity {
+					return errors.NewBadRequestError(fmt.Sprintf(
+							"Los tramos %d-%d y desde %d unidades se solapan",
+							prev.MinQuantity, *prev.MaxQuantity, t.MinQuantity))
+			}
+	}
+
+	return nil
+}
+
+func samePriceID(a *PriceResponse, b *Price) bool {
+	if a == nil || b == nil {
+			return a == nil && b == nil
+	}
+	return a.ID == b.ID
+}
+
+func equalIDs(a, b []int64) bool {
+	if len(a) != len(b) {
+			return false
+	}
+	for i := range a {
+			if a[i] != b[i] {
+					return false
+			}
+	}
+	return true
+}
+
 func mapPriceListToResponse(pl *PriceList) *PriceListResponse {
 	return &PriceListResponse{
 			ID:          pl.ID,
@@ -311,6 +1196,7 @@ func mapPriceListToResponse(pl *PriceList) *PriceListResponse {
 			IsDefault:   pl.IsDefault,
 			Priority:    pl.Priority,
 			Status:      pl.Status,
+			IDCurrency:  pl.IDCurrency,
 			CreatedAt:   pl.CreatedAt,
 			UpdatedAt:   pl.UpdatedAt,
 	}
@@ -4,6 +4,8 @@ package product
 import (
 	at "ecom/internal/use_cases/attributeValue"
 	category "ecom/internal/use_cases/category"
+	"ecom/internal/shared/money"
+	currency "ecom/internal/use_cases/currencies"
 	tag "ecom/internal/use_cases/tag"
 	"time"
 )
@@ -14,6 +16,11 @@ type Product struct {
 	Slug        string           `json:"slug"`
 	Description string           `json:"description"`
 	Status      bool             `json:"status"`
+	PublicationStatus string     `json:"publication_status"`
+	PublishAt   *time.Time       `json:"publish_at,omitempty"`
+	UnpublishAt *time.Time       `json:"unpublish_at,omitempty"`
+	AverageRating float64        `json:"average_rating"` // Media de las reseñas aprobadas
+	ReviewCount int              `json:"review_count"`
 	IDCategory  int64            `json:"id_category"`
 	Category    *category.Category        `json:"category,omitempty"`
 	Tags        []tag.Tag            `json:"tags,omitempty"`
@@ -22,15 +29,226 @@ type Product struct {
 	UpdatedAt   time.Time        `json:"updated_at"`
 }
 
+// Tipos de relación entre productos
+const (
+	RelationRelated   = "related"
+	RelationCrossSell = "cross_sell"
+	RelationUpSell    = "up_sell"
+	RelationAccessory = "accessory"
+)
+
+// RelatedProduct producto relacionado con otro. Automatic indica que no está curado sino
+// sugerido por compartir categoría o tags
+type RelatedProduct struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	Slug      string `json:"slug"`
+	Type      string `json:"type"`
+	Position  int    `json:"position"`
+	Automatic bool   `json:"automatic"`
+}
+
+// Estados del ciclo de publicación de un producto
+const (
+	PublicationDraft     = "draft"
+	PublicationInReview  = "in_review"
+	PublicationScheduled = "scheduled"
+	PublicationPublished = "published"
+	PublicationArchived  = "archived"
+)
+
+// publicationTransitions estados a los que se puede pasar desde cada estado
+var publicationTransitions = map[string][]string{
+	PublicationDraft:     {PublicationInReview, PublicationScheduled, PublicationPublished, PublicationArchived},
+	PublicationInReview:  {PublicationDraft, PublicationScheduled, PublicationPublished, PublicationArchived},
+	PublicationScheduled: {PublicationDraft, PublicationInReview, PublicationScheduled, PublicationPublished, PublicationArchived},
+	PublicationPublished: {PublicationDraft, PublicationPublished, PublicationArchived},
+	PublicationArchived:  {PublicationDraft},
+}
+
+// canTransition indica si el producto puede pasar del estado from al estado to
+func canTransition(from, to string) bool {
+	for _, allowed := range publicationTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPublishedAt indica si el producto es visible en la tienda en el instante t
+func (p *Product) IsPublishedAt(t time.Time) bool {
+	if p.PublicationStatus != PublicationPublished {
+		return false
+	}
+	if p.PublishAt != nil && p.PublishAt.After(t) {
+		return false
+	}
+	return p.UnpublishAt == nil || p.UnpublishAt.After(t)
+}
+
 type ProductVariant struct {
 	ID              int64            `json:"id"`
 	Name            string           `json:"name"`
 	SKU             string           `json:"sku"`
-	Stock           int              `json:"stock"`
+	Stock           int              `json:"stock"`     // Existencias en los almacenes activos
+	Reserved        int              `json:"reserved"`  // Unidades apartadas por carritos sin pagar
+	Available       int              `json:"available"` // Disponible para vender: stock menos reservas
 	IDProduct       int64            `json:"id_product"`
 	IDTaxCategory   int64            `json:"id_tax_category"`
 	AttributeValues []at.AttributeValue  `json:"attribute_values,omitempty"`
+	Price           *currency.LocalizedPrice `json:"price,omitempty"` // Precio por defecto en la moneda pedida
+	Media           []VariantMedia   `json:"media,omitempty"`
+	Dimensions      *Dimensions      `json:"dimensions,omitempty"`
+	Bundle          *Bundle          `json:"bundle,omitempty"` // Sólo si la variante es un pack
 	CreatedAt       time.Time        `json:"created_at"`
 	UpdatedAt       time.Time        `json:"updated_at"`
 }
 
+// Modos de precio de un pack: el precio propio de la variante o la suma de sus componentes con descuento
+const (
+	BundlePricingFixed      = "fixed"
+	BundlePricingComponents = "components"
+)
+
+// Tipos de descuento sobre la suma de los componentes
+const (
+	BundleDiscountPercent = "percent"
+	BundleDiscountAmount  = "amount"
+)
+
+// Bundle pack o kit formado por otras variantes. Su stock no se guarda: son las unidades que
+// se pueden montar con el stock de los componentes
+type Bundle struct {
+	PricingMode   string            `json:"pricing_mode"`
+	DiscountType  string            `json:"discount_type,omitempty"`
+	DiscountValue money.Decimal     `json:"discount_value"`
+	Components    []BundleComponent `json:"components"`
+}
+
+type BundleComponent struct {
+	IDProductVariant int64  `json:"id_product_variant"`
+	Name             string `json:"name"`
+	SKU              string `json:"sku"`
+	Quantity         int    `json:"quantity"` // Unidades del componente por pack
+	Stock            int    `json:"stock"`    // Disponible para vender del componente
+}
+
+// AvailableStock unidades del pack que se pueden montar con el stock actual de los componentes
+func (b *Bundle) AvailableStock() int {
+	available := -1
+	for _, c := range b.Components {
+		units := 0
+		if c.Stock > 0 {
+			units = c.Stock / c.Quantity
+		}
+		if available < 0 || units < available {
+			available = units
+		}
+	}
+	if available < 0 {
+		return 0
+	}
+	return available
+}
+
+// ApplyDiscount descuenta de la suma de los componentes el porcentaje o importe del pack, sin bajar de cero
+func (b *Bundle) ApplyDiscount(sum money.Decimal) money.Decimal {
+	total := sum
+	switch b.DiscountType {
+	case BundleDiscountPercent:
+		total = sum.Sub(sum.Mul(b.DiscountValue).Div(money.NewFromInt(100)))
+	case BundleDiscountAmount:
+		total = sum.Sub(b.DiscountValue)
+	}
+	if total.IsNegative() {
+		return money.NewFromInt(0)
+	}
+	return total
+}
+
+// Unidades de medida admitidas. Las medidas se guardan siempre en cm y kg
+const (
+	UnitCentimeter = "cm"
+	UnitInch       = "in"
+	UnitKilogram   = "kg"
+	UnitPound      = "lb"
+)
+
+// VolumetricDivisor son los cm³ que equivalen a 1 kg para los transportistas (estándar IATA)
+const VolumetricDivisor = 5000
+
+var (
+	centimetersPerInch = money.NewFromFloat(2.54)
+	kilogramsPerPound  = money.NewFromFloat(0.45359237)
+)
+
+// Dimensions medidas de envío de una variante en cm y kg
+type Dimensions struct {
+	Length           money.Decimal `json:"length"`
+	Width            money.Decimal `json:"width"`
+	Height           money.Decimal `json:"height"`
+	Weight           money.Decimal `json:"weight"`
+	LengthUnit       string        `json:"length_unit"`
+	WeightUnit       string        `json:"weight_unit"`
+	VolumetricWeight money.Decimal `json:"volumetric_weight"` // largo × ancho × alto / VolumetricDivisor
+	ShippingWeight   money.Decimal `json:"shipping_weight"`   // el mayor entre el peso real y el volumétrico
+}
+
+// newDimensions crea las medidas a partir de valores en cm y kg y calcula los pesos derivados
+func newDimensions(length, width, height, weight money.Decimal) *Dimensions {
+	volumetric := length.Mul(width).Mul(height).Div(money.NewFromInt(VolumetricDivisor)).Round(3)
+	shipping := weight
+	if volumetric.GreaterThan(weight) {
+		shipping = volumetric
+	}
+
+	return &Dimensions{
+		Length:           length,
+		Width:            width,
+		Height:           height,
+		Weight:           weight,
+		LengthUnit:       UnitCentimeter,
+		WeightUnit:       UnitKilogram,
+		VolumetricWeight: volumetric,
+		ShippingWeight:   shipping,
+	}
+}
+
+// VariantMedia es una imagen de la galería de una variante
+type VariantMedia struct {
+	ID        int64  `json:"id"` // id del media
+	FileName  string `json:"file_name"`
+	FilePath  string `json:"file_path"`
+	MimeType  string `json:"mime_type"`
+	Position  int    `json:"position"`
+	IsPrimary bool   `json:"is_primary"`
+}
+
+// ProductSearchHit es un producto encontrado por la búsqueda con sus datos agregados
+type ProductSearchHit struct {
+	Product
+	MinPrice  *money.Decimal // Precio vigente más bajo de sus variantes en la lista por defecto
+	MaxPrice  *money.Decimal
+	Stock     int
+	Relevance float64
+}
+
+// FacetCount es el número de productos que cumplen la búsqueda para un valor de faceta
+type FacetCount struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+type AttributeFacet struct {
+	ID     int64        `json:"id"`
+	Name   string       `json:"name"`
+	Values []FacetCount `json:"values"`
+}
+
+type SearchFacets struct {
+	Categories []FacetCount     `json:"categories"`
+	Tags       []FacetCount     `json:"tags"`
+	Attributes []AttributeFacet `json:"attributes"`
+}
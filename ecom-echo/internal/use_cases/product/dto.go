@@ -2,6 +2,8 @@
 package product
 
 import (
+	"ecom/internal/shared/money"
+	at "ecom/internal/use_cases/attributeValue"
 	category "ecom/internal/use_cases/category"
 	tag "ecom/internal/use_cases/tag"
 	"time"
@@ -9,7 +11,7 @@ import (
 
 type CreateProductRequest struct {
 	Name              string  `json:"name" validate:"required,min=2"`
-	Slug              string  `json:"slug" validate:"required,min=2,slug"`
+	Slug              string  `json:"slug" validate:"omitempty,min=2,max=200,slug"` // Por defecto se genera a partir del nombre
 	Description       string  `json:"description"`
 	Status           bool    `json:"status"`
 	IDCategory       int64   `json:"id_category" validate:"required"`
@@ -23,6 +25,40 @@ type CreateProductVariantRequest struct {
 	Stock           int      `json:"stock" validate:"min=0"`
 	IDTaxCategory   int64    `json:"id_tax_category" validate:"omitempty"`
 	AttributeValues []CreateAttributeValueRequest `json:"attribute_values"`
+	Dimensions      *DimensionsRequest `json:"dimensions"`
+}
+
+// DimensionsRequest medidas de envío; se convierten a cm y kg al guardarlas
+type DimensionsRequest struct {
+	Length     money.Decimal `json:"length" validate:"gt=0,max=100000"`
+	Width      money.Decimal `json:"width" validate:"gt=0,max=100000"`
+	Height     money.Decimal `json:"height" validate:"gt=0,max=100000"`
+	Weight     money.Decimal `json:"weight" validate:"gt=0,max=100000"`
+	LengthUnit string        `json:"length_unit" validate:"omitempty,oneof=cm in"` // Por defecto cm
+	WeightUnit string        `json:"weight_unit" validate:"omitempty,oneof=kg lb"` // Por defecto kg
+}
+
+// toDimensions normaliza las medidas a cm (2 decimales) y kg (3 decimales). Siempre redondea
+// half_up: MONEY_ROUNDING es para importes y no debe cambiar las medidas guardadas
+func (r *DimensionsRequest) toDimensions() *Dimensions {
+	if r == nil {
+		return nil
+	}
+
+	length, width, height, weight := r.Length, r.Width, r.Height, r.Weight
+	if r.LengthUnit == UnitInch {
+		length = length.Mul(centimetersPerInch)
+		width = width.Mul(centimetersPerInch)
+		height = height.Mul(centimetersPerInch)
+	}
+	if r.WeightUnit == UnitPound {
+		weight = weight.Mul(kilogramsPerPound)
+	}
+
+	return newDimensions(
+		money.RoundWith(length, 2, money.RoundHalfUp), money.RoundWith(width, 2, money.RoundHalfUp),
+		money.RoundWith(height, 2, money.RoundHalfUp), money.RoundWith(weight, 3, money.RoundHalfUp),
+	)
 }
 
 type CreateAttributeValueRequest struct {
@@ -35,17 +71,23 @@ type ProductResponse struct {
 	Slug        string           `json:"slug"`
 	Description string           `json:"description"`
 	Status      bool             `json:"status"`
+	PublicationStatus string     `json:"publication_status"`
+	PublishAt   *time.Time       `json:"publish_at,omitempty"`
+	UnpublishAt *time.Time       `json:"unpublish_at,omitempty"`
+	AverageRating float64        `json:"average_rating"` // Media de las reseñas aprobadas
+	ReviewCount int              `json:"review_count"`
 	IDCategory  int64            `json:"id_category"`
 	Category    *category.Category        `json:"category,omitempty"`
 	Tags        []tag.Tag            `json:"tags,omitempty"`
 	Variants    []ProductVariant `json:"variants,omitempty"`
+	Relations   []RelatedProduct `json:"relations,omitempty"` // Sólo en el detalle
 	CreatedAt   time.Time        `json:"created_at"`
 	UpdatedAt   time.Time        `json:"updated_at"`
 }
 
 type UpdateProductRequest struct {
 	Name        *string `json:"name,omitempty" validate:"omitempty,min=2"`
-	Slug        *string `json:"slug,omitempty" validate:"omitempty,min=2,slug"`
+	Slug        *string `json:"slug,omitempty" validate:"omitempty,min=2,max=200,slug"`
 	Description *string `json:"description,omitempty"`
 	Status      *bool   `json:"status,omitempty"`
 	IDCategory  *int64  `json:"id_category,omitempty"`
@@ -58,22 +100,167 @@ type CreateVariantRequest struct {
 	IDTaxCategory   int64    `json:"id_tax_category" validate:"omitempty"`
 	IDProduct       int64    `json:"id_product" validate:"required"`
 	AttributeValues []CreateAttributeValueRequest `json:"attribute_values"`
+	Dimensions      *DimensionsRequest `json:"dimensions"`
 }
 
 type UpdateVariantRequest struct {
 	Name          *string `json:"name,omitempty"`
 	SKU           *string `json:"sku,omitempty"`
-	Stock         *int    `json:"stock,omitempty" validate:"omitempty,min=0"`
+	Stock         *int    `json:"stock,omitempty"` // Ya no se admite: el stock cambia con movimientos de inventario
 	IDTaxCategory *int64  `json:"id_tax_category,omitempty"`
 	AttributeValues *[]CreateAttributeValueRequest `json:"attribute_values,omitempty"`
+	Dimensions    *DimensionsRequest `json:"dimensions,omitempty"`
 	
 }
 
+// UpdatePublicationRequest cambio de estado de publicación. publish_at es obligatorio para
+// programar y unpublish_at, si se indica, retira el producto automáticamente
+type UpdatePublicationRequest struct {
+	Status      string     `json:"status" validate:"required,oneof=draft in_review scheduled published archived"`
+	PublishAt   *time.Time `json:"publish_at"`
+	UnpublishAt *time.Time `json:"unpublish_at"`
+}
+
+// ReplaceRelationsRequest sustituye las relaciones de un tipo; el orden de product_ids es el de
+// presentación y una lista vacía las elimina
+type ReplaceRelationsRequest struct {
+	Type       string  `json:"type" validate:"required,oneof=related cross_sell up_sell accessory"`
+	ProductIDs []int64 `json:"product_ids" validate:"max=50"`
+}
+
+// SetBundleRequest convierte la variante en pack o cambia su composición
+type SetBundleRequest struct {
+	Components    []BundleComponentRequest `json:"components" validate:"required,min=1,max=50,dive"`
+	PricingMode   string                   `json:"pricing_mode" validate:"required,oneof=fixed components"`
+	DiscountType  string                   `json:"discount_type" validate:"omitempty,oneof=percent amount"`
+	DiscountValue money.Decimal            `json:"discount_value" validate:"min=0"`
+}
+
+type BundleComponentRequest struct {
+	IDProductVariant int64 `json:"id_product_variant" validate:"required"`
+	Quantity         int   `json:"quantity" validate:"required,min=1"`
+}
+
+// ConsumeStockRequest líneas de un pedido confirmado; los packs descuentan el stock de sus componentes.
+// Con id_cart, las reservas del carrito se convierten en la venta. Sin almacén, la venta se reparte
+// entre los almacenes activos empezando por el predeterminado
+type ConsumeStockRequest struct {
+	Items       []StockLine `json:"items" validate:"required,min=1,dive"`
+	IDCart      *int64      `json:"id_cart,omitempty"`
+	IDWarehouse *int64      `json:"id_warehouse,omitempty"`
+	Reference   string      `json:"reference" validate:"max=100"`
+	Actor       string      `json:"actor" validate:"max=150"`
+}
+
+type StockLine struct {
+	IDProductVariant int64 `json:"id_product_variant" validate:"required"`
+	Quantity         int   `json:"quantity" validate:"required,min=1"`
+}
+
+// CloneProductRequest datos opcionales de la copia; por defecto "<nombre> (copia)" y "<slug>-copia"
+type CloneProductRequest struct {
+	Name   string `json:"name" validate:"omitempty,min=2"`
+	Slug   string `json:"slug" validate:"omitempty,min=2,slug"`
+	Status *bool  `json:"status"` // Por defecto la copia se crea desactivada
+}
+
+// VariantMatrixRequest atributos y valores elegidos; se genera una variante por cada combinación
+type VariantMatrixRequest struct {
+	Attributes    []VariantMatrixAttribute `json:"attributes" validate:"required,min=1,dive"`
+	Stock         int                      `json:"stock" validate:"min=0"`
+	IDTaxCategory int64                    `json:"id_tax_category"`
+	SKUPrefix     string                   `json:"sku_prefix" validate:"omitempty,max=20,slug"` // Por defecto, las 3 primeras letras del producto
+	Dimensions    *DimensionsRequest       `json:"dimensions"` // Se aplican a todas las variantes generadas
+}
+
+type VariantMatrixAttribute struct {
+	IDProductAttribute int64   `json:"id_product_attribute" validate:"required"`
+	ValueIDs           []int64 `json:"value_ids" validate:"required,min=1"`
+}
+
+type VariantMatrixItem struct {
+	ID              int64               `json:"id,omitempty"` // Sólo al confirmar
+	Name            string              `json:"name"`
+	SKU             string              `json:"sku"`
+	AttributeValues []at.AttributeValue `json:"attribute_values"`
+}
+
+// SkippedCombination combinación que ya existe como variante del producto
+type SkippedCombination struct {
+	IDVariant       int64               `json:"id_variant"`
+	AttributeValues []at.AttributeValue `json:"attribute_values"`
+}
+
+type VariantMatrixResponse struct {
+	Committed bool                 `json:"committed"`
+	Variants  []VariantMatrixItem  `json:"variants"`
+	Skipped   []SkippedCombination `json:"skipped"`
+}
+
+type AttachVariantMediaRequest struct {
+	IDMedia   int64 `json:"id_media" validate:"required"`
+	Position  *int  `json:"position" validate:"omitempty,min=0"` // Por defecto, al final de la galería
+	IsPrimary bool  `json:"is_primary"`
+}
+
+// ReorderVariantMediaRequest ids de todos los media de la variante en el nuevo orden
+type ReorderVariantMediaRequest struct {
+	MediaIDs []int64 `json:"media_ids" validate:"required,min=1"`
+}
+
 type Pagination struct {
 	Page       int    `query:"page" validate:"min=1"`
 	PerPage    int    `query:"per_page" validate:"min=1,max=100"`
 	Search     string `query:"search"`
 	Status     *bool  `query:"status"`
+	PublicationStatus string `query:"publication_status" validate:"omitempty,oneof=draft in_review scheduled published archived"`
 	IDCategory *int64 `query:"id_category"`
+	Currency   string `query:"currency" validate:"omitempty,len=3"`
+	Storefront bool   `query:"-"` // Sólo productos publicados en este momento; lo fija el handler de la tienda
+}
+
+
+const (
+	SortRelevance = "relevance"
+	SortNewest    = "newest"
+	SortNameAsc   = "name_asc"
+	SortNameDesc  = "name_desc"
+	SortPriceAsc  = "price_asc"
+	SortPriceDesc = "price_desc"
+)
+
+// SearchRequest filtros de la búsqueda de productos. Dentro de una misma faceta los valores
+// se combinan con OR y entre facetas con AND. Los rangos de precio usan la lista por defecto
+type SearchRequest struct {
+	Page              int            `query:"page" validate:"min=1"`
+	PerPage           int            `query:"per_page" validate:"min=1,max=100"`
+	Query             string         `query:"q" validate:"max=200"`
+	CategoryIDs       []int64        `query:"category_ids"`
+	TagIDs            []int64        `query:"tag_ids"`
+	AttributeValueIDs []int64        `query:"attribute_value_ids"`
+	MinPrice          *money.Decimal `query:"min_price" validate:"omitempty,min=0"`
+	MaxPrice          *money.Decimal `query:"max_price" validate:"omitempty,min=0"`
+	InStock           *bool          `query:"in_stock"`
+	Status            *bool          `query:"status"`
+	PublicationStatus string         `query:"publication_status" validate:"omitempty,oneof=draft in_review scheduled published archived"`
+	Storefront        bool           `query:"-"` // Sólo productos publicados en este momento; lo fija el handler de la tienda
+	Sort              string         `query:"sort" validate:"omitempty,oneof=relevance newest name_asc name_desc price_asc price_desc"`
+	Currency          string         `query:"currency" validate:"omitempty,len=3"`
 }
 
+type ProductSearchItem struct {
+	ProductResponse
+	MinPrice  *money.Decimal `json:"min_price,omitempty"`
+	MaxPrice  *money.Decimal `json:"max_price,omitempty"`
+	Stock     int            `json:"stock"`
+	Relevance float64        `json:"relevance"`
+}
+
+type SearchResponse struct {
+	Items   []ProductSearchItem `json:"items"`
+	Total   int64               `json:"total"`
+	Page    int                 `json:"page"`
+	PerPage int                 `json:"per_page"`
+	Sort    string              `json:"sort"`
+	Facets  *SearchFacets       `json:"facets"`
+}
@@ -21,9 +21,17 @@ func NewHandler(e *echo.Group, s *Service) {
 	// Rutas de productos
 	e.POST("", h.Create)
 	e.GET("", h.List)
+	e.GET("/search", h.Search)
 	e.GET("/:id", h.GetByID)
 	e.PUT("/:id", h.Update)
 	e.DELETE("/:id", h.Delete)
+	e.POST("/:id/clone", h.Clone)
+	e.PUT("/:id/publication", h.UpdatePublication)
+
+	// Relaciones curadas con otros productos
+	e.GET("/:id/relations", h.ListRelations)
+	e.PUT("/:id/relations", h.ReplaceRelations)
+	e.DELETE("/:id/relations/:relatedId", h.DeleteRelation)
 
 	// Rutas de variantes
 	e.POST("/variant", h.CreateVariant)
@@ -31,6 +39,29 @@ func NewHandler(e *echo.Group, s *Service) {
 	e.PUT("/variant/:id", h.UpdateVariant)
 	e.DELETE("/variant/:id", h.DeleteVariant)
 	e.GET("/:id/variants", h.ListVariants) //obtener todas las variatnes de un producto
+	e.POST("/:id/variants/matrix/preview", h.PreviewVariantMatrix)
+
+	// Galería de la variante
+	e.GET("/variant/:id/media", h.ListVariantMedia)
+	e.POST("/variant/:id/media", h.AttachVariantMedia)
+	e.PUT("/variant/:id/media/order", h.ReorderVariantMedia)
+	e.PUT("/variant/:id/media/:mediaId/primary", h.SetPrimaryVariantMedia)
+	e.DELETE("/variant/:id/media/:mediaId", h.DetachVariantMedia)
+	e.POST("/:id/variants/matrix", h.GenerateVariantMatrix)
+
+	// Packs y consumo de stock
+	e.PUT("/variant/:id/bundle", h.SetBundle)
+	e.DELETE("/variant/:id/bundle", h.DeleteBundle)
+	e.POST("/stock/consume", h.ConsumeStock)
+}
+
+// NewStorefrontHandler rutas públicas de la tienda: sólo productos publicados en este momento
+func NewStorefrontHandler(e *echo.Group, s *Service) {
+	h := &Handler{service: s}
+
+	e.GET("", h.StorefrontList)
+	e.GET("/search", h.StorefrontSearch)
+	e.GET("/:id", h.StorefrontGetByID)
 }
 
 // Handlers de Productos
@@ -63,7 +94,23 @@ func (h *Handler) GetByID(c echo.Context) error {
 					response.Error("ID inválido", err.Error()))
 	}
 
-	product, err := h.service.GetByID(c.Request().Context(), id)
+	product, err := h.service.GetByID(c.Request().Context(), id, c.QueryParam("currency"))
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Producto obtenido exitosamente", product))
+}
+
+func (h *Handler) StorefrontGetByID(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID inválido", err.Error()))
+	}
+
+	product, err := h.service.GetPublishedByID(c.Request().Context(), id, c.QueryParam("currency"))
 	if err != nil {
 			return h.handleError(c, err)
 	}
@@ -99,6 +146,124 @@ func (h *Handler) Update(c echo.Context) error {
 			response.Success("Producto actualizado exitosamente", product))
 }
 
+func (h *Handler) Clone(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID inválido", err.Error()))
+	}
+
+	var req CloneProductRequest
+	if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error al procesar la solicitud", err.Error()))
+	}
+
+	if err := c.Validate(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error de validación", err.Error()))
+	}
+
+	product, err := h.service.Clone(c.Request().Context(), id, &req)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, 
+			response.Success("Producto clonado exitosamente", product))
+}
+
+func (h *Handler) UpdatePublication(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID inválido", err.Error()))
+	}
+
+	var req UpdatePublicationRequest
+	if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error al procesar la solicitud", err.Error()))
+	}
+
+	if err := c.Validate(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error de validación", err.Error()))
+	}
+
+	product, err := h.service.UpdatePublication(c.Request().Context(), id, &req)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Estado de publicación actualizado exitosamente", product))
+}
+
+func (h *Handler) ListRelations(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID inválido", err.Error()))
+	}
+
+	relations, err := h.service.ListRelations(c.Request().Context(), id, c.QueryParam("type"))
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Relaciones obtenidas exitosamente", relations))
+}
+
+func (h *Handler) ReplaceRelations(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID inválido", err.Error()))
+	}
+
+	var req ReplaceRelationsRequest
+	if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error al procesar la solicitud", err.Error()))
+	}
+
+	if err := c.Validate(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error de validación", err.Error()))
+	}
+
+	relations, err := h.service.ReplaceRelations(c.Request().Context(), id, &req)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Relaciones actualizadas exitosamente", relations))
+}
+
+func (h *Handler) DeleteRelation(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID inválido", err.Error()))
+	}
+
+	relatedID, err := strconv.ParseInt(c.Param("relatedId"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID de producto relacionado inválido", err.Error()))
+	}
+
+	if err := h.service.DeleteRelation(c.Request().Context(), id, relatedID, c.QueryParam("type")); err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Relación eliminada exitosamente", nil))
+}
+
 func (h *Handler) Delete(c echo.Context) error {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
@@ -115,11 +280,20 @@ func (h *Handler) Delete(c echo.Context) error {
 }
 
 func (h *Handler) List(c echo.Context) error {
+	return h.list(c, false)
+}
+
+func (h *Handler) StorefrontList(c echo.Context) error {
+	return h.list(c, true)
+}
+
+func (h *Handler) list(c echo.Context, storefront bool) error {
 	p := Pagination{Page: 1, PerPage: 10}
 	if err := c.Bind(&p); err != nil {
 			return c.JSON(http.StatusBadRequest, 
 					response.Error("Error en paginación", err.Error()))
 	}
+	p.Storefront = storefront
 
 	products, total, err := h.service.List(c.Request().Context(), &p)
 	if err != nil {
@@ -137,6 +311,36 @@ func (h *Handler) List(c echo.Context) error {
 }
 
 // Handlers de Variantes
+func (h *Handler) Search(c echo.Context) error {
+	return h.search(c, false)
+}
+
+func (h *Handler) StorefrontSearch(c echo.Context) error {
+	return h.search(c, true)
+}
+
+func (h *Handler) search(c echo.Context, storefront bool) error {
+	req := SearchRequest{Page: 1, PerPage: 10}
+	if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error en los parámetros de búsqueda", err.Error()))
+	}
+	req.Storefront = storefront
+
+	if err := c.Validate(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error de validación", err.Error()))
+	}
+
+	result, err := h.service.Search(c.Request().Context(), &req)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Búsqueda realizada exitosamente", result))
+}
+
 func (h *Handler) CreateVariant(c echo.Context) error {
 	var req CreateVariantRequest
 	if err := c.Bind(&req); err != nil {
@@ -158,6 +362,50 @@ func (h *Handler) CreateVariant(c echo.Context) error {
 			response.Success("Variante creada exitosamente", product))
 }
 
+func (h *Handler) PreviewVariantMatrix(c echo.Context) error {
+	return h.variantMatrix(c, false)
+}
+
+func (h *Handler) GenerateVariantMatrix(c echo.Context) error {
+	return h.variantMatrix(c, true)
+}
+
+func (h *Handler) variantMatrix(c echo.Context, commit bool) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID inválido", err.Error()))
+	}
+
+	var req VariantMatrixRequest
+	if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error al procesar la solicitud", err.Error()))
+	}
+
+	if err := c.Validate(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error de validación", err.Error()))
+	}
+
+	if !commit {
+			result, err := h.service.PreviewVariantMatrix(c.Request().Context(), id, &req)
+			if err != nil {
+					return h.handleError(c, err)
+			}
+			return c.JSON(http.StatusOK, 
+					response.Success("Vista previa de variantes generada exitosamente", result))
+	}
+
+	result, err := h.service.GenerateVariantMatrix(c.Request().Context(), id, &req)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, 
+			response.Success("Variantes generadas exitosamente", result))
+}
+
 func (h *Handler) GetVariant(c echo.Context) error {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
@@ -165,7 +413,7 @@ func (h *Handler) GetVariant(c echo.Context) error {
 					response.Error("ID inválido", err.Error()))
 	}
 
-	variant, err := h.service.GetVariant(c.Request().Context(), id)
+	variant, err := h.service.GetVariant(c.Request().Context(), id, c.QueryParam("currency"))
 	if err != nil {
 			return h.handleError(c, err)
 	}
@@ -216,6 +464,68 @@ func (h *Handler) DeleteVariant(c echo.Context) error {
 			response.Success("Variante eliminada exitosamente", nil))
 }
 
+func (h *Handler) SetBundle(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID inválido", err.Error()))
+	}
+
+	var req SetBundleRequest
+	if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error al procesar la solicitud", err.Error()))
+	}
+
+	if err := c.Validate(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error de validación", err.Error()))
+	}
+
+	variant, err := h.service.SetBundle(c.Request().Context(), id, &req)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Pack actualizado exitosamente", variant))
+}
+
+func (h *Handler) DeleteBundle(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID inválido", err.Error()))
+	}
+
+	if err := h.service.DeleteBundle(c.Request().Context(), id); err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Pack eliminado exitosamente", nil))
+}
+
+func (h *Handler) ConsumeStock(c echo.Context) error {
+	var req ConsumeStockRequest
+	if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error al procesar la solicitud", err.Error()))
+	}
+
+	if err := c.Validate(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error de validación", err.Error()))
+	}
+
+	if err := h.service.ConsumeStock(c.Request().Context(), &req); err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Stock descontado exitosamente", nil))
+}
+
 func (h *Handler) ListVariants(c echo.Context) error {
 	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
@@ -223,7 +533,7 @@ func (h *Handler) ListVariants(c echo.Context) error {
 					response.Error("ID de producto inválido", err.Error()))
 	}
 
-	variants, err := h.service.ListVariants(c.Request().Context(), productID)
+	variants, err := h.service.ListVariants(c.Request().Context(), productID, c.QueryParam("currency"))
 	if err != nil {
 		fmt.Printf("error: %v\n,", err)
 			return h.handleError(c, err)
@@ -233,6 +543,118 @@ func (h *Handler) ListVariants(c echo.Context) error {
 			response.Success("Variantes obtenidas exitosamente", variants))
 }
 
+func (h *Handler) ListVariantMedia(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID inválido", err.Error()))
+	}
+
+	media, err := h.service.ListVariantMedia(c.Request().Context(), id)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Galería obtenida exitosamente", media))
+}
+
+func (h *Handler) AttachVariantMedia(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID inválido", err.Error()))
+	}
+
+	var req AttachVariantMediaRequest
+	if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error al procesar la solicitud", err.Error()))
+	}
+
+	if err := c.Validate(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error de validación", err.Error()))
+	}
+
+	media, err := h.service.AttachVariantMedia(c.Request().Context(), id, &req)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, 
+			response.Success("Archivo añadido a la galería exitosamente", media))
+}
+
+func (h *Handler) ReorderVariantMedia(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID inválido", err.Error()))
+	}
+
+	var req ReorderVariantMediaRequest
+	if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error al procesar la solicitud", err.Error()))
+	}
+
+	if err := c.Validate(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error de validación", err.Error()))
+	}
+
+	media, err := h.service.ReorderVariantMedia(c.Request().Context(), id, &req)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Galería reordenada exitosamente", media))
+}
+
+func (h *Handler) SetPrimaryVariantMedia(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID inválido", err.Error()))
+	}
+	mediaID, err := strconv.ParseInt(c.Param("mediaId"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID de archivo inválido", err.Error()))
+	}
+
+	media, err := h.service.SetPrimaryVariantMedia(c.Request().Context(), id, mediaID)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Imagen principal actualizada exitosamente", media))
+}
+
+func (h *Handler) DetachVariantMedia(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID inválido", err.Error()))
+	}
+	mediaID, err := strconv.ParseInt(c.Param("mediaId"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID de archivo inválido", err.Error()))
+	}
+
+	media, err := h.service.DetachVariantMedia(c.Request().Context(), id, mediaID)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Archivo quitado de la galería exitosamente", media))
+}
+
 func (h *Handler) handleError(c echo.Context, err error) error {
 	switch e := err.(type) {
 	case *errors.AppError:
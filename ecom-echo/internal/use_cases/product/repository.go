@@ -5,8 +5,13 @@ import (
 	"context"
 	"database/sql"
 	"ecom/internal/shared/errors"
+	"ecom/internal/shared/money"
+	"ecom/internal/shared/slug"
+	"ecom/internal/shared/stock"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	at "ecom/internal/use_cases/attributeValue"
 	category "ecom/internal/use_cases/category"
@@ -20,6 +25,27 @@ type Repository interface {
 	Update(ctx context.Context, p *Product) error
 	Delete(ctx context.Context, id int64) error
 	List(ctx context.Context, p *Pagination) ([]Product, int64, error)
+	Search(ctx context.Context, req *SearchRequest) ([]ProductSearchHit, int64, error)
+	ListProductIDs(ctx context.Context, categoryIDs []int64, tagID *int64) ([]int64, error)
+	SearchFacets(ctx context.Context, req *SearchRequest) (*SearchFacets, error)
+
+	// Packs y consumo de stock
+	SaveBundle(ctx context.Context, variantID int64, b *Bundle) error
+	DeleteBundle(ctx context.Context, variantID int64) error
+	BundleVariantIDs(ctx context.Context, ids []int64) ([]int64, error)
+	IsBundleComponent(ctx context.Context, variantID int64) (bool, error)
+	ConsumeStock(ctx context.Context, req *ConsumeStockRequest) error
+
+	// Relaciones entre productos
+	ListRelations(ctx context.Context, productID int64, publishedOnly bool) ([]RelatedProduct, error)
+	ReplaceRelations(ctx context.Context, productID int64, relationType string, relatedIDs []int64) error
+	DeleteRelation(ctx context.Context, productID, relatedID int64, relationType string) error
+	SuggestRelated(ctx context.Context, productID int64, limit int) ([]RelatedProduct, error)
+	ExistingProductIDs(ctx context.Context, ids []int64) ([]int64, error)
+
+	// Programación de publicaciones
+	PublishScheduled(ctx context.Context) ([]int64, error)
+	ArchiveExpired(ctx context.Context) ([]int64, error)
 	
 	// Operaciones de Variantes
 	CreateVariant(ctx context.Context, v *ProductVariant) error
@@ -27,6 +53,18 @@ type Repository interface {
 	DeleteVariant(ctx context.Context, id int64) error
 	GetVariantByID(ctx context.Context, id int64) (*ProductVariant, error)
 	ListVariants(ctx context.Context, productID int64) ([]ProductVariant, error)
+	CreateVariants(ctx context.Context, variants []ProductVariant) error
+	ListSKUsWithPrefix(ctx context.Context, prefix string) ([]string, error)
+	ListSlugsWithPrefix(ctx context.Context, prefix string, excludeID int64) ([]string, error)
+	CloneProduct(ctx context.Context, sourceID int64, p *Product, skus map[int64]string) error
+	GetAttributeValuesByIDs(ctx context.Context, ids []int64) ([]at.AttributeValue, error)
+
+	// Galería de imágenes de las variantes
+	ListVariantMedia(ctx context.Context, variantID int64) ([]VariantMedia, error)
+	AttachVariantMedia(ctx context.Context, variantID, mediaID int64, position *int, primary bool) error
+	DetachVariantMedia(ctx context.Context, variantID, mediaID int64) error
+	ReorderVariantMedia(ctx context.Context, variantID int64, mediaIDs []int64) error
+	SetPrimaryVariantMedia(ctx context.Context, variantID, mediaID int64) error
 	
 	// Métodos auxiliares para relaciones
 	AddProductTags(ctx context.Context, tx *sql.Tx, productID int64, tagIDs []int64) error
@@ -36,6 +74,7 @@ type Repository interface {
 	loadProductTags(ctx context.Context, p *Product) error
 	loadProductVariants(ctx context.Context, p *Product) error
 	loadVariantAttributeValues(ctx context.Context, v *ProductVariant) error
+	loadVariantDetails(ctx context.Context, v *ProductVariant) error
 }
 
 type MySQLRepository struct {
@@ -55,11 +94,12 @@ func (r *MySQLRepository) Create(ctx context.Context, p *Product) error {
 	
 	// Crear producto
 	query := `
-			INSERT INTO products (name, slug, description, status, id_category, created_at, updated_at)
-			VALUES (?, ?, ?, ?, ?, NOW(), NOW())
+			INSERT INTO products (name, slug, description, status, publication_status, publish_at, unpublish_at,
+					id_category, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
 	`
 	result, err := tx.ExecContext(ctx, query,
-			p.Name, p.Slug, p.Description, p.Status, p.IDCategory)
+			p.Name, p.Slug, p.Description, p.Status, p.PublicationStatus, p.PublishAt, p.UnpublishAt, p.IDCategory)
 	if err != nil {
 		tx.Rollback()
 		return errors.NewMysqlError(err)
@@ -95,7 +135,9 @@ func (r *MySQLRepository) Create(ctx context.Context, p *Product) error {
 func (r *MySQLRepository) GetByID(ctx context.Context, id int64) (*Product, error) {
 	// Consulta principal para obtener el producto
 	query := `
-			SELECT p.id, p.name, p.slug, p.description, p.status, p.id_category,
+			SELECT p.id, p.name, p.slug, p.description, p.status,
+							p.publication_status, p.publish_at, p.unpublish_at,
+							p.rating_average, p.rating_count, p.id_category,
 							p.created_at, p.updated_at,
 							c.id, c.name
 			FROM products p
@@ -106,9 +148,12 @@ func (r *MySQLRepository) GetByID(ctx context.Context, id int64) (*Product, erro
 	p := &Product{
 		Category: &category.Category{},
 	}
+	var publishAt, unpublishAt sql.NullTime
 	
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&p.ID, &p.Name, &p.Slug, &p.Description, &p.Status, &p.IDCategory,
+		&p.ID, &p.Name, &p.Slug, &p.Description, &p.Status,
+		&p.PublicationStatus, &publishAt, &unpublishAt,
+		&p.AverageRating, &p.ReviewCount, &p.IDCategory,
 		&p.CreatedAt, &p.UpdatedAt,
 		&p.Category.ID, &p.Category.Name,
 	)
@@ -119,6 +164,7 @@ func (r *MySQLRepository) GetByID(ctx context.Context, id int64) (*Product, erro
 	if err != nil {
 			return nil, err
 	}
+	p.PublishAt, p.UnpublishAt = nullTimePtr(publishAt), nullTimePtr(unpublishAt)
 
 	// Obtener tags
 	tagsQuery := `
@@ -141,94 +187,52 @@ func (r *MySQLRepository) GetByID(ctx context.Context, id int64) (*Product, erro
 			p.Tags = append(p.Tags, tag)
 	}
 
-	// Obtener variantes y sus attribute values
-	variantsQuery := `
-			SELECT v.id, v.name, v.sku, v.stock, v.id_tax_category,
-						v.id_product,	v.created_at, v.updated_at
-			FROM product_variants v
-			WHERE v.id_product = ? AND v.deleted_at IS NULL
-	`
-	variantsRows, err := r.db.QueryContext(ctx, variantsQuery, id)
-	if err != nil {
+	// Variantes con los mismos detalles que el listado: atributos, galería, medidas y packs
+	if err := r.loadProductVariants(ctx, p); err != nil {
 			return nil, err
 	}
-	defer variantsRows.Close()
-
-	for variantsRows.Next() {
-			var variant ProductVariant
-			var idTaxCategory sql.NullInt64
-			err := variantsRows.Scan(
-					&variant.ID, &variant.Name, &variant.SKU, &variant.Stock,
-					&idTaxCategory, &variant.IDProduct, &variant.CreatedAt, &variant.UpdatedAt,
-			)
-			if err != nil {
-					return nil, err
-			}
 
-			if idTaxCategory.Valid {
-				variant.IDTaxCategory = idTaxCategory.Int64
-			} else {
-				variant.IDTaxCategory = 0
-			}
+	return p, nil
+}
 
-			// Obtener attribute values para cada variante
-			attrQuery := `
-					SELECT av.id, av.name, av.id_attribute_product,
-									pa.id, pa.name, pa.type, pa.description
-					FROM attribute_values av
-					INNER JOIN n_product_variant_attribute_values pvav ON pvav.id_attribute_value = av.id
-					INNER JOIN product_attributes pa ON pa.id = av.id_attribute_product
-					WHERE pvav.id_product_variant = ?
-			`
-			attrRows, err := r.db.QueryContext(ctx, attrQuery, variant.ID)
-			if err != nil {
-					return nil, err
-			}
-			defer attrRows.Close()
-
-			for attrRows.Next() {
-					var av at.AttributeValue
-        	av.ProductAttribute = &at.ProductAttributeInfo{}
-					
-					err := attrRows.Scan(
-							&av.ID, &av.Name, &av.IDProductAttribute,
-							&av.ProductAttribute.ID, &av.ProductAttribute.Name,
-							&av.ProductAttribute.Type, &av.ProductAttribute.Description,
-					)
-					if err != nil {
-							return nil, err
-					}
-					
-					variant.AttributeValues = append(variant.AttributeValues, av)
-			}
+// Update guarda el producto; si cambia el slug, el anterior pasa al historial para redirigirlo
+func (r *MySQLRepository) Update(ctx context.Context, p *Product) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+			return errors.NewMysqlError(err)
+	}
+	defer tx.Rollback()
 
-			p.Variants = append(p.Variants, variant)
+	var oldSlug string
+	err = tx.QueryRowContext(ctx, "SELECT slug FROM products WHERE id = ? AND deleted_at IS NULL FOR UPDATE", p.ID).Scan(&oldSlug)
+	if err == sql.ErrNoRows {
+			return errors.NewNotFoundError("Producto no encontrado")
+	}
+	if err != nil {
+			return errors.NewMysqlError(err)
 	}
 
-	return p, nil
-}
+	if err := slug.SaveHistory(ctx, tx, slug.EntityProduct, p.ID, oldSlug, p.Slug); err != nil {
+			return err
+	}
 
-func (r *MySQLRepository) Update(ctx context.Context, p *Product) error {
 	query := `
 			UPDATE products 
 			SET name = ?, slug = ?, description = ?, status = ?, 
+					publication_status = ?, publish_at = ?, unpublish_at = ?,
 					id_category = ?, updated_at = NOW()
 			WHERE id = ? AND deleted_at IS NULL
 	`
-	result, err := r.db.ExecContext(ctx, query,
+	if _, err := tx.ExecContext(ctx, query,
 			p.Name, p.Slug, p.Description, p.Status,
-			p.IDCategory, p.ID)
-	if err != nil {
+			p.PublicationStatus, p.PublishAt, p.UnpublishAt,
+			p.IDCategory, p.ID); err != nil {
 			return errors.NewMysqlError(err)
 	}
 
-	rows, err := result.RowsAffected()
-	if err != nil {
+	if err := tx.Commit(); err != nil {
 			return errors.NewInternalError("Error al actualizar producto", err)
 	}
-	if rows == 0 {
-			return errors.NewNotFoundError("Producto no encontrado")
-	}
 	return nil
 }
 
@@ -257,24 +261,33 @@ func (r *MySQLRepository) List(ctx context.Context, p *Pagination) ([]Product, i
 	// Consulta para contar el total
 	countQuery := `
 			SELECT COUNT(*) 
-			FROM products 
-			WHERE deleted_at IS NULL
+			FROM products p
+			WHERE p.deleted_at IS NULL
 	`
 	args := []interface{}{}
 
 	if p.Search != "" {
-			countQuery += " AND (name LIKE ? OR description LIKE ?)"
+			countQuery += " AND (p.name LIKE ? OR p.description LIKE ?)"
 			searchTerm := "%" + p.Search + "%"
 			args = append(args, searchTerm, searchTerm)
 	}
 
 	if p.Status != nil {
-			countQuery += " AND status = ?"
+			countQuery += " AND p.status = ?"
 			args = append(args, *p.Status)
 	}
 
+	if p.PublicationStatus != "" {
+			countQuery += " AND p.publication_status = ?"
+			args = append(args, p.PublicationStatus)
+	}
+
+	if p.Storefront {
+			countQuery += " AND " + publishedNowCondition
+	}
+
 	if p.IDCategory != nil {
-			countQuery += " AND id_category = ?"
+			countQuery += " AND p.id_category = ?"
 			args = append(args, *p.IDCategory)
 	}
 
@@ -287,6 +300,8 @@ func (r *MySQLRepository) List(ctx context.Context, p *Pagination) ([]Product, i
 	// Consulta principal
 	query := `
 			SELECT DISTINCT p.id, p.name, p.slug, p.description, p.status, 
+							p.publication_status, p.publish_at, p.unpublish_at,
+							p.rating_average, p.rating_count,
 							p.id_category, p.created_at, p.updated_at,
 							c.id, c.name
 			FROM products p
@@ -302,6 +317,14 @@ func (r *MySQLRepository) List(ctx context.Context, p *Pagination) ([]Product, i
 			query += " AND p.status = ?"
 	}
 
+	if p.PublicationStatus != "" {
+			query += " AND p.publication_status = ?"
+	}
+
+	if p.Storefront {
+			query += " AND " + publishedNowCondition
+	}
+
 	if p.IDCategory != nil {
 			query += " AND p.id_category = ?"
 	}
@@ -320,14 +343,18 @@ func (r *MySQLRepository) List(ctx context.Context, p *Pagination) ([]Product, i
 			p := Product{
 					Category: &category.Category{},
 			}
+			var publishAt, unpublishAt sql.NullTime
 			err := rows.Scan(
 					&p.ID, &p.Name, &p.Slug, &p.Description, &p.Status,
+					&p.PublicationStatus, &publishAt, &unpublishAt,
+					&p.AverageRating, &p.ReviewCount,
 					&p.IDCategory, &p.CreatedAt, &p.UpdatedAt,
 					&p.Category.ID, &p.Category.Name,
 			)
 			if err != nil {
 					return nil, 0, err
 			}
+			p.PublishAt, p.UnpublishAt = nullTimePtr(publishAt), nullTimePtr(unpublishAt)
 
 			// Cargar tags
 			if err := r.loadProductTags(ctx, &p); err != nil {
@@ -363,11 +390,11 @@ func (r *MySQLRepository) CreateVariant(ctx context.Context, v *ProductVariant)
 	query := `
 			INSERT INTO product_variants 
 			(name, sku, stock, id_product, id_tax_category, created_at, updated_at)
-			VALUES (?, ?, ?, ?, ?, NOW(), NOW())
+			VALUES (?, ?, 0, ?, ?, NOW(), NOW())
 	`
 	
 	result, err := tx.ExecContext(ctx, query,
-			v.Name, v.SKU, v.Stock, v.IDProduct, idTaxCategory)
+			v.Name, v.SKU, v.IDProduct, idTaxCategory)
 	if err != nil {
 			tx.Rollback()
 			return errors.NewMysqlError(err)
@@ -380,6 +407,19 @@ func (r *MySQLRepository) CreateVariant(ctx context.Context, v *ProductVariant)
 	}
 	v.ID = variantID
 
+	// El stock inicial entra en el libro como recepción en el almacén predeterminado
+	if err := stock.Receive(ctx, tx, variantID, v.Stock, "Stock inicial", stock.ActorSystem); err != nil {
+			tx.Rollback()
+			return err
+	}
+
+	if v.Dimensions != nil {
+			if err := r.saveVariantDimensions(ctx, tx, variantID, v.Dimensions); err != nil {
+					tx.Rollback()
+					return err
+			}
+	}
+
 	// Crear attribute values
 	if len(v.AttributeValues) > 0 {
 			query := `INSERT INTO n_product_variant_attribute_values 
@@ -427,11 +467,11 @@ func (r *MySQLRepository) UpdateVariant(ctx context.Context, v *ProductVariant)
 
 	query := `
 			UPDATE product_variants 
-			SET name = ?, sku = ?, stock = ?, id_tax_category = ?, updated_at = NOW()
+			SET name = ?, sku = ?, id_tax_category = ?, updated_at = NOW()
 			WHERE id = ? AND deleted_at IS NULL
 	`
 	result, err := tx.ExecContext(ctx, query,
-			v.Name, v.SKU, v.Stock, idTaxCategory, v.ID)
+			v.Name, v.SKU, idTaxCategory, v.ID)
 	if err != nil {
 			tx.Rollback()
 			return errors.NewMysqlError(err)
@@ -447,6 +487,13 @@ func (r *MySQLRepository) UpdateVariant(ctx context.Context, v *ProductVariant)
 			return errors.NewNotFoundError("Variante no encontrada")
 	}
 
+	if v.Dimensions != nil {
+			if err := r.saveVariantDimensions(ctx, tx, v.ID, v.Dimensions); err != nil {
+					tx.Rollback()
+					return err
+			}
+	}
+
 	// Si hay nuevos attribute values, primero eliminamos los existentes
 	if len(v.AttributeValues) > 0 {
 		_, err = tx.ExecContext(ctx, 
@@ -539,7 +586,7 @@ func (r *MySQLRepository) AddVariantAttributeValues(ctx context.Context, tx *sql
 
 func (r *MySQLRepository) GetVariantByID(ctx context.Context, id int64) (*ProductVariant, error) {
     query := `
-        SELECT v.id, v.name, v.sku, v.stock, v.id_product, v.id_tax_category,
+        SELECT v.id, v.name, v.sku, v.stock, v.reserved, GREATEST(COALESCE(v.stock, 0) - v.reserved, 0), v.id_product, v.id_tax_category,
                v.created_at, v.updated_at
         FROM product_variants v
         WHERE v.id = ? AND v.deleted_at IS NULL
@@ -548,7 +595,7 @@ func (r *MySQLRepository) GetVariantByID(ctx context.Context, id int64) (*Produc
     variant := &ProductVariant{}
 		var idTaxCategory sql.NullInt64
     err := r.db.QueryRowContext(ctx, query, id).Scan(
-        &variant.ID, &variant.Name, &variant.SKU, &variant.Stock,
+        &variant.ID, &variant.Name, &variant.SKU, &variant.Stock, &variant.Reserved, &variant.Available,
         &variant.IDProduct, &idTaxCategory,
         &variant.CreatedAt, &variant.UpdatedAt,
     )
@@ -570,13 +617,16 @@ func (r *MySQLRepository) GetVariantByID(ctx context.Context, id int64) (*Produc
     if err := r.loadVariantAttributeValues(ctx, variant); err != nil {
 			return nil, err
     }
+    if err := r.loadVariantDetails(ctx, variant); err != nil {
+			return nil, err
+    }
 
     return variant, nil
 }
 
 func (r *MySQLRepository) ListVariants(ctx context.Context, productID int64) ([]ProductVariant, error) {
     query := `
-        SELECT v.id, v.name, v.sku, v.stock, v.id_product, v.id_tax_category, v.id_product,
+        SELECT v.id, v.name, v.sku, v.stock, v.reserved, GREATEST(COALESCE(v.stock, 0) - v.reserved, 0), v.id_product, v.id_tax_category, v.id_product,
                v.created_at, v.updated_at
         FROM product_variants v
         WHERE v.id_product = ? AND v.deleted_at IS NULL
@@ -594,7 +644,7 @@ func (r *MySQLRepository) ListVariants(ctx context.Context, productID int64) ([]
         var v ProductVariant
 				var idTaxCategory sql.NullInt64
         err := rows.Scan(
-            &v.ID, &v.Name, &v.SKU, &v.Stock,
+            &v.ID, &v.Name, &v.SKU, &v.Stock, &v.Reserved, &v.Available,
             &v.IDProduct, &idTaxCategory, &v.IDProduct,
             &v.CreatedAt, &v.UpdatedAt,
         )
@@ -611,6 +661,9 @@ func (r *MySQLRepository) ListVariants(ctx context.Context, productID int64) ([]
         if err := r.loadVariantAttributeValues(ctx, &v); err != nil {
             return nil, err
         }
+        if err := r.loadVariantDetails(ctx, &v); err != nil {
+            return nil, err
+        }
 
         variants = append(variants, v)
     }
@@ -618,98 +671,1492 @@ func (r *MySQLRepository) ListVariants(ctx context.Context, productID int64) ([]
     return variants, nil
 }
 
-func (r *MySQLRepository) loadProductTags(ctx context.Context, p *Product) error {
+// CreateVariants crea varias variantes con sus valores de atributo en una sola transacción
+func (r *MySQLRepository) CreateVariants(ctx context.Context, variants []ProductVariant) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	defer tx.Rollback()
+
 	query := `
-			SELECT t.id, t.name, t.code, t.active
-			FROM tags t
-			INNER JOIN n_products_tags pt ON pt.id_tag = t.id
-			WHERE pt.id_product = ? AND t.deleted_at IS NULL
+		INSERT INTO product_variants
+		(name, sku, stock, id_product, id_tax_category, created_at, updated_at)
+		VALUES (?, ?, 0, ?, ?, NOW(), NOW())
 	`
-	rows, err := r.db.QueryContext(ctx, query, p.ID)
-	if err != nil {
+	for i := range variants {
+		v := &variants[i]
+		idTaxCategory := sql.NullInt64{Int64: v.IDTaxCategory, Valid: v.IDTaxCategory != 0}
+
+		result, err := tx.ExecContext(ctx, query, v.Name, v.SKU, v.IDProduct, idTaxCategory)
+		if err != nil {
+			return errors.NewMysqlError(err)
+		}
+		if v.ID, err = result.LastInsertId(); err != nil {
+			return errors.NewInternalError("Error al obtener el id creado", err)
+		}
+
+		if err := stock.Receive(ctx, tx, v.ID, v.Stock, "Stock inicial", stock.ActorSystem); err != nil {
 			return err
-	}
-	defer rows.Close()
+		}
 
-	for rows.Next() {
-			var tag tag.Tag
-			if err := rows.Scan(&tag.ID, &tag.Name, &tag.Code, &tag.Active); err != nil {
-					return err
+		if len(v.AttributeValues) > 0 {
+			if err := r.AddVariantAttributeValues(ctx, tx, v.ID, v.AttributeValues); err != nil {
+				return err
 			}
-			p.Tags = append(p.Tags, tag)
+		}
+		if v.Dimensions != nil {
+			if err := r.saveVariantDimensions(ctx, tx, v.ID, v.Dimensions); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.NewMysqlError(err)
 	}
 	return nil
 }
 
-func (r *MySQLRepository) loadProductVariants(ctx context.Context, p *Product) error {
-	query := `
-			SELECT v.id, v.name, v.sku, v.stock, v.id_tax_category, v.id_product,
-							v.created_at, v.updated_at
-			FROM product_variants v
-			WHERE v.id_product = ? AND v.deleted_at IS NULL
-	`
-	rows, err := r.db.QueryContext(ctx, query, p.ID)
+// ListSKUsWithPrefix devuelve los SKU que empiezan por el prefijo, incluidos los de variantes
+// eliminadas, que siguen ocupando el índice único
+func (r *MySQLRepository) ListSKUsWithPrefix(ctx context.Context, prefix string) ([]string, error) {
+	return r.listWithPrefix(ctx, "SELECT sku FROM product_variants WHERE sku LIKE ?", prefix)
+}
+
+// ListSlugsWithPrefix devuelve los slugs de productos que empiezan por el prefijo, incluidos los
+// eliminados y los antiguos del historial, salvo los del producto excludeID
+func (r *MySQLRepository) ListSlugsWithPrefix(ctx context.Context, prefix string, excludeID int64) ([]string, error) {
+	return r.listWithPrefix(ctx, `
+		SELECT slug FROM (
+			SELECT slug, id AS entity_id FROM products
+			UNION ALL
+			SELECT slug, entity_id FROM slug_history WHERE entity_type = 'product'
+		) s
+		WHERE slug LIKE ? AND entity_id <> ?
+	`, prefix, excludeID)
+}
+
+func (r *MySQLRepository) listWithPrefix(ctx context.Context, query, prefix string, args ...interface{}) ([]string, error) {
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(prefix)
+	rows, err := r.db.QueryContext(ctx, query, append([]interface{}{escaped + "%"}, args...)...)
 	if err != nil {
-			return err
+		return nil, errors.NewMysqlError(err)
 	}
 	defer rows.Close()
 
+	var values []string
 	for rows.Next() {
-			var v ProductVariant
-			var idTaxCategory sql.NullInt64
-			err := rows.Scan(
-					&v.ID, &v.Name, &v.SKU, &v.Stock,
-					&idTaxCategory, &v.IDProduct, &v.CreatedAt, &v.UpdatedAt,
-			)
-			if err != nil {
-				return err
-			}
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, errors.NewMysqlError(err)
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
 
-			if idTaxCategory.Valid {
-				v.IDTaxCategory = idTaxCategory.Int64
-			} else {
-				v.IDTaxCategory = 0
-			}
+// CloneProduct copia el producto con sus tags y, por cada variante de skus (id origen -> SKU nuevo),
+// la variante con sus valores de atributo, medidas, galería, asignaciones de precio y tramos,
+// todo en una transacción. La copia empieza sin stock. Asigna el id del nuevo producto a p.ID
+func (r *MySQLRepository) CloneProduct(ctx context.Context, sourceID int64, p *Product, skus map[int64]string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	defer tx.Rollback()
 
-			// Cargar attribute values para cada variante
-			if err := r.loadVariantAttributeValues(ctx, &v); err != nil {
-					return err
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO products (name, slug, description, status, publication_status, id_category, created_at, updated_at)
+		SELECT ?, ?, description, ?, ?, id_category, NOW(), NOW()
+		FROM products WHERE id = ? AND deleted_at IS NULL
+	`, p.Name, p.Slug, p.Status, p.PublicationStatus, sourceID)
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	if p.ID, err = result.LastInsertId(); err != nil {
+		return errors.NewInternalError("Error al obtener el id creado", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO n_products_tags (id_tag, id_product)
+		SELECT id_tag, ? FROM n_products_tags WHERE id_product = ?
+	`, p.ID, sourceID); err != nil {
+		return errors.NewMysqlError(err)
+	}
+
+	// Relaciones de la variante: cada consulta recibe (id nuevo, id origen)
+	variantCopies := []string{
+		`INSERT INTO n_product_variant_attribute_values (id_product_variant, id_attribute_value)
+		 SELECT ?, id_attribute_value FROM n_product_variant_attribute_values WHERE id_product_variant = ?`,
+		`INSERT INTO product_dimensions (id_product_variant, length, width, height, weight)
+		 SELECT ?, length, width, height, weight FROM product_dimensions WHERE id_product_variant = ?`,
+		`INSERT INTO n_product_variant_media (id_product_variant, id_media, position, is_primary)
+		 SELECT ?, id_media, position, is_primary FROM n_product_variant_media WHERE id_product_variant = ?`,
+		`INSERT INTO price_tiers (id_price_list, id_product_variant, min_quantity, max_quantity, amount, created_at, updated_at)
+		 SELECT id_price_list, ?, min_quantity, max_quantity, amount, NOW(), NOW()
+		 FROM price_tiers WHERE id_product_variant = ? AND deleted_at IS NULL`,
+	}
+
+	// Orden estable para que los ids de las copias sigan el de las variantes originales
+	sourceIDs := make([]int64, 0, len(skus))
+	for id := range skus {
+		sourceIDs = append(sourceIDs, id)
+	}
+	sort.Slice(sourceIDs, func(i, j int) bool { return sourceIDs[i] < sourceIDs[j] })
+
+	for _, sourceVariantID := range sourceIDs {
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO product_variants (name, sku, stock, id_product, id_tax_category, created_at, updated_at)
+			SELECT name, ?, 0, ?, id_tax_category, NOW(), NOW()
+			FROM product_variants WHERE id = ? AND deleted_at IS NULL
+		`, skus[sourceVariantID], p.ID, sourceVariantID)
+		if err != nil {
+			return errors.NewMysqlError(err)
+		}
+		variantID, err := result.LastInsertId()
+		if err != nil {
+			return errors.NewInternalError("Error al obtener el id creado", err)
+		}
+
+		for _, query := range variantCopies {
+			if _, err := tx.ExecContext(ctx, query, variantID, sourceVariantID); err != nil {
+				return errors.NewMysqlError(err)
 			}
+		}
+		if err := clonePrices(ctx, tx, variantID, sourceVariantID); err != nil {
+			return err
+		}
+	}
 
-			p.Variants = append(p.Variants, v)
+	if err := tx.Commit(); err != nil {
+		return errors.NewMysqlError(err)
 	}
 	return nil
 }
 
-func (r *MySQLRepository) loadVariantAttributeValues(ctx context.Context, v *ProductVariant) error {
+// clonePrices copia cada precio activo y no caducado de la variante origen en una fila nueva de
+// prices (misma lista, importe y vigencia) y la asigna a la copia, de modo que los precios de
+// ambas variantes se puedan editar o borrar por separado. Los históricos no se copian
+func clonePrices(ctx context.Context, tx *sql.Tx, variantID, sourceVariantID int64) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT p.id_price_list, p.amount, p.starts_at, p.ends_at
+		FROM n_product_variant_prices pvp
+		INNER JOIN prices p ON p.id = pvp.id_price
+		WHERE pvp.id_product_variant = ? AND pvp.is_active = TRUE AND p.deleted_at IS NULL
+		  AND (p.ends_at IS NULL OR p.ends_at > NOW())
+		ORDER BY p.id
+	`, sourceVariantID)
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+
+	type sourcePrice struct {
+		listID   int64
+		amount   money.Decimal
+		startsAt time.Time
+		endsAt   sql.NullTime
+	}
+	var prices []sourcePrice
+	for rows.Next() {
+		var sp sourcePrice
+		if err := rows.Scan(&sp.listID, &sp.amount, &sp.startsAt, &sp.endsAt); err != nil {
+			rows.Close()
+			return errors.NewMysqlError(err)
+		}
+		prices = append(prices, sp)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return errors.NewMysqlError(err)
+	}
+
+	for _, sp := range prices {
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO prices (id_price_list, amount, starts_at, ends_at, created_at, updated_at)
+			VALUES (?, ?, ?, ?, NOW(), NOW())
+		`, sp.listID, sp.amount, sp.startsAt, sp.endsAt)
+		if err != nil {
+			return errors.NewMysqlError(err)
+		}
+		priceID, err := result.LastInsertId()
+		if err != nil {
+			return errors.NewInternalError("Error al obtener el id creado", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO n_product_variant_prices (id_product_variant, id_price, is_active, created_at, updated_at)
+			VALUES (?, ?, TRUE, NOW(), NOW())
+		`, variantID, priceID); err != nil {
+			return errors.NewMysqlError(err)
+		}
+	}
+	return nil
+}
+
+// GetAttributeValuesByIDs devuelve los valores de atributo indicados con su atributo
+func (r *MySQLRepository) GetAttributeValuesByIDs(ctx context.Context, ids []int64) ([]at.AttributeValue, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	query := fmt.Sprintf(`
+		SELECT av.id, av.name, av.id_attribute_product,
+		       pa.id, pa.name, pa.type, COALESCE(pa.description, '')
+		FROM attribute_values av
+		INNER JOIN product_attributes pa ON pa.id = av.id_attribute_product
+		WHERE av.id IN (%s) AND pa.deleted_at IS NULL
+	`, placeholders(len(ids)))
+	rows, err := r.db.QueryContext(ctx, query, int64Args(ids)...)
+	if err != nil {
+		return nil, errors.NewMysqlError(err)
+	}
+	defer rows.Close()
+
+	var values []at.AttributeValue
+	for rows.Next() {
+		av := at.AttributeValue{ProductAttribute: &at.ProductAttributeInfo{}}
+		if err := rows.Scan(
+			&av.ID, &av.Name, &av.IDProductAttribute,
+			&av.ProductAttribute.ID, &av.ProductAttribute.Name,
+			&av.ProductAttribute.Type, &av.ProductAttribute.Description,
+		); err != nil {
+			return nil, errors.NewMysqlError(err)
+		}
+		values = append(values, av)
+	}
+	return values, rows.Err()
+}
+
+// saveVariantDimensions guarda las medidas de la variante, ya normalizadas a cm y kg
+func (r *MySQLRepository) saveVariantDimensions(ctx context.Context, tx *sql.Tx, variantID int64, d *Dimensions) error {
 	query := `
-			SELECT av.id, av.name, av.id_attribute_product,
-							pa.id, pa.name, pa.type, pa.description
-			FROM attribute_values av
-			INNER JOIN n_product_variant_attribute_values pvav ON pvav.id_attribute_value = av.id
-			INNER JOIN product_attributes pa ON pa.id = av.id_attribute_product
-			WHERE pvav.id_product_variant = ?
+		INSERT INTO product_dimensions (id_product_variant, length, width, height, weight)
+		VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			length = VALUES(length), width = VALUES(width),
+			height = VALUES(height), weight = VALUES(weight)
 	`
-	rows, err := r.db.QueryContext(ctx, query, v.ID)
+	if _, err := tx.ExecContext(ctx, query, variantID, d.Length, d.Width, d.Height, d.Weight); err != nil {
+		return errors.NewMysqlError(err)
+	}
+	return nil
+}
+
+// loadVariantDetails carga la galería y las medidas de la variante
+func (r *MySQLRepository) loadVariantDetails(ctx context.Context, v *ProductVariant) error {
+	media, err := r.ListVariantMedia(ctx, v.ID)
 	if err != nil {
-			return err
+		return err
+	}
+	v.Media = media
+
+	if err := r.loadBundle(ctx, v); err != nil {
+		return err
+	}
+
+	var length, width, height, weight money.NullDecimal
+	err = r.db.QueryRowContext(ctx, `
+		SELECT length, width, height, weight
+		FROM product_dimensions
+		WHERE id_product_variant = ?
+	`, v.ID).Scan(&length, &width, &height, &weight)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	if length.Valid && width.Valid && height.Valid && weight.Valid {
+		v.Dimensions = newDimensions(length.Decimal, width.Decimal, height.Decimal, weight.Decimal)
+	}
+	return nil
+}
+
+// loadBundle carga la composición del pack; el stock de un pack es el que permiten sus componentes
+func (r *MySQLRepository) loadBundle(ctx context.Context, v *ProductVariant) error {
+	b := &Bundle{}
+	var discountType sql.NullString
+	err := r.db.QueryRowContext(ctx, `
+		SELECT pricing_mode, discount_type, discount_value
+		FROM product_bundles
+		WHERE id_product_variant = ?
+	`, v.ID).Scan(&b.PricingMode, &discountType, &b.DiscountValue)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	b.DiscountType = discountType.String
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT c.id_component_variant, pv.name, pv.sku, c.quantity,
+			CASE WHEN pv.deleted_at IS NULL THEN GREATEST(COALESCE(pv.stock, 0) - pv.reserved, 0) ELSE 0 END
+		FROM product_bundle_components c
+		INNER JOIN product_variants pv ON pv.id = c.id_component_variant
+		WHERE c.id_bundle_variant = ?
+		ORDER BY c.position
+	`, v.ID)
+	if err != nil {
+		return errors.NewMysqlError(err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-			var av at.AttributeValue
-			av.ProductAttribute = &at.ProductAttributeInfo{}
-			
-			err := rows.Scan(
-					&av.ID, &av.Name, &av.IDProductAttribute,
-					&av.ProductAttribute.ID, &av.ProductAttribute.Name,
-					&av.ProductAttribute.Type, &av.ProductAttribute.Description,
-			)
-			if err != nil {
-					return err
-			}
-			
-			v.AttributeValues = append(v.AttributeValues, av)
+		var c BundleComponent
+		if err := rows.Scan(&c.IDProductVariant, &c.Name, &c.SKU, &c.Quantity, &c.Stock); err != nil {
+			return err
+		}
+		b.Components = append(b.Components, c)
+	}
+	if err := rows.Err(); err != nil {
+		return err
 	}
+
+	v.Bundle = b
+	v.Stock = b.AvailableStock()
+	v.Reserved = 0
+	v.Available = v.Stock
 	return nil
 }
+
+// SaveBundle crea o sustituye la composición y el precio del pack
+func (r *MySQLRepository) SaveBundle(ctx context.Context, variantID int64, b *Bundle) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO product_bundles (id_product_variant, pricing_mode, discount_type, discount_value, created_at, updated_at)
+		VALUES (?, ?, NULLIF(?, ''), ?, NOW(), NOW())
+		ON DUPLICATE KEY UPDATE pricing_mode = VALUES(pricing_mode), discount_type = VALUES(discount_type),
+			discount_value = VALUES(discount_value), updated_at = NOW()
+	`, variantID, b.PricingMode, b.DiscountType, b.DiscountValue); err != nil {
+		return errors.NewMysqlError(err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"DELETE FROM product_bundle_components WHERE id_bundle_variant = ?", variantID); err != nil {
+		return errors.NewMysqlError(err)
+	}
+
+	for i, c := range b.Components {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO product_bundle_components (id_bundle_variant, id_component_variant, quantity, position)
+			VALUES (?, ?, ?, ?)
+		`, variantID, c.IDProductVariant, c.Quantity, i); err != nil {
+			return errors.NewMysqlError(err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.NewInternalError("Error al guardar el pack", err)
+	}
+	return nil
+}
+
+// DeleteBundle convierte el pack en una variante normal
+func (r *MySQLRepository) DeleteBundle(ctx context.Context, variantID int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		"DELETE FROM product_bundle_components WHERE id_bundle_variant = ?", variantID); err != nil {
+		return errors.NewMysqlError(err)
+	}
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM product_bundles WHERE id_product_variant = ?", variantID)
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewInternalError("Error al eliminar el pack", err)
+	}
+	if rows == 0 {
+		return errors.NewNotFoundError("La variante no es un pack")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.NewInternalError("Error al eliminar el pack", err)
+	}
+	return nil
+}
+
+// BundleVariantIDs devuelve cuáles de los ids son packs
+func (r *MySQLRepository) BundleVariantIDs(ctx context.Context, ids []int64) ([]int64, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return r.queryIDs(ctx,
+		"SELECT id_product_variant FROM product_bundles WHERE id_product_variant IN ("+placeholders(len(ids))+")",
+		int64Args(ids)...)
+}
+
+// IsBundleComponent indica si la variante forma parte de algún pack
+func (r *MySQLRepository) IsBundleComponent(ctx context.Context, variantID int64) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx,
+		"SELECT EXISTS(SELECT 1 FROM product_bundle_components WHERE id_component_variant = ?)", variantID).Scan(&exists)
+	if err != nil {
+		return false, errors.NewMysqlError(err)
+	}
+	return exists, nil
+}
+
+// ConsumeStock registra en el libro la venta de las líneas en una transacción: los packs descuentan
+// sus componentes y, si alguna variante no tiene stock disponible suficiente, no se descuenta nada.
+// Si el pedido viene de un carrito, las reservas de las líneas vendidas se convierten en la venta
+func (r *MySQLRepository) ConsumeStock(ctx context.Context, req *ConsumeStockRequest) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	defer tx.Rollback()
+
+	units := make(map[int64]int)
+	lines := make([]int64, 0, len(req.Items))
+	for _, line := range req.Items {
+		expanded, err := stock.Expand(ctx, tx, line.IDProductVariant, line.Quantity)
+		if err != nil {
+			return err
+		}
+		for id, quantity := range expanded {
+			units[id] += quantity
+		}
+		lines = append(lines, line.IDProductVariant)
+	}
+
+	if err := stock.Sell(ctx, tx, units, req.IDCart, lines, req.IDWarehouse, req.Actor, req.Reference); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.NewInternalError("Error al descontar stock", err)
+	}
+	return nil
+}
+
+// ListVariantMedia devuelve la galería de la variante ordenada por posición
+func (r *MySQLRepository) ListVariantMedia(ctx context.Context, variantID int64) ([]VariantMedia, error) {
+	query := `
+		SELECT m.id, m.file_name, m.file_path, m.mime_type, vm.position, vm.is_primary
+		FROM n_product_variant_media vm
+		INNER JOIN media m ON m.id = vm.id_media
+		WHERE vm.id_product_variant = ? AND m.deleted_at IS NULL
+		ORDER BY vm.position, m.id
+	`
+	rows, err := r.db.QueryContext(ctx, query, variantID)
+	if err != nil {
+		return nil, errors.NewMysqlError(err)
+	}
+	defer rows.Close()
+
+	var media []VariantMedia
+	for rows.Next() {
+		var m VariantMedia
+		if err := rows.Scan(&m.ID, &m.FileName, &m.FilePath, &m.MimeType, &m.Position, &m.IsPrimary); err != nil {
+			return nil, errors.NewMysqlError(err)
+		}
+		media = append(media, m)
+	}
+	return media, rows.Err()
+}
+
+// AttachVariantMedia añade un media a la galería en la posición indicada (nil = al final).
+// La primera imagen de la galería pasa a ser la principal
+func (r *MySQLRepository) AttachVariantMedia(ctx context.Context, variantID, mediaID int64, position *int, primary bool) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM media WHERE id = ? AND deleted_at IS NULL", mediaID,
+	).Scan(&exists); err != nil {
+		return errors.NewMysqlError(err)
+	}
+	if exists == 0 {
+		return errors.NewNotFoundError("Archivo no encontrado")
+	}
+
+	// Bloquea la galería de la variante mientras se recalculan las posiciones
+	rows, err := tx.QueryContext(ctx,
+		"SELECT id_media FROM n_product_variant_media WHERE id_product_variant = ? FOR UPDATE", variantID)
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	count := 0
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return errors.NewMysqlError(err)
+		}
+		if id == mediaID {
+			rows.Close()
+			return errors.NewConflictError("El archivo ya está en la galería de la variante")
+		}
+		count++
+	}
+	rows.Close()
+
+	pos := count
+	if position != nil && *position < count {
+		pos = *position
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE n_product_variant_media SET position = position + 1
+		WHERE id_product_variant = ? AND position >= ?
+	`, variantID, pos); err != nil {
+		return errors.NewMysqlError(err)
+	}
+
+	if count == 0 {
+		primary = true
+	}
+	if primary {
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE n_product_variant_media SET is_primary = false WHERE id_product_variant = ?", variantID,
+		); err != nil {
+			return errors.NewMysqlError(err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO n_product_variant_media (id_product_variant, id_media, position, is_primary)
+		VALUES (?, ?, ?, ?)
+	`, variantID, mediaID, pos, primary); err != nil {
+		return errors.NewMysqlError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.NewMysqlError(err)
+	}
+	return nil
+}
+
+// DetachVariantMedia quita un media de la galería; si era la principal, la primera restante la sustituye
+func (r *MySQLRepository) DetachVariantMedia(ctx context.Context, variantID, mediaID int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	defer tx.Rollback()
+
+	var position int
+	var primary bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT position, is_primary FROM n_product_variant_media
+		WHERE id_product_variant = ? AND id_media = ? FOR UPDATE
+	`, variantID, mediaID).Scan(&position, &primary)
+	if err == sql.ErrNoRows {
+		return errors.NewNotFoundError("El archivo no está en la galería de la variante")
+	}
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"DELETE FROM n_product_variant_media WHERE id_product_variant = ? AND id_media = ?", variantID, mediaID,
+	); err != nil {
+		return errors.NewMysqlError(err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE n_product_variant_media SET position = position - 1 WHERE id_product_variant = ? AND position > ?", variantID, position,
+	); err != nil {
+		return errors.NewMysqlError(err)
+	}
+	if primary {
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE n_product_variant_media SET is_primary = true WHERE id_product_variant = ? ORDER BY position LIMIT 1", variantID,
+		); err != nil {
+			return errors.NewMysqlError(err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.NewMysqlError(err)
+	}
+	return nil
+}
+
+// ReorderVariantMedia asigna las posiciones según el orden de mediaIDs, que debe contener
+// exactamente los media de la galería
+func (r *MySQLRepository) ReorderVariantMedia(ctx context.Context, variantID int64, mediaIDs []int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx,
+		"SELECT id_media FROM n_product_variant_media WHERE id_product_variant = ? FOR UPDATE", variantID)
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	current := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return errors.NewMysqlError(err)
+		}
+		current[id] = true
+	}
+	rows.Close()
+
+	if len(mediaIDs) != len(current) {
+		return errors.NewBadRequestError("Debe indicar todos los archivos de la galería una sola vez")
+	}
+	for i, id := range mediaIDs {
+		if !current[id] {
+			return errors.NewBadRequestError("Debe indicar todos los archivos de la galería una sola vez")
+		}
+		delete(current, id)
+
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE n_product_variant_media SET position = ? WHERE id_product_variant = ? AND id_media = ?",
+			i, variantID, id,
+		); err != nil {
+			return errors.NewMysqlError(err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.NewMysqlError(err)
+	}
+	return nil
+}
+
+// SetPrimaryVariantMedia marca un media de la galería como imagen principal de la variante
+func (r *MySQLRepository) SetPrimaryVariantMedia(ctx context.Context, variantID, mediaID int64) error {
+	var exists int
+	if err := r.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM n_product_variant_media WHERE id_product_variant = ? AND id_media = ?",
+		variantID, mediaID,
+	).Scan(&exists); err != nil {
+		return errors.NewMysqlError(err)
+	}
+	if exists == 0 {
+		return errors.NewNotFoundError("El archivo no está en la galería de la variante")
+	}
+
+	if _, err := r.db.ExecContext(ctx,
+		"UPDATE n_product_variant_media SET is_primary = (id_media = ?) WHERE id_product_variant = ?",
+		mediaID, variantID,
+	); err != nil {
+		return errors.NewMysqlError(err)
+	}
+	return nil
+}
+
+func (r *MySQLRepository) loadProductTags(ctx context.Context, p *Product) error {
+	query := `
+			SELECT t.id, t.name, t.code, t.active
+			FROM tags t
+			INNER JOIN n_products_tags pt ON pt.id_tag = t.id
+			WHERE pt.id_product = ? AND t.deleted_at IS NULL
+	`
+	rows, err := r.db.QueryContext(ctx, query, p.ID)
+	if err != nil {
+			return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+			var tag tag.Tag
+			if err := rows.Scan(&tag.ID, &tag.Name, &tag.Code, &tag.Active); err != nil {
+					return err
+			}
+			p.Tags = append(p.Tags, tag)
+	}
+	return nil
+}
+
+func (r *MySQLRepository) loadProductVariants(ctx context.Context, p *Product) error {
+	query := `
+			SELECT v.id, v.name, v.sku, v.stock, v.reserved, GREATEST(COALESCE(v.stock, 0) - v.reserved, 0), v.id_tax_category, v.id_product,
+							v.created_at, v.updated_at
+			FROM product_variants v
+			WHERE v.id_product = ? AND v.deleted_at IS NULL
+	`
+	rows, err := r.db.QueryContext(ctx, query, p.ID)
+	if err != nil {
+			return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+			var v ProductVariant
+			var idTaxCategory sql.NullInt64
+			err := rows.Scan(
+					&v.ID, &v.Name, &v.SKU, &v.Stock, &v.Reserved, &v.Available,
+					&idTaxCategory, &v.IDProduct, &v.CreatedAt, &v.UpdatedAt,
+			)
+			if err != nil {
+				return err
+			}
+
+			if idTaxCategory.Valid {
+				v.IDTaxCategory = idTaxCategory.Int64
+			} else {
+				v.IDTaxCategory = 0
+			}
+
+			// Cargar attribute values para cada variante
+			if err := r.loadVariantAttributeValues(ctx, &v); err != nil {
+					return err
+			}
+			if err := r.loadVariantDetails(ctx, &v); err != nil {
+					return err
+			}
+
+			p.Variants = append(p.Variants, v)
+	}
+	return nil
+}
+
+func (r *MySQLRepository) loadVariantAttributeValues(ctx context.Context, v *ProductVariant) error {
+	query := `
+			SELECT av.id, av.name, av.id_attribute_product,
+							pa.id, pa.name, pa.type, pa.description
+			FROM attribute_values av
+			INNER JOIN n_product_variant_attribute_values pvav ON pvav.id_attribute_value = av.id
+			INNER JOIN product_attributes pa ON pa.id = av.id_attribute_product
+			WHERE pvav.id_product_variant = ?
+	`
+	rows, err := r.db.QueryContext(ctx, query, v.ID)
+	if err != nil {
+			return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+			var av at.AttributeValue
+			av.ProductAttribute = &at.ProductAttributeInfo{}
+			
+			err := rows.Scan(
+					&av.ID, &av.Name, &av.IDProductAttribute,
+					&av.ProductAttribute.ID, &av.ProductAttribute.Name,
+					&av.ProductAttribute.Type, &av.ProductAttribute.Description,
+			)
+			if err != nil {
+					return err
+			}
+			
+			v.AttributeValues = append(v.AttributeValues, av)
+	}
+	return nil
+}
+
+// ListProductIDs devuelve los ids de los productos no eliminados, opcionalmente de unas
+// categorías o con un tag
+func (r *MySQLRepository) ListProductIDs(ctx context.Context, categoryIDs []int64, tagID *int64) ([]int64, error) {
+	query := "SELECT p.id FROM products p WHERE p.deleted_at IS NULL"
+	var args []interface{}
+
+	if len(categoryIDs) > 0 {
+		query += " AND p.id_category IN (" + placeholders(len(categoryIDs)) + ")"
+		args = append(args, int64Args(categoryIDs)...)
+	}
+	if tagID != nil {
+		query += " AND EXISTS(SELECT 1 FROM n_products_tags pt WHERE pt.id_product = p.id AND pt.id_tag = ?)"
+		args = append(args, *tagID)
+	}
+	query += " ORDER BY p.id"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.NewMysqlError(err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Precio vigente de cada variante en la lista por defecto, usado por los filtros y el orden por precio
+const activeDefaultPricesQuery = `
+			SELECT v.id_product, v.id AS id_product_variant, pr.amount
+			FROM product_variants v
+			JOIN n_product_variant_prices pvp ON pvp.id_product_variant = v.id AND pvp.is_active = true
+			JOIN prices pr ON pr.id = pvp.id_price
+			JOIN price_lists pl ON pl.id = pr.id_price_list
+			WHERE v.deleted_at IS NULL
+			AND pr.deleted_at IS NULL
+			AND pl.deleted_at IS NULL
+			AND pl.is_default = true
+			AND pl.status = true
+			AND pr.starts_at <= NOW()
+			AND (pr.ends_at IS NULL OR pr.ends_at > NOW())
+`
+
+// searchCondition es un filtro de la búsqueda. Los filtros con faceta se omiten al
+// contar esa misma faceta, para que el resto de sus valores sigan mostrando resultados
+type searchCondition struct {
+	facet       string
+	attributeID int64 // Atributo filtrado, en las condiciones de faceta de atributo
+	sql         string
+	args        []interface{}
+}
+
+const (
+	facetCategory  = "category"
+	facetTag       = "tag"
+	facetAttribute = "attribute:"
+)
+
+func (r *MySQLRepository) searchConditions(ctx context.Context, req *SearchRequest) ([]searchCondition, error) {
+	conditions := []searchCondition{{sql: "p.deleted_at IS NULL"}}
+
+	if req.Query != "" {
+		conditions = append(conditions, searchCondition{
+			sql: `(MATCH(p.name, p.description) AGAINST (? IN NATURAL LANGUAGE MODE)
+					OR p.name LIKE ?
+					OR EXISTS(
+						SELECT 1 FROM product_variants sv
+						WHERE sv.id_product = p.id AND sv.deleted_at IS NULL
+						AND (MATCH(sv.sku) AGAINST (? IN NATURAL LANGUAGE MODE) OR sv.sku = ?)
+					))`,
+			args: []interface{}{req.Query, "%" + req.Query + "%", req.Query, req.Query},
+		})
+	}
+
+	if req.Status != nil {
+		conditions = append(conditions, searchCondition{sql: "p.status = ?", args: []interface{}{*req.Status}})
+	}
+
+	if req.PublicationStatus != "" {
+		conditions = append(conditions, searchCondition{sql: "p.publication_status = ?", args: []interface{}{req.PublicationStatus}})
+	}
+
+	if req.Storefront {
+		conditions = append(conditions, searchCondition{sql: publishedNowCondition})
+	}
+
+	if len(req.CategoryIDs) > 0 {
+		conditions = append(conditions, searchCondition{
+			facet: facetCategory,
+			sql:   "p.id_category IN (" + placeholders(len(req.CategoryIDs)) + ")",
+			args:  int64Args(req.CategoryIDs),
+		})
+	}
+
+	if len(req.TagIDs) > 0 {
+		conditions = append(conditions, searchCondition{
+			facet: facetTag,
+			sql: `EXISTS(
+					SELECT 1 FROM n_products_tags spt
+					WHERE spt.id_product = p.id AND spt.id_tag IN (` + placeholders(len(req.TagIDs)) + `)
+				)`,
+			args: int64Args(req.TagIDs),
+		})
+	}
+
+	if len(req.AttributeValueIDs) > 0 {
+		groups, order, err := r.groupAttributeValues(ctx, req.AttributeValueIDs)
+		if err != nil {
+			return nil, err
+		}
+		// Un EXISTS por atributo: alguna variante debe tener uno de los valores pedidos
+		for _, attributeID := range order {
+			valueIDs := groups[attributeID]
+			conditions = append(conditions, searchCondition{
+				facet:       fmt.Sprintf("%s%d", facetAttribute, attributeID),
+				attributeID: attributeID,
+				sql: `EXISTS(
+						SELECT 1 FROM product_variants av_v
+						JOIN n_product_variant_attribute_values av_n ON av_n.id_product_variant = av_v.id
+						WHERE av_v.id_product = p.id AND av_v.deleted_at IS NULL
+						AND av_n.id_attribute_value IN (` + placeholders(len(valueIDs)) + `)
+					)`,
+				args: int64Args(valueIDs),
+			})
+		}
+	}
+
+	if req.MinPrice != nil || req.MaxPrice != nil {
+		priceSQL := "EXISTS(SELECT 1 FROM (" + activeDefaultPricesQuery + ") fp WHERE fp.id_product = p.id"
+		var args []interface{}
+		if req.MinPrice != nil {
+			priceSQL += " AND fp.amount >= ?"
+			args = append(args, *req.MinPrice)
+		}
+		if req.MaxPrice != nil {
+			priceSQL += " AND fp.amount <= ?"
+			args = append(args, *req.MaxPrice)
+		}
+		conditions = append(conditions, searchCondition{sql: priceSQL + ")", args: args})
+	}
+
+	if req.InStock != nil {
+		stockSQL := `EXISTS(
+				SELECT 1 FROM product_variants sv
+				WHERE sv.id_product = p.id AND sv.deleted_at IS NULL AND ` + availableStockSQL("sv") + ` > 0
+			)`
+		if !*req.InStock {
+			stockSQL = "NOT " + stockSQL
+		}
+		conditions = append(conditions, searchCondition{sql: stockSQL})
+	}
+
+	return conditions, nil
+}
+
+// availableStockSQL unidades disponibles de la variante con el alias dado. Un pack no tiene stock
+// propio: son los packs completos que se pueden montar con lo disponible de sus componentes,
+// igual que calcula Bundle.AvailableStock
+func availableStockSQL(alias string) string {
+	return fmt.Sprintf(`(CASE WHEN EXISTS(SELECT 1 FROM product_bundles ab WHERE ab.id_product_variant = %[1]s.id)
+			THEN COALESCE((
+				SELECT MIN(CASE WHEN cv.deleted_at IS NULL
+					THEN FLOOR(GREATEST(COALESCE(cv.stock, 0) - cv.reserved, 0) / bc.quantity) ELSE 0 END)
+				FROM product_bundle_components bc
+				JOIN product_variants cv ON cv.id = bc.id_component_variant
+				WHERE bc.id_bundle_variant = %[1]s.id
+			), 0)
+			ELSE GREATEST(COALESCE(%[1]s.stock, 0) - %[1]s.reserved, 0) END)`, alias)
+}
+
+// groupAttributeValues agrupa los valores pedidos por su atributo, en el orden en que aparecen
+func (r *MySQLRepository) groupAttributeValues(ctx context.Context, valueIDs []int64) (map[int64][]int64, []int64, error) {
+	query := `
+			SELECT id, id_attribute_product
+			FROM attribute_values
+			WHERE id IN (` + placeholders(len(valueIDs)) + `)
+	`
+	rows, err := r.db.QueryContext(ctx, query, int64Args(valueIDs)...)
+	if err != nil {
+		return nil, nil, errors.NewMysqlError(err)
+	}
+	defer rows.Close()
+
+	attributeOf := make(map[int64]int64)
+	for rows.Next() {
+		var valueID, attributeID int64
+		if err := rows.Scan(&valueID, &attributeID); err != nil {
+			return nil, nil, err
+		}
+		attributeOf[valueID] = attributeID
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	groups := make(map[int64][]int64)
+	var order []int64
+	for _, valueID := range valueIDs {
+		attributeID, ok := attributeOf[valueID]
+		if !ok {
+			return nil, nil, errors.NewBadRequestError(fmt.Sprintf("Valor de atributo %d no encontrado", valueID))
+		}
+		if _, seen := groups[attributeID]; !seen {
+			order = append(order, attributeID)
+		}
+		groups[attributeID] = append(groups[attributeID], valueID)
+	}
+	return groups, order, nil
+}
+
+// whereClause une las condiciones omitiendo las de la faceta indicada
+func whereClause(conditions []searchCondition, excludeFacet string) (string, []interface{}) {
+	var parts []string
+	var args []interface{}
+	for _, c := range conditions {
+		if excludeFacet != "" && c.facet == excludeFacet {
+			continue
+		}
+		parts = append(parts, c.sql)
+		args = append(args, c.args...)
+	}
+	return " WHERE " + strings.Join(parts, " AND "), args
+}
+
+func (r *MySQLRepository) Search(ctx context.Context, req *SearchRequest) ([]ProductSearchHit, int64, error) {
+	conditions, err := r.searchConditions(ctx, req)
+	if err != nil {
+		return nil, 0, err
+	}
+	where, whereArgs := whereClause(conditions, "")
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM products p"+where, whereArgs...).Scan(&total); err != nil {
+		return nil, 0, errors.NewMysqlError(err)
+	}
+
+	relevance := "0"
+	var args []interface{}
+	if req.Query != "" {
+		// Relevancia del texto más un extra si alguna variante tiene exactamente ese SKU
+		relevance = `(MATCH(p.name, p.description) AGAINST (? IN NATURAL LANGUAGE MODE)
+				+ COALESCE((
+					SELECT MAX(MATCH(rv.sku) AGAINST (? IN NATURAL LANGUAGE MODE))
+					FROM product_variants rv
+					WHERE rv.id_product = p.id AND rv.deleted_at IS NULL
+				), 0)
+				+ IF(EXISTS(
+					SELECT 1 FROM product_variants rv
+					WHERE rv.id_product = p.id AND rv.deleted_at IS NULL AND rv.sku = ?
+				), 10, 0))`
+		args = append(args, req.Query, req.Query, req.Query)
+	}
+
+	query := `
+			SELECT p.id, p.name, p.slug, p.description, p.status,
+							p.publication_status, p.publish_at, p.unpublish_at,
+							p.rating_average, p.rating_count, p.id_category,
+							p.created_at, p.updated_at,
+							c.id, c.name,
+							ap.min_amount, ap.max_amount, COALESCE(st.stock, 0),
+							` + relevance + ` AS relevance
+			FROM products p
+			LEFT JOIN categories c ON c.id = p.id_category
+			LEFT JOIN (
+					SELECT id_product, MIN(amount) AS min_amount, MAX(amount) AS max_amount
+					FROM (` + activeDefaultPricesQuery + `) dp
+					GROUP BY id_product
+			) ap ON ap.id_product = p.id
+			LEFT JOIN (
+					SELECT sv.id_product, SUM(` + availableStockSQL("sv") + `) AS stock
+					FROM product_variants sv
+					WHERE sv.deleted_at IS NULL
+					GROUP BY sv.id_product
+			) st ON st.id_product = p.id
+	` + where + " ORDER BY " + searchOrderBy(req.Sort) + " LIMIT ? OFFSET ?"
+
+	args = append(args, whereArgs...)
+	args = append(args, req.PerPage, (req.Page-1)*req.PerPage)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, errors.NewMysqlError(err)
+	}
+	defer rows.Close()
+
+	var hits []ProductSearchHit
+	for rows.Next() {
+		var hit ProductSearchHit
+		var idCategory, categoryID sql.NullInt64
+		var categoryName sql.NullString
+		var minPrice, maxPrice money.NullDecimal
+		var publishAt, unpublishAt sql.NullTime
+
+		err := rows.Scan(
+			&hit.ID, &hit.Name, &hit.Slug, &hit.Description, &hit.Status,
+			&hit.PublicationStatus, &publishAt, &unpublishAt,
+			&hit.AverageRating, &hit.ReviewCount, &idCategory,
+			&hit.CreatedAt, &hit.UpdatedAt,
+			&categoryID, &categoryName,
+			&minPrice, &maxPrice, &hit.Stock,
+			&hit.Relevance,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		hit.IDCategory = idCategory.Int64
+		hit.PublishAt, hit.UnpublishAt = nullTimePtr(publishAt), nullTimePtr(unpublishAt)
+		if categoryID.Valid {
+			hit.Category = &category.Category{ID: categoryID.Int64, Name: categoryName.String}
+		}
+		if minPrice.Valid {
+			hit.MinPrice = &minPrice.Decimal
+			hit.MaxPrice = &maxPrice.Decimal
+		}
+		hits = append(hits, hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	rows.Close()
+
+	for i := range hits {
+		if err := r.loadProductTags(ctx, &hits[i].Product); err != nil {
+			return nil, 0, err
+		}
+		if err := r.loadProductVariants(ctx, &hits[i].Product); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return hits, total, nil
+}
+
+func searchOrderBy(order string) string {
+	switch order {
+	case SortRelevance:
+		return "relevance DESC, p.created_at DESC"
+	case SortNameAsc:
+		return "p.name ASC, p.id ASC"
+	case SortNameDesc:
+		return "p.name DESC, p.id DESC"
+	case SortPriceAsc:
+		return "ap.min_amount IS NULL, ap.min_amount ASC, p.id ASC"
+	case SortPriceDesc:
+		return "ap.min_amount IS NULL, ap.min_amount DESC, p.id DESC"
+	default:
+		return "p.created_at DESC, p.id DESC"
+	}
+}
+
+// SearchFacets cuenta los productos por categoría, tag y valor de atributo. Cada faceta se
+// cuenta con todos los filtros salvo el suyo propio
+func (r *MySQLRepository) SearchFacets(ctx context.Context, req *SearchRequest) (*SearchFacets, error) {
+	conditions, err := r.searchConditions(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	facets := &SearchFacets{
+		Categories: []FacetCount{},
+		Tags:       []FacetCount{},
+		Attributes: []AttributeFacet{},
+	}
+
+	where, args := whereClause(conditions, facetCategory)
+	facets.Categories, err = r.queryFacetCounts(ctx, `
+			SELECT c.id, c.name, COUNT(DISTINCT p.id) AS total
+			FROM products p
+			JOIN categories c ON c.id = p.id_category AND c.deleted_at IS NULL
+	`+where+" GROUP BY c.id, c.name ORDER BY total DESC, c.name ASC", args)
+	if err != nil {
+		return nil, err
+	}
+
+	where, args = whereClause(conditions, facetTag)
+	facets.Tags, err = r.queryFacetCounts(ctx, `
+			SELECT t.id, t.name, COUNT(DISTINCT p.id) AS total
+			FROM products p
+			JOIN n_products_tags pt ON pt.id_product = p.id
+			JOIN tags t ON t.id = pt.id_tag AND t.deleted_at IS NULL
+	`+where+" GROUP BY t.id, t.name ORDER BY total DESC, t.name ASC", args)
+	if err != nil {
+		return nil, err
+	}
+
+	// Atributos sin filtro con todas las condiciones; cada atributo filtrado, sin la suya
+	var filtered []int64
+	for _, c := range conditions {
+		if c.attributeID != 0 {
+			filtered = append(filtered, c.attributeID)
+		}
+	}
+
+	attributeQuery := `
+			SELECT pa.id, pa.name, av.id, av.name, COUNT(DISTINCT p.id) AS total
+			FROM products p
+			JOIN product_variants v ON v.id_product = p.id AND v.deleted_at IS NULL
+			JOIN n_product_variant_attribute_values pvav ON pvav.id_product_variant = v.id
+			JOIN attribute_values av ON av.id = pvav.id_attribute_value
+			JOIN product_attributes pa ON pa.id = av.id_attribute_product AND pa.deleted_at IS NULL
+	`
+	groupBy := " GROUP BY pa.id, pa.name, av.id, av.name ORDER BY pa.name ASC, av.name ASC"
+
+	where, args = whereClause(conditions, "")
+	if len(filtered) > 0 {
+		where += " AND pa.id NOT IN (" + placeholders(len(filtered)) + ")"
+		args = append(args, int64Args(filtered)...)
+	}
+	if err := r.appendAttributeFacets(ctx, facets, attributeQuery+where+groupBy, args); err != nil {
+		return nil, err
+	}
+
+	for _, attributeID := range filtered {
+		where, args = whereClause(conditions, fmt.Sprintf("%s%d", facetAttribute, attributeID))
+		where += " AND pa.id = ?"
+		args = append(args, attributeID)
+		if err := r.appendAttributeFacets(ctx, facets, attributeQuery+where+groupBy, args); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(facets.Attributes, func(i, j int) bool {
+		return facets.Attributes[i].Name < facets.Attributes[j].Name
+	})
+
+	return facets, nil
+}
+
+func (r *MySQLRepository) queryFacetCounts(ctx context.Context, query string, args []interface{}) ([]FacetCount, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.NewMysqlError(err)
+	}
+	defer rows.Close()
+
+	counts := []FacetCount{}
+	for rows.Next() {
+		var f FacetCount
+		if err := rows.Scan(&f.ID, &f.Name, &f.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, f)
+	}
+	return counts, rows.Err()
+}
+
+func (r *MySQLRepository) appendAttributeFacets(ctx context.Context, facets *SearchFacets, query string, args []interface{}) error {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	defer rows.Close()
+
+	index := make(map[int64]int)
+	for rows.Next() {
+		var attributeID int64
+		var attributeName string
+		var value FacetCount
+		if err := rows.Scan(&attributeID, &attributeName, &value.ID, &value.Name, &value.Count); err != nil {
+			return err
+		}
+
+		i, ok := index[attributeID]
+		if !ok {
+			facets.Attributes = append(facets.Attributes, AttributeFacet{ID: attributeID, Name: attributeName, Values: []FacetCount{}})
+			i = len(facets.Attributes) - 1
+			index[attributeID] = i
+		}
+		facets.Attributes[i].Values = append(facets.Attributes[i].Values, value)
+	}
+	return rows.Err()
+}
+
+// ListRelations devuelve las relaciones curadas del producto ordenadas por tipo y posición,
+// sin productos eliminados. Con publishedOnly, sólo los publicados en este momento
+func (r *MySQLRepository) ListRelations(ctx context.Context, productID int64, publishedOnly bool) ([]RelatedProduct, error) {
+	query := `
+		SELECT p.id, p.name, p.slug, pr.type, pr.position
+		FROM product_relations pr
+		INNER JOIN products p ON p.id = pr.id_related_product AND p.deleted_at IS NULL
+		WHERE pr.id_product = ?
+	`
+	if publishedOnly {
+		query += " AND " + publishedNowCondition
+	}
+	query += " ORDER BY FIELD(pr.type, 'related', 'cross_sell', 'up_sell', 'accessory'), pr.position"
+
+	return r.queryRelated(ctx, query, productID)
+}
+
+// ReplaceRelations sustituye las relaciones de un tipo por relatedIDs, en ese orden
+func (r *MySQLRepository) ReplaceRelations(ctx context.Context, productID int64, relationType string, relatedIDs []int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		"DELETE FROM product_relations WHERE id_product = ? AND type = ?", productID, relationType); err != nil {
+		return errors.NewMysqlError(err)
+	}
+
+	for i, relatedID := range relatedIDs {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO product_relations (id_product, id_related_product, type, position, created_at)
+			VALUES (?, ?, ?, ?, NOW())
+		`, productID, relatedID, relationType, i); err != nil {
+			return errors.NewMysqlError(err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.NewInternalError("Error al guardar las relaciones del producto", err)
+	}
+	return nil
+}
+
+// DeleteRelation elimina una relación; con relationType vacío, la de todos los tipos
+func (r *MySQLRepository) DeleteRelation(ctx context.Context, productID, relatedID int64, relationType string) error {
+	query := "DELETE FROM product_relations WHERE id_product = ? AND id_related_product = ?"
+	args := []interface{}{productID, relatedID}
+	if relationType != "" {
+		query += " AND type = ?"
+		args = append(args, relationType)
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewInternalError("Error al eliminar la relación", err)
+	}
+	if rows == 0 {
+		return errors.NewNotFoundError("Relación no encontrada")
+	}
+	return nil
+}
+
+// SuggestRelated sugiere productos publicados de la misma categoría o con tags en común,
+// primero los que comparten más tags y después los de la misma categoría
+func (r *MySQLRepository) SuggestRelated(ctx context.Context, productID int64, limit int) ([]RelatedProduct, error) {
+	query := `
+		SELECT p.id, p.name, p.slug, 'related', 0
+		FROM products p
+		INNER JOIN products src ON src.id = ?
+		LEFT JOIN (
+			SELECT pt.id_product, COUNT(*) AS shared
+			FROM n_products_tags pt
+			INNER JOIN n_products_tags spt ON spt.id_tag = pt.id_tag AND spt.id_product = ?
+			GROUP BY pt.id_product
+		) st ON st.id_product = p.id
+		WHERE p.id <> src.id AND p.deleted_at IS NULL AND ` + publishedNowCondition + `
+			AND (st.shared > 0 OR p.id_category = src.id_category)
+		ORDER BY COALESCE(st.shared, 0) DESC, p.id_category = src.id_category DESC, p.created_at DESC
+		LIMIT ?
+	`
+	related, err := r.queryRelated(ctx, query, productID, productID, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range related {
+		related[i].Position = i
+		related[i].Automatic = true
+	}
+	return related, nil
+}
+
+// ExistingProductIDs devuelve cuáles de los ids son productos no eliminados
+func (r *MySQLRepository) ExistingProductIDs(ctx context.Context, ids []int64) ([]int64, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	return r.queryIDs(ctx,
+		"SELECT id FROM products WHERE deleted_at IS NULL AND id IN ("+placeholders(len(ids))+")", int64Args(ids)...)
+}
+
+func (r *MySQLRepository) queryIDs(ctx context.Context, query string, args ...interface{}) ([]int64, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.NewMysqlError(err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *MySQLRepository) queryRelated(ctx context.Context, query string, args ...interface{}) ([]RelatedProduct, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.NewMysqlError(err)
+	}
+	defer rows.Close()
+
+	related := []RelatedProduct{}
+	for rows.Next() {
+		var rp RelatedProduct
+		if err := rows.Scan(&rp.ID, &rp.Name, &rp.Slug, &rp.Type, &rp.Position); err != nil {
+			return nil, err
+		}
+		related = append(related, rp)
+	}
+	return related, rows.Err()
+}
+
+// publishedNowCondition productos visibles en la tienda en este momento
+const publishedNowCondition = `(p.publication_status = 'published'
+		AND (p.publish_at IS NULL OR p.publish_at <= NOW())
+		AND (p.unpublish_at IS NULL OR p.unpublish_at > NOW()))`
+
+// PublishScheduled publica los productos programados cuya fecha de publicación ya llegó
+func (r *MySQLRepository) PublishScheduled(ctx context.Context) ([]int64, error) {
+	return r.transitionDue(ctx,
+		"publication_status = 'scheduled' AND publish_at <= NOW()",
+		"publication_status = 'published', status = true",
+	)
+}
+
+// ArchiveExpired archiva los productos publicados cuya fecha de retirada ya pasó
+func (r *MySQLRepository) ArchiveExpired(ctx context.Context) ([]int64, error) {
+	return r.transitionDue(ctx,
+		"publication_status = 'published' AND unpublish_at <= NOW()",
+		"publication_status = 'archived', status = false",
+	)
+}
+
+// transitionDue aplica set a los productos que cumplen due y devuelve sus ids
+func (r *MySQLRepository) transitionDue(ctx context.Context, due, set string) ([]int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, "SELECT id FROM products WHERE deleted_at IS NULL AND "+due+" FOR UPDATE")
+	if err != nil {
+		return nil, errors.NewMysqlError(err)
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	query := "UPDATE products SET " + set + ", updated_at = NOW() WHERE id IN (" + placeholders(len(ids)) + ")"
+	if _, err := tx.ExecContext(ctx, query, int64Args(ids)...); err != nil {
+		return nil, errors.NewMysqlError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+func int64Args(ids []int64) []interface{} {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return args
+}
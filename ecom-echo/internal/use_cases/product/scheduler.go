@@ -0,0 +1,53 @@
+// scheduler.go
+package product
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// PublicationScheduler publica y retira periódicamente los productos según sus fechas programadas
+type PublicationScheduler struct {
+	service  *Service
+	interval time.Duration
+}
+
+func NewPublicationScheduler(service *Service, interval time.Duration) *PublicationScheduler {
+	return &PublicationScheduler{service: service, interval: interval}
+}
+
+// Start lanza la revisión en segundo plano hasta que se cancele el contexto.
+// La primera revisión se hace al arrancar
+func (s *PublicationScheduler) Start(ctx context.Context) {
+	if s.interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			s.process(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (s *PublicationScheduler) process(ctx context.Context) {
+	published, archived, err := s.service.ProcessPublicationSchedule(ctx)
+	if err != nil {
+		log.Printf("Error al procesar las publicaciones programadas: %v", err)
+		return
+	}
+
+	if published > 0 || archived > 0 {
+		log.Printf("Publicaciones programadas: %d productos publicados, %d archivados", published, archived)
+	}
+}
@@ -4,35 +4,73 @@ package product
 import (
 	"context"
 	"ecom/internal/shared/errors"
+	"ecom/internal/shared/money"
+	"ecom/internal/shared/slug"
 	"fmt"
 	"hash/fnv"
+	"sort"
 	"strings"
 	"time"
 	"unicode"
 
 	at "ecom/internal/use_cases/attributeValue"
+	currency "ecom/internal/use_cases/currencies"
+	"ecom/internal/use_cases/prices"
 	tag "ecom/internal/use_cases/tag"
 	taxcategory "ecom/internal/use_cases/taxes/taxCategory"
 )
 
+// ChangeListener recibe los cambios de productos y variantes (por ejemplo, el índice de búsqueda)
+type ChangeListener interface {
+	ProductChanged(ctx context.Context, id int64)
+	ProductDeleted(ctx context.Context, id int64)
+}
+
 type Service struct {
 	repo Repository
 	taxCategoryService *taxcategory.Service
+	pricesService *prices.Service
+	currencyService *currency.Service
+	slugs *slug.Namespace
+	listener ChangeListener
 }
 
-func NewService(repo Repository, taxCategoryService *taxcategory.Service) *Service {
+// NewService crea el servicio; listener puede ser nil
+func NewService(repo Repository, taxCategoryService *taxcategory.Service, pricesService *prices.Service, currencyService *currency.Service, slugs *slug.Namespace, listener ChangeListener) *Service {
 	return &Service{
 		repo: repo,
 		taxCategoryService: taxCategoryService,
+		pricesService: pricesService,
+		currencyService: currencyService,
+		slugs: slugs,
+		listener: listener,
+	}
+}
+
+func (s *Service) notifyChanged(ctx context.Context, id int64) {
+	if s.listener != nil {
+		s.listener.ProductChanged(ctx, id)
 	}
 }
 
 func (s *Service) Create(ctx context.Context, req *CreateProductRequest) (*ProductResponse, error) {
+	productSlug := req.Slug
+	if productSlug == "" {
+		generated, err := s.generateSlug(ctx, req.Name, 0)
+		if err != nil {
+			return nil, err
+		}
+		productSlug = generated
+	} else if err := s.checkSlugAvailable(ctx, productSlug, 0); err != nil {
+		return nil, err
+	}
+
 	product := &Product{
 		Name:        req.Name,
-		Slug:        req.Slug,
+		Slug:        productSlug,
 		Description: req.Description,
 		Status:      req.Status,
+		PublicationStatus: legacyPublicationStatus(req.Status),
 		IDCategory:  req.IDCategory,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
@@ -64,6 +102,7 @@ func (s *Service) Create(ctx context.Context, req *CreateProductRequest) (*Produ
 		IDProduct:     product.ID,
 		IDTaxCategory: defaultTaxCategory(req.DefaultVariant.IDTaxCategory, idTaxDefault.ID),
 		AttributeValues: make([]at.AttributeValue, len(req.DefaultVariant.AttributeValues)),
+		Dimensions:    req.DefaultVariant.Dimensions.toDimensions(),
 	}
 
 
@@ -77,17 +116,29 @@ func (s *Service) Create(ctx context.Context, req *CreateProductRequest) (*Produ
 	if err := s.repo.CreateVariant(ctx, variant); err != nil {
 			return nil, err
 	}
+	s.notifyChanged(ctx, product.ID)
 
-	return s.GetByID(ctx, product.ID)
+	return s.GetByID(ctx, product.ID, "")
 }
 
-func (s *Service) GetByID(ctx context.Context, id int64) (*ProductResponse, error) {
+// GetByID devuelve el producto con el precio de cada variante en currencyCode (vacío = moneda de la lista)
+func (s *Service) GetByID(ctx context.Context, id int64, currencyCode string) (*ProductResponse, error) {
 	product, err := s.repo.GetByID(ctx, id)
 	if err != nil {		
 		return nil, err
 	}
+	if err := s.checkCurrency(ctx, currencyCode); err != nil {
+		return nil, err
+	}
+	if err := s.attachPrices(ctx, currencyCode, product.Variants); err != nil {
+		return nil, err
+	}
 
-	return mapProductToResponse(product), nil
+	resp := mapProductToResponse(product)
+	if resp.Relations, err = s.detailRelations(ctx, id, false); err != nil {
+		return nil, err
+	}
+	return resp, nil
 }
 
 func (s *Service) Update(ctx context.Context, id int64, req *UpdateProductRequest) (*ProductResponse, error) {
@@ -96,15 +147,28 @@ func (s *Service) Update(ctx context.Context, id int64, req *UpdateProductReques
 			return nil, err
 	}
 
+	// Sin slug explícito, un cambio de nombre regenera el slug; el anterior queda como redirección
+	if req.Slug != nil && *req.Slug != product.Slug {
+			if err := s.checkSlugAvailable(ctx, *req.Slug, id); err != nil {
+					return nil, err
+			}
+			product.Slug = *req.Slug
+	} else if req.Slug == nil && req.Name != nil && *req.Name != product.Name {
+			if product.Slug, err = s.generateSlug(ctx, *req.Name, id); err != nil {
+					return nil, err
+			}
+	}
 	if req.Name != nil {
 			product.Name = *req.Name
-			product.Slug = *req.Slug
 	}
 	if req.Description != nil {
 			product.Description = *req.Description
 	}
-	if req.Status != nil {
+	// status sigue admitiéndose: activar publica y desactivar devuelve a borrador
+	if req.Status != nil && *req.Status != product.Status {
 			product.Status = *req.Status
+			product.PublicationStatus = legacyPublicationStatus(*req.Status)
+			product.PublishAt, product.UnpublishAt = nil, nil
 	}
 	if req.IDCategory != nil {
 			product.IDCategory = *req.IDCategory
@@ -114,8 +178,191 @@ func (s *Service) Update(ctx context.Context, id int64, req *UpdateProductReques
 	if err := s.repo.Update(ctx, product); err != nil {
 			return nil, err
 	}
+	s.notifyChanged(ctx, id)
+
+	return s.GetByID(ctx, id, "")
+}
+
+// UpdatePublication cambia el estado de publicación del producto. Programar exige una
+// publish_at futura; publicar sin publish_at publica en este momento
+func (s *Service) UpdatePublication(ctx context.Context, id int64, req *UpdatePublicationRequest) (*ProductResponse, error) {
+	product, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canTransition(product.PublicationStatus, req.Status) {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("No se puede pasar un producto de %s a %s", product.PublicationStatus, req.Status))
+	}
+
+	now := time.Now()
+	publishAt, unpublishAt := req.PublishAt, req.UnpublishAt
 
-	return s.GetByID(ctx, id)
+	switch req.Status {
+	case PublicationScheduled:
+		if publishAt == nil || !publishAt.After(now) {
+			return nil, errors.NewValidationError("Para programar un producto publish_at debe ser una fecha futura")
+		}
+	case PublicationPublished:
+		if publishAt == nil {
+			publishAt = &now
+		} else if publishAt.After(now) {
+			return nil, errors.NewValidationError("Para publicar en una fecha futura usa el estado scheduled")
+		}
+	default:
+		// Borrador, revisión y archivado no tienen fechas de publicación
+		publishAt, unpublishAt = nil, nil
+	}
+
+	if unpublishAt != nil {
+		if !unpublishAt.After(*publishAt) || !unpublishAt.After(now) {
+			return nil, errors.NewValidationError("unpublish_at debe ser posterior a publish_at y a la fecha actual")
+		}
+	}
+
+	product.PublicationStatus = req.Status
+	product.Status = req.Status == PublicationPublished
+	product.PublishAt, product.UnpublishAt = publishAt, unpublishAt
+	product.UpdatedAt = now
+
+	if err := s.repo.Update(ctx, product); err != nil {
+		return nil, err
+	}
+	s.notifyChanged(ctx, id)
+
+	return s.GetByID(ctx, id, "")
+}
+
+// ProcessPublicationSchedule publica los productos programados que ya llegaron a su fecha y
+// archiva los publicados cuya fecha de retirada ya pasó
+func (s *Service) ProcessPublicationSchedule(ctx context.Context) (published, archived int, err error) {
+	publishedIDs, err := s.repo.PublishScheduled(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, id := range publishedIDs {
+		s.notifyChanged(ctx, id)
+	}
+
+	archivedIDs, err := s.repo.ArchiveExpired(ctx)
+	if err != nil {
+		return len(publishedIDs), 0, err
+	}
+	for _, id := range archivedIDs {
+		s.notifyChanged(ctx, id)
+	}
+
+	return len(publishedIDs), len(archivedIDs), nil
+}
+
+// GetPublishedByID devuelve el producto sólo si está visible en la tienda en este momento
+func (s *Service) GetPublishedByID(ctx context.Context, id int64, currencyCode string) (*ProductResponse, error) {
+	product, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !product.IsPublishedAt(time.Now()) {
+		return nil, errors.NewNotFoundError("Producto no encontrado")
+	}
+	if err := s.checkCurrency(ctx, currencyCode); err != nil {
+		return nil, err
+	}
+	if err := s.attachPrices(ctx, currencyCode, product.Variants); err != nil {
+		return nil, err
+	}
+
+	resp := mapProductToResponse(product)
+	if resp.Relations, err = s.detailRelations(ctx, id, true); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// maxSuggestedRelations productos sugeridos cuando no hay relaciones curadas
+const maxSuggestedRelations = 8
+
+// detailRelations devuelve las relaciones curadas del producto o, si no tiene ninguna,
+// productos publicados de su categoría o con tags en común
+func (s *Service) detailRelations(ctx context.Context, id int64, publishedOnly bool) ([]RelatedProduct, error) {
+	related, err := s.repo.ListRelations(ctx, id, publishedOnly)
+	if err != nil || len(related) > 0 {
+		return related, err
+	}
+	return s.repo.SuggestRelated(ctx, id, maxSuggestedRelations)
+}
+
+// ListRelations devuelve las relaciones curadas del producto; relationType vacío = todos los tipos
+func (s *Service) ListRelations(ctx context.Context, id int64, relationType string) ([]RelatedProduct, error) {
+	if err := s.checkProductExists(ctx, id); err != nil {
+		return nil, err
+	}
+
+	related, err := s.repo.ListRelations(ctx, id, false)
+	if err != nil || relationType == "" {
+		return related, err
+	}
+
+	filtered := []RelatedProduct{}
+	for _, rp := range related {
+		if rp.Type == relationType {
+			filtered = append(filtered, rp)
+		}
+	}
+	return filtered, nil
+}
+
+// ReplaceRelations sustituye las relaciones de un tipo por los productos indicados, en ese orden
+func (s *Service) ReplaceRelations(ctx context.Context, id int64, req *ReplaceRelationsRequest) ([]RelatedProduct, error) {
+	if err := s.checkProductExists(ctx, id); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int64]bool, len(req.ProductIDs))
+	for _, relatedID := range req.ProductIDs {
+		if relatedID == id {
+			return nil, errors.NewBadRequestError("Un producto no puede relacionarse consigo mismo")
+		}
+		if seen[relatedID] {
+			return nil, errors.NewBadRequestError(fmt.Sprintf("El producto %d está repetido", relatedID))
+		}
+		seen[relatedID] = true
+	}
+
+	existing, err := s.repo.ExistingProductIDs(ctx, req.ProductIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) != len(req.ProductIDs) {
+		found := make(map[int64]bool, len(existing))
+		for _, e := range existing {
+			found[e] = true
+		}
+		for _, relatedID := range req.ProductIDs {
+			if !found[relatedID] {
+				return nil, errors.NewNotFoundError(fmt.Sprintf("Producto %d no encontrado", relatedID))
+			}
+		}
+	}
+
+	if err := s.repo.ReplaceRelations(ctx, id, req.Type, req.ProductIDs); err != nil {
+		return nil, err
+	}
+	return s.ListRelations(ctx, id, req.Type)
+}
+
+func (s *Service) DeleteRelation(ctx context.Context, id, relatedID int64, relationType string) error {
+	return s.repo.DeleteRelation(ctx, id, relatedID, relationType)
+}
+
+func (s *Service) checkProductExists(ctx context.Context, id int64) error {
+	existing, err := s.repo.ExistingProductIDs(ctx, []int64{id})
+	if err != nil {
+		return err
+	}
+	if len(existing) == 0 {
+		return errors.NewNotFoundError("Producto no encontrado")
+	}
+	return nil
 }
 
 func (s *Service) Delete(ctx context.Context, id int64) error {
@@ -132,15 +379,33 @@ func (s *Service) Delete(ctx context.Context, id int64) error {
 	}
 
 	// Luego eliminar el producto
-	return s.repo.Delete(ctx, id)
+	if err := s.repo.Delete(ctx, id); err != nil {
+			return err
+	}
+	if s.listener != nil {
+			s.listener.ProductDeleted(ctx, id)
+	}
+	return nil
 }
 
 func (s *Service) List(ctx context.Context, p *Pagination) ([]ProductResponse, int64, error) {
+	if err := s.checkCurrency(ctx, p.Currency); err != nil {
+			return nil, 0, err
+	}
+
 	products, total, err := s.repo.List(ctx, p)
 	if err != nil {
 			return nil, 0, err
 	}
 
+	groups := make([][]ProductVariant, len(products))
+	for i := range products {
+			groups[i] = products[i].Variants
+	}
+	if err := s.attachPrices(ctx, p.Currency, groups...); err != nil {
+			return nil, 0, err
+	}
+
 	var response []ProductResponse
 	for _, product := range products {
 			response = append(response, *mapProductToResponse(&product))
@@ -149,6 +414,61 @@ func (s *Service) List(ctx context.Context, p *Pagination) ([]ProductResponse, i
 	return response, total, nil
 }
 
+// Search busca productos por relevancia de texto y filtros, y devuelve los contadores de facetas
+func (s *Service) Search(ctx context.Context, req *SearchRequest) (*SearchResponse, error) {
+	req.Query = strings.TrimSpace(req.Query)
+	if req.Sort == "" || (req.Sort == SortRelevance && req.Query == "") {
+			req.Sort = SortNewest
+			if req.Query != "" {
+					req.Sort = SortRelevance
+			}
+	}
+	if req.MinPrice != nil && req.MaxPrice != nil && req.MinPrice.GreaterThan(*req.MaxPrice) {
+			return nil, errors.NewBadRequestError("El precio mínimo no puede ser mayor que el máximo")
+	}
+	if err := s.checkCurrency(ctx, req.Currency); err != nil {
+			return nil, err
+	}
+
+	hits, total, err := s.repo.Search(ctx, req)
+	if err != nil {
+			return nil, err
+	}
+
+	facets, err := s.repo.SearchFacets(ctx, req)
+	if err != nil {
+			return nil, err
+	}
+
+	groups := make([][]ProductVariant, len(hits))
+	for i := range hits {
+			groups[i] = hits[i].Variants
+	}
+	if err := s.attachPrices(ctx, req.Currency, groups...); err != nil {
+			return nil, err
+	}
+
+	items := make([]ProductSearchItem, len(hits))
+	for i := range hits {
+			items[i] = ProductSearchItem{
+					ProductResponse: *mapProductToResponse(&hits[i].Product),
+					MinPrice:        hits[i].MinPrice,
+					MaxPrice:        hits[i].MaxPrice,
+					Stock:           hits[i].Stock,
+					Relevance:       hits[i].Relevance,
+			}
+	}
+
+	return &SearchResponse{
+			Items:   items,
+			Total:   total,
+			Page:    req.Page,
+			PerPage: req.PerPage,
+			Sort:    req.Sort,
+			Facets:  facets,
+	}, nil
+}
+
 func (s *Service) CreateVariant(ctx context.Context, req *CreateVariantRequest) (*ProductResponse, error) {
 	// Verificar que el producto existe
 	_, err := s.repo.GetByID(ctx, req.IDProduct)
@@ -168,6 +488,7 @@ func (s *Service) CreateVariant(ctx context.Context, req *CreateVariantRequest)
 		IDProduct:     req.IDProduct,
 		IDTaxCategory: defaultTaxCategory(req.IDTaxCategory, idTaxDefault.ID),
 		AttributeValues: make([]at.AttributeValue, len(req.AttributeValues)),
+		Dimensions:    req.Dimensions.toDimensions(),
 		CreatedAt:     time.Now(),
 		UpdatedAt:     time.Now(),
 	}
@@ -182,8 +503,274 @@ func (s *Service) CreateVariant(ctx context.Context, req *CreateVariantRequest)
 	if err := s.repo.CreateVariant(ctx, variant); err != nil {
 		return nil, err
 	}
+	s.notifyChanged(ctx, req.IDProduct)
+
+	return s.GetByID(ctx, req.IDProduct, "")
+}
+
+// maxVariantMatrix limita las combinaciones que se pueden generar de una vez
+const maxVariantMatrix = 500
+
+// PreviewVariantMatrix calcula las variantes que generaría la matriz sin crearlas
+func (s *Service) PreviewVariantMatrix(ctx context.Context, productID int64, req *VariantMatrixRequest) (*VariantMatrixResponse, error) {
+	result, _, err := s.buildVariantMatrix(ctx, productID, req)
+	return result, err
+}
+
+// GenerateVariantMatrix crea las variantes de todas las combinaciones que aún no existen
+func (s *Service) GenerateVariantMatrix(ctx context.Context, productID int64, req *VariantMatrixRequest) (*VariantMatrixResponse, error) {
+	result, variants, err := s.buildVariantMatrix(ctx, productID, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(variants) == 0 {
+		return result, nil
+	}
+
+	if err := s.repo.CreateVariants(ctx, variants); err != nil {
+		return nil, err
+	}
+	for i := range variants {
+		result.Variants[i].ID = variants[i].ID
+	}
+	result.Committed = true
+	s.notifyChanged(ctx, productID)
+
+	return result, nil
+}
+
+func (s *Service) buildVariantMatrix(ctx context.Context, productID int64, req *VariantMatrixRequest) (*VariantMatrixResponse, []ProductVariant, error) {
+	product, err := s.repo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Validar que cada valor pertenece a su atributo
+	var valueIDs []int64
+	seenAttributes := make(map[int64]bool)
+	for _, a := range req.Attributes {
+		if seenAttributes[a.IDProductAttribute] {
+			return nil, nil, errors.NewBadRequestError(fmt.Sprintf("El atributo %d está repetido", a.IDProductAttribute))
+		}
+		seenAttributes[a.IDProductAttribute] = true
+		valueIDs = append(valueIDs, a.ValueIDs...)
+	}
+
+	found, err := s.repo.GetAttributeValuesByIDs(ctx, valueIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	valuesByID := make(map[int64]at.AttributeValue, len(found))
+	for _, v := range found {
+		valuesByID[v.ID] = v
+	}
+
+	groups := make([][]at.AttributeValue, len(req.Attributes))
+	combinations := 1
+	for i, a := range req.Attributes {
+		seen := make(map[int64]bool)
+		for _, id := range a.ValueIDs {
+			v, ok := valuesByID[id]
+			if !ok {
+				return nil, nil, errors.NewNotFoundError(fmt.Sprintf("Valor de atributo %d no encontrado", id))
+			}
+			if v.IDProductAttribute != a.IDProductAttribute {
+				return nil, nil, errors.NewBadRequestError(fmt.Sprintf("El valor %d no pertenece al atributo %d", id, a.IDProductAttribute))
+			}
+			if !seen[id] {
+				seen[id] = true
+				groups[i] = append(groups[i], v)
+			}
+		}
+		combinations *= len(groups[i])
+		if combinations > maxVariantMatrix {
+			return nil, nil, errors.NewBadRequestError(fmt.Sprintf("La matriz supera el máximo de %d combinaciones", maxVariantMatrix))
+		}
+	}
+
+	// Una variante existente repite la combinación si coincide en los atributos de la matriz,
+	// aunque tenga valores de otros atributos
+	existing := make(map[string]int64, len(product.Variants))
+	for _, v := range product.Variants {
+		var ids []int64
+		for _, av := range v.AttributeValues {
+			if seenAttributes[av.IDProductAttribute] {
+				ids = append(ids, av.ID)
+			}
+		}
+		if len(ids) != len(req.Attributes) {
+			continue
+		}
+		if _, ok := existing[combinationKey(ids)]; !ok {
+			existing[combinationKey(ids)] = v.ID
+		}
+	}
+
+	idTaxDefault, err := s.taxCategoryService.GetTaxDefault(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	prefix := strings.ToUpper(req.SKUPrefix)
+	if prefix == "" {
+		prefix = skuCode(product.Name, "PRD")
+	}
+	prefix = fmt.Sprintf("%s-%d", prefix, product.ID)
+
+	// Los SKU de variantes eliminadas siguen ocupando el índice único
+	usedSKUs, err := s.repo.ListSKUsWithPrefix(ctx, prefix)
+	if err != nil {
+		return nil, nil, err
+	}
+	taken := takenValues(usedSKUs)
+
+	result := &VariantMatrixResponse{Variants: []VariantMatrixItem{}, Skipped: []SkippedCombination{}}
+	var variants []ProductVariant
+
+	// Recorre las combinaciones como un cuentakilómetros: el último atributo cambia primero
+	indexes := make([]int, len(groups))
+	for {
+		values := make([]at.AttributeValue, len(groups))
+		ids := make([]int64, len(groups))
+		names := make([]string, len(groups))
+		codes := []string{prefix}
+		for i, g := range groups {
+			values[i] = g[indexes[i]]
+			ids[i] = values[i].ID
+			names[i] = values[i].Name
+			codes = append(codes, skuCode(values[i].Name, fmt.Sprint(values[i].ID)))
+		}
+
+		if id, ok := existing[combinationKey(ids)]; ok {
+			result.Skipped = append(result.Skipped, SkippedCombination{IDVariant: id, AttributeValues: values})
+		} else {
+			sku := uniqueValue(strings.Join(codes, "-"), taken)
+			name := product.Name + " - " + strings.Join(names, " / ")
+
+			result.Variants = append(result.Variants, VariantMatrixItem{Name: name, SKU: sku, AttributeValues: values})
+			variants = append(variants, ProductVariant{
+				Name:            name,
+				SKU:             sku,
+				Stock:           req.Stock,
+				IDProduct:       product.ID,
+				IDTaxCategory:   defaultTaxCategory(req.IDTaxCategory, idTaxDefault.ID),
+				AttributeValues: values,
+				Dimensions:      req.Dimensions.toDimensions(),
+			})
+		}
+
+		i := len(indexes) - 1
+		for ; i >= 0; i-- {
+			indexes[i]++
+			if indexes[i] < len(groups[i]) {
+				break
+			}
+			indexes[i] = 0
+		}
+		if i < 0 {
+			break
+		}
+	}
+
+	return result, variants, nil
+}
+
+// combinationKey identifica un conjunto de valores de atributo sin importar el orden
+func combinationKey(ids []int64) string {
+	sorted := append([]int64(nil), ids...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return fmt.Sprint(sorted)
+}
+
+// skuCode devuelve hasta 3 letras o dígitos en mayúsculas del texto, o fallback si no tiene ninguno
+func skuCode(value, fallback string) string {
+	var code []rune
+	for _, r := range strings.ToUpper(value) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			code = append(code, r)
+			if len(code) == 3 {
+				break
+			}
+		}
+	}
+	if len(code) == 0 {
+		return fallback
+	}
+	return string(code)
+}
+
+// uniqueValue añade un sufijo numérico si el SKU o slug ya está en uso y lo marca como ocupado.
+// La comparación ignora mayúsculas, igual que los índices únicos de la base de datos
+func uniqueValue(value string, taken map[string]bool) string {
+	candidate := value
+	for n := 2; taken[strings.ToUpper(candidate)]; n++ {
+		candidate = fmt.Sprintf("%s-%d", value, n)
+	}
+	taken[strings.ToUpper(candidate)] = true
+	return candidate
+}
+
+// Clone duplica el producto con sus tags y variantes (valores de atributo, medidas, galería
+// y asignaciones de precio). La copia se crea desactivada salvo que se indique lo contrario
+// y sin stock, y recibe un slug y SKUs nuevos
+func (s *Service) Clone(ctx context.Context, id int64, req *CloneProductRequest) (*ProductResponse, error) {
+	source, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	clone := &Product{
+		Name:   defaultValue(req.Name, source.Name+" (copia)"),
+		Status: req.Status != nil && *req.Status,
+	}
+	clone.PublicationStatus = legacyPublicationStatus(clone.Status)
+
+	if req.Slug != "" {
+		if err := s.checkSlugAvailable(ctx, req.Slug, 0); err != nil {
+			return nil, err
+		}
+		clone.Slug = req.Slug
+	} else {
+		base := source.Slug + "-copia"
+		if clone.Slug, err = s.slugs.Generate(ctx, base, slug.EntityProduct, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	skus := make(map[int64]string, len(source.Variants))
+	for _, v := range source.Variants {
+		// Deja sitio para el sufijo dentro de los 100 caracteres de la columna
+		base := v.SKU
+		if len(base) > 85 {
+			base = base[:85]
+		}
+		base += "-COPIA"
+
+		existing, err := s.repo.ListSKUsWithPrefix(ctx, base)
+		if err != nil {
+			return nil, err
+		}
+		taken := takenValues(existing)
+		for _, sku := range skus {
+			taken[strings.ToUpper(sku)] = true
+		}
+		skus[v.ID] = uniqueValue(base, taken)
+	}
+
+	if err := s.repo.CloneProduct(ctx, id, clone, skus); err != nil {
+		return nil, err
+	}
+	s.notifyChanged(ctx, clone.ID)
+
+	return s.GetByID(ctx, clone.ID, "")
+}
 
-	return s.GetByID(ctx, req.IDProduct)
+func takenValues(values []string) map[string]bool {
+	taken := make(map[string]bool, len(values))
+	for _, v := range values {
+		taken[strings.ToUpper(v)] = true
+	}
+	return taken
 }
 
 func (s *Service) UpdateVariant(ctx context.Context, id int64, req *UpdateVariantRequest) (*ProductVariant, error) {
@@ -198,8 +785,9 @@ func (s *Service) UpdateVariant(ctx context.Context, id int64, req *UpdateVarian
 	if req.SKU != nil {
 			variant.SKU = *req.SKU
 	}
+	// El stock sólo cambia con movimientos de inventario, para que quede constancia de dónde y por qué
 	if req.Stock != nil {
-			variant.Stock = *req.Stock
+			return nil, errors.NewBadRequestError("El stock no se puede editar en la variante; registra un movimiento de inventario")
 	}
 
 	if req.IDTaxCategory != nil {
@@ -210,6 +798,10 @@ func (s *Service) UpdateVariant(ctx context.Context, id int64, req *UpdateVarian
 		variant.IDTaxCategory = defaultTaxCategory(*req.IDTaxCategory, idTaxDefault.ID)
 	}
 
+	if req.Dimensions != nil {
+			variant.Dimensions = req.Dimensions.toDimensions()
+	}
+
 	// Actualizar AttributeValues si se proporcionaron
 	if req.AttributeValues != nil {
 			variant.AttributeValues = make([]at.AttributeValue, len(*req.AttributeValues))
@@ -227,6 +819,7 @@ func (s *Service) UpdateVariant(ctx context.Context, id int64, req *UpdateVarian
 	if err := s.repo.UpdateVariant(ctx, variant); err != nil {
 		return nil, err
 	}
+	s.notifyChanged(ctx, variant.IDProduct)
 
 	return s.repo.GetVariantByID(ctx, id)
 }
@@ -247,18 +840,319 @@ func (s *Service) DeleteVariant(ctx context.Context, id int64) error {
 			return errors.NewBadRequestError("No se puede eliminar la única variante del producto")
 	}
 
-	return s.repo.DeleteVariant(ctx, id)
+	if err := s.repo.DeleteVariant(ctx, id); err != nil {
+			return err
+	}
+	s.notifyChanged(ctx, variant.IDProduct)
+	return nil
 }
 
-func (s *Service) GetVariant(ctx context.Context, id int64) (*ProductVariant, error) {
-	return s.repo.GetVariantByID(ctx, id)
+// SetBundle convierte la variante en un pack o sustituye sus componentes y su precio
+func (s *Service) SetBundle(ctx context.Context, variantID int64, req *SetBundleRequest) (*ProductVariant, error) {
+	variant, err := s.repo.GetVariantByID(ctx, variantID)
+	if err != nil {
+		return nil, err
+	}
+
+	isComponent, err := s.repo.IsBundleComponent(ctx, variantID)
+	if err != nil {
+		return nil, err
+	}
+	if isComponent {
+		return nil, errors.NewBadRequestError("La variante forma parte de otro pack y no puede ser un pack")
+	}
+
+	bundle := &Bundle{
+		PricingMode:   req.PricingMode,
+		DiscountType:  req.DiscountType,
+		DiscountValue: req.DiscountValue,
+	}
+	if bundle.PricingMode == "" {
+		bundle.PricingMode = BundlePricingFixed
+	}
+	if bundle.DiscountType == "" {
+		bundle.DiscountValue = money.Zero
+	}
+	if bundle.DiscountType != "" && bundle.PricingMode != BundlePricingComponents {
+		return nil, errors.NewBadRequestError("El descuento sólo se aplica a packs con precio por componentes")
+	}
+	if bundle.DiscountType == BundleDiscountPercent && bundle.DiscountValue.GreaterThan(money.NewFromInt(100)) {
+		return nil, errors.NewBadRequestError("El descuento porcentual no puede superar el 100%")
+	}
+
+	ids := make([]int64, 0, len(req.Components))
+	seen := make(map[int64]bool, len(req.Components))
+	for _, c := range req.Components {
+		if c.IDProductVariant == variantID {
+			return nil, errors.NewBadRequestError("Un pack no puede contenerse a sí mismo")
+		}
+		if seen[c.IDProductVariant] {
+			return nil, errors.NewBadRequestError(fmt.Sprintf("La variante %d está repetida en el pack", c.IDProductVariant))
+		}
+		seen[c.IDProductVariant] = true
+		ids = append(ids, c.IDProductVariant)
+		bundle.Components = append(bundle.Components, BundleComponent{
+			IDProductVariant: c.IDProductVariant,
+			Quantity:         c.Quantity,
+		})
+	}
+
+	for _, id := range ids {
+		if _, err := s.repo.GetVariantByID(ctx, id); err != nil {
+			if errors.IsNotFound(err) {
+				return nil, errors.NewNotFoundError(fmt.Sprintf("Variante %d no encontrada", id))
+			}
+			return nil, err
+		}
+	}
+
+	nested, err := s.repo.BundleVariantIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(nested) > 0 {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("La variante %d es un pack y no puede ser componente de otro", nested[0]))
+	}
+
+	if err := s.repo.SaveBundle(ctx, variantID, bundle); err != nil {
+		return nil, err
+	}
+	s.notifyChanged(ctx, variant.IDProduct)
+
+	return s.GetVariant(ctx, variantID, "")
 }
 
-func (s *Service) ListVariants(ctx context.Context, productID int64) ([]ProductVariant, error) {
-	return s.repo.ListVariants(ctx, productID)
+// DeleteBundle deja la variante como una variante normal con su propio stock
+func (s *Service) DeleteBundle(ctx context.Context, variantID int64) error {
+	variant, err := s.repo.GetVariantByID(ctx, variantID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteBundle(ctx, variantID); err != nil {
+		return err
+	}
+	s.notifyChanged(ctx, variant.IDProduct)
+	return nil
 }
 
+// ConsumeStock registra como ventas las líneas de un pedido confirmado. Las líneas de packs
+// descuentan el stock de sus componentes
+func (s *Service) ConsumeStock(ctx context.Context, req *ConsumeStockRequest) error {
+	if req.Actor == "" {
+		req.Actor = "checkout"
+	}
+
+	productIDs := make(map[int64]bool)
+	for _, line := range req.Items {
+		variant, err := s.repo.GetVariantByID(ctx, line.IDProductVariant)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return errors.NewNotFoundError(fmt.Sprintf("Variante %d no encontrada", line.IDProductVariant))
+			}
+			return err
+		}
+		productIDs[variant.IDProduct] = true
+		if variant.Bundle != nil {
+			for _, c := range variant.Bundle.Components {
+				component, err := s.repo.GetVariantByID(ctx, c.IDProductVariant)
+				if err != nil {
+					return err
+				}
+				productIDs[component.IDProduct] = true
+			}
+		}
+	}
+
+	if err := s.repo.ConsumeStock(ctx, req); err != nil {
+		return err
+	}
 
+	for id := range productIDs {
+		s.notifyChanged(ctx, id)
+	}
+	return nil
+}
+
+// ListVariantMedia devuelve la galería de la variante
+func (s *Service) ListVariantMedia(ctx context.Context, variantID int64) ([]VariantMedia, error) {
+	if _, err := s.repo.GetVariantByID(ctx, variantID); err != nil {
+		return nil, err
+	}
+
+	media, err := s.repo.ListVariantMedia(ctx, variantID)
+	if err != nil {
+		return nil, err
+	}
+	if media == nil {
+		media = []VariantMedia{}
+	}
+	return media, nil
+}
+
+func (s *Service) AttachVariantMedia(ctx context.Context, variantID int64, req *AttachVariantMediaRequest) ([]VariantMedia, error) {
+	if _, err := s.repo.GetVariantByID(ctx, variantID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.AttachVariantMedia(ctx, variantID, req.IDMedia, req.Position, req.IsPrimary); err != nil {
+		return nil, err
+	}
+	return s.ListVariantMedia(ctx, variantID)
+}
+
+func (s *Service) DetachVariantMedia(ctx context.Context, variantID, mediaID int64) ([]VariantMedia, error) {
+	if _, err := s.repo.GetVariantByID(ctx, variantID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.DetachVariantMedia(ctx, variantID, mediaID); err != nil {
+		return nil, err
+	}
+	return s.ListVariantMedia(ctx, variantID)
+}
+
+func (s *Service) ReorderVariantMedia(ctx context.Context, variantID int64, req *ReorderVariantMediaRequest) ([]VariantMedia, error) {
+	if _, err := s.repo.GetVariantByID(ctx, variantID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ReorderVariantMedia(ctx, variantID, req.MediaIDs); err != nil {
+		return nil, err
+	}
+	return s.ListVariantMedia(ctx, variantID)
+}
+
+func (s *Service) SetPrimaryVariantMedia(ctx context.Context, variantID, mediaID int64) ([]VariantMedia, error) {
+	if _, err := s.repo.GetVariantByID(ctx, variantID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetPrimaryVariantMedia(ctx, variantID, mediaID); err != nil {
+		return nil, err
+	}
+	return s.ListVariantMedia(ctx, variantID)
+}
+
+func (s *Service) GetVariant(ctx context.Context, id int64, currencyCode string) (*ProductVariant, error) {
+	if err := s.checkCurrency(ctx, currencyCode); err != nil {
+		return nil, err
+	}
+
+	variant, err := s.repo.GetVariantByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	variants := []ProductVariant{*variant}
+	if err := s.attachPrices(ctx, currencyCode, variants); err != nil {
+		return nil, err
+	}
+	return &variants[0], nil
+}
+
+func (s *Service) ListVariants(ctx context.Context, productID int64, currencyCode string) ([]ProductVariant, error) {
+	if err := s.checkCurrency(ctx, currencyCode); err != nil {
+		return nil, err
+	}
+
+	variants, err := s.repo.ListVariants(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.attachPrices(ctx, currencyCode, variants); err != nil {
+		return nil, err
+	}
+	return variants, nil
+}
+
+// checkCurrency comprueba que la moneda pedida por el cliente exista y esté activa
+func (s *Service) checkCurrency(ctx context.Context, currencyCode string) error {
+	if currencyCode == "" {
+		return nil
+	}
+
+	c, err := s.currencyService.GetCurrencyByCode(ctx, currencyCode)
+	if err != nil {
+		return err
+	}
+	if !c.Active {
+		return errors.NewBadRequestError(fmt.Sprintf("La moneda %s no está disponible", c.Code))
+	}
+	return nil
+}
+
+// attachPrices resuelve el precio por defecto de las variantes convertido a la moneda pedida, con
+// una sola carga de precios y un solo tipo de cambio para todos los grupos de variantes.
+// Las variantes sin precio vigente se devuelven sin precio
+func (s *Service) attachPrices(ctx context.Context, currencyCode string, groups ...[]ProductVariant) error {
+	var ids []int64
+	for _, variants := range groups {
+		for i := range variants {
+			ids = append(ids, variants[i].ID)
+			if b := variants[i].Bundle; b != nil && b.PricingMode == BundlePricingComponents {
+				for _, c := range b.Components {
+					ids = append(ids, c.IDProductVariant)
+				}
+			}
+		}
+	}
+
+	set, err := s.pricesService.DefaultPrices(ctx, ids)
+	if err != nil {
+		return err
+	}
+	if set.Empty() {
+		return nil
+	}
+	if set.SourceCode == "" {
+		// Sin moneda en la lista ni moneda base los importes no se pueden formatear ni convertir
+		if currencyCode != "" {
+			return errors.NewBadRequestError(fmt.Sprintf("No hay tipo de cambio vigente para la moneda %s", strings.ToUpper(currencyCode)))
+		}
+		return nil
+	}
+
+	localizer, err := s.currencyService.NewLocalizer(ctx, set.SourceCode, currencyCode)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return errors.NewBadRequestError(fmt.Sprintf("No hay tipo de cambio vigente para la moneda %s", strings.ToUpper(currencyCode)))
+		}
+		return err
+	}
+
+	for _, variants := range groups {
+		for i := range variants {
+			if b := variants[i].Bundle; b != nil && b.PricingMode == BundlePricingComponents {
+				variants[i].Price = bundlePrice(set, localizer, b)
+				continue
+			}
+			if amount, ok := set.UnitAmount(variants[i].ID, 1); ok {
+				variants[i].Price = localizer.Localize(amount)
+			}
+		}
+	}
+	return nil
+}
+
+// bundlePrice suma el precio de los componentes por sus unidades, aplica el descuento del pack y
+// convierte el total a la moneda pedida. Si algún componente no tiene precio, el pack tampoco
+func bundlePrice(set *prices.DefaultPriceSet, localizer *currency.Localizer, b *Bundle) *currency.LocalizedPrice {
+	if len(b.Components) == 0 {
+		return nil
+	}
+
+	sum := money.Zero
+	for _, c := range b.Components {
+		amount, ok := set.UnitAmount(c.IDProductVariant, c.Quantity)
+		if !ok {
+			return nil
+		}
+		sum = sum.Add(amount.Mul(money.NewFromInt(int64(c.Quantity))))
+	}
+	return localizer.Localize(b.ApplyDiscount(sum))
+}
 
 func mapProductToResponse(p *Product) *ProductResponse {
 
@@ -268,6 +1162,11 @@ func mapProductToResponse(p *Product) *ProductResponse {
 			Slug:        p.Slug,
 			Description: p.Description,
 			Status:      p.Status,
+			PublicationStatus: p.PublicationStatus,
+			PublishAt:   p.PublishAt,
+			UnpublishAt: p.UnpublishAt,
+			AverageRating: p.AverageRating,
+			ReviewCount: p.ReviewCount,
 			IDCategory:  p.IDCategory,
 			Category:    p.Category,
 			Tags:        p.Tags,
@@ -277,6 +1176,24 @@ func mapProductToResponse(p *Product) *ProductResponse {
 	}
 }
 
+// generateSlug genera a partir del nombre un slug que no use ni haya usado otro producto o categoría
+func (s *Service) generateSlug(ctx context.Context, name string, id int64) (string, error) {
+	return s.slugs.Generate(ctx, defaultValue(slug.Make(name), "producto"), slug.EntityProduct, id)
+}
+
+// checkSlugAvailable comprueba que ningún otro producto ni categoría use ni haya usado el slug
+func (s *Service) checkSlugAvailable(ctx context.Context, value string, id int64) error {
+	return s.slugs.Check(ctx, value, slug.EntityProduct, id)
+}
+
+// legacyPublicationStatus traduce el antiguo booleano status al estado de publicación
+func legacyPublicationStatus(active bool) string {
+	if active {
+		return PublicationPublished
+	}
+	return PublicationDraft
+}
+
 func defaultValue(value, defaultValue string) string {
 	if value == "" {
 		return defaultValue
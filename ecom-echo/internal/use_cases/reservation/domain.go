@@ -0,0 +1,26 @@
+// domain.go
+package reservation
+
+import "time"
+
+// Reservation unidades de una variante apartadas para una línea de carrito. En las líneas de packs
+// IDLineVariant es el pack e IDProductVariant cada uno de sus componentes
+type Reservation struct {
+	ID               int64     `json:"id"`
+	IDCart           int64     `json:"id_cart"`
+	IDLineVariant    int64     `json:"id_line_variant"`
+	IDProductVariant int64     `json:"id_product_variant"`
+	SKU              string    `json:"sku"`
+	Quantity         int       `json:"quantity"`
+	Status           string    `json:"status"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	Reference        string    `json:"reference,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// CartReservations reservas vigentes del carrito; ExpiresAt es la primera que caduca
+type CartReservations struct {
+	IDCart       int64         `json:"id_cart"`
+	Reservations []Reservation `json:"reservations"`
+	ExpiresAt    *time.Time    `json:"expires_at,omitempty"`
+}
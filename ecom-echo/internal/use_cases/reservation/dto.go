@@ -0,0 +1,8 @@
+// dto.go
+package reservation
+
+// ReserveLineRequest cantidad de la línea del carrito; 0 libera su reserva
+type ReserveLineRequest struct {
+	IDProductVariant int64 `json:"id_product_variant" validate:"required"`
+	Quantity         int   `json:"quantity" validate:"min=0,max=10000"`
+}
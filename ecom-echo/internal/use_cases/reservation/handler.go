@@ -0,0 +1,135 @@
+// handler.go
+package reservation
+
+import (
+	"ecom/internal/shared/errors"
+	"ecom/internal/shared/response"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(e *echo.Group, s *Service) {
+	h := &Handler{service: s}
+
+	e.GET("/cart/:cartId", h.GetCart)
+	e.PUT("/cart/:cartId/lines", h.ReserveLine)
+	e.DELETE("/cart/:cartId/lines/:variantId", h.ReleaseLine)
+	e.POST("/cart/:cartId/refresh", h.Refresh)
+	e.DELETE("/cart/:cartId", h.ReleaseCart)
+}
+
+func (h *Handler) GetCart(c echo.Context) error {
+	cartID, err := strconv.ParseInt(c.Param("cartId"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID de carrito inválido", err.Error()))
+	}
+
+	reservations, err := h.service.GetCart(c.Request().Context(), cartID)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Reservas obtenidas exitosamente", reservations))
+}
+
+func (h *Handler) ReserveLine(c echo.Context) error {
+	cartID, err := strconv.ParseInt(c.Param("cartId"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID de carrito inválido", err.Error()))
+	}
+
+	var req ReserveLineRequest
+	if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error al procesar la solicitud", err.Error()))
+	}
+
+	if err := c.Validate(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error de validación", err.Error()))
+	}
+
+	reservations, err := h.service.ReserveLine(c.Request().Context(), cartID, &req)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Stock reservado exitosamente", reservations))
+}
+
+func (h *Handler) ReleaseLine(c echo.Context) error {
+	cartID, err := strconv.ParseInt(c.Param("cartId"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID de carrito inválido", err.Error()))
+	}
+
+	variantID, err := strconv.ParseInt(c.Param("variantId"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID de variante inválido", err.Error()))
+	}
+
+	reservations, err := h.service.ReleaseLine(c.Request().Context(), cartID, variantID)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Reserva liberada exitosamente", reservations))
+}
+
+func (h *Handler) Refresh(c echo.Context) error {
+	cartID, err := strconv.ParseInt(c.Param("cartId"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID de carrito inválido", err.Error()))
+	}
+
+	reservations, err := h.service.Refresh(c.Request().Context(), cartID)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Reservas renovadas exitosamente", reservations))
+}
+
+func (h *Handler) ReleaseCart(c echo.Context) error {
+	cartID, err := strconv.ParseInt(c.Param("cartId"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID de carrito inválido", err.Error()))
+	}
+
+	if err := h.service.ReleaseCart(c.Request().Context(), cartID); err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Reservas del carrito liberadas exitosamente", nil))
+}
+
+func (h *Handler) handleError(c echo.Context, err error) error {
+	switch e := err.(type) {
+	case *errors.AppError:
+			return c.JSON(e.Code, response.Error(e.Message, nil))
+	default:
+			if errors.IsNotFound(err) {
+					return c.JSON(http.StatusNotFound, 
+							response.Error("Recurso no encontrado", nil))
+			}
+			return c.JSON(http.StatusInternalServerError, 
+					response.Error("Error interno del servidor", nil))
+	}
+}
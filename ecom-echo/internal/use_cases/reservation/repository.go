@@ -0,0 +1,190 @@
+// repository.go
+package reservation
+
+import (
+	"context"
+	"database/sql"
+	"ecom/internal/shared/errors"
+	"ecom/internal/shared/stock"
+	"strings"
+	"time"
+)
+
+type Repository interface {
+	CartExists(ctx context.Context, cartID int64) (bool, error)
+	ListActive(ctx context.Context, cartID int64) ([]Reservation, error)
+	Reserve(ctx context.Context, cartID, variantID int64, quantity int, ttl time.Duration) error
+	ReleaseCart(ctx context.Context, cartID int64) ([]int64, error)
+	Refresh(ctx context.Context, cartID int64, ttl time.Duration) (int64, error)
+	ReleaseExpired(ctx context.Context, limit int) ([]int64, error)
+	VariantProductIDs(ctx context.Context, variantIDs []int64) ([]int64, error)
+}
+
+type MySQLRepository struct {
+	db *sql.DB
+}
+
+func NewMySQLRepository(db *sql.DB) Repository {
+	return &MySQLRepository{db: db}
+}
+
+func (r *MySQLRepository) CartExists(ctx context.Context, cartID int64) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx,
+		"SELECT EXISTS(SELECT 1 FROM carts WHERE id = ? AND deleted_at IS NULL)", cartID).Scan(&exists)
+	if err != nil {
+		return false, errors.NewMysqlError(err)
+	}
+	return exists, nil
+}
+
+// ListActive devuelve las reservas vigentes del carrito
+func (r *MySQLRepository) ListActive(ctx context.Context, cartID int64) ([]Reservation, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT sr.id, sr.id_cart, sr.id_line_variant, sr.id_product_variant, pv.sku, sr.quantity, sr.status,
+			sr.expires_at, COALESCE(sr.reference, ''), sr.created_at
+		FROM stock_reservations sr
+		INNER JOIN product_variants pv ON pv.id = sr.id_product_variant
+		WHERE sr.id_cart = ? AND sr.status = 'active' AND sr.expires_at > NOW()
+		ORDER BY sr.id_line_variant, sr.id_product_variant
+	`, cartID)
+	if err != nil {
+		return nil, errors.NewMysqlError(err)
+	}
+	defer rows.Close()
+
+	reservations := []Reservation{}
+	for rows.Next() {
+		var rv Reservation
+		if err := rows.Scan(
+			&rv.ID, &rv.IDCart, &rv.IDLineVariant, &rv.IDProductVariant, &rv.SKU, &rv.Quantity, &rv.Status,
+			&rv.ExpiresAt, &rv.Reference, &rv.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, rv)
+	}
+	return reservations, rows.Err()
+}
+
+// Reserve sustituye la reserva de la línea del carrito en una transacción
+func (r *MySQLRepository) Reserve(ctx context.Context, cartID, variantID int64, quantity int, ttl time.Duration) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	defer tx.Rollback()
+
+	if err := stock.Reserve(ctx, tx, cartID, variantID, quantity, ttl); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.NewInternalError("Error al reservar stock", err)
+	}
+	return nil
+}
+
+// ReleaseCart libera todas las reservas del carrito y devuelve las variantes afectadas
+func (r *MySQLRepository) ReleaseCart(ctx context.Context, cartID int64) ([]int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.NewMysqlError(err)
+	}
+	defer tx.Rollback()
+
+	ids, err := stock.ReleaseCart(ctx, tx, cartID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.NewInternalError("Error al liberar las reservas", err)
+	}
+	return ids, nil
+}
+
+// Refresh amplía la caducidad de las reservas vigentes del carrito. Las ya caducadas no se recuperan:
+// hay que volver a reservar la línea
+func (r *MySQLRepository) Refresh(ctx context.Context, cartID int64, ttl time.Duration) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE stock_reservations
+		SET expires_at = DATE_ADD(NOW(), INTERVAL ? SECOND), updated_at = NOW()
+		WHERE id_cart = ? AND status = 'active' AND expires_at > NOW()
+	`, int64(ttl/time.Second), cartID)
+	if err != nil {
+		return 0, errors.NewMysqlError(err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.NewInternalError("Error al renovar las reservas", err)
+	}
+	return rows, nil
+}
+
+// ReleaseExpired libera las reservas caducadas, una variante por transacción para no retener
+// bloqueos. Devuelve las variantes liberadas
+func (r *MySQLRepository) ReleaseExpired(ctx context.Context, limit int) ([]int64, error) {
+	ids, err := stock.ExpiredVariantIDs(ctx, r.db, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	released := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if err := r.expireVariant(ctx, id); err != nil {
+			return released, err
+		}
+		released = append(released, id)
+	}
+	return released, nil
+}
+
+func (r *MySQLRepository) expireVariant(ctx context.Context, variantID int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	defer tx.Rollback()
+
+	// Bloquear la variante libera sus reservas caducadas
+	if _, err := stock.LockVariants(ctx, tx, []int64{variantID}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.NewInternalError("Error al liberar las reservas caducadas", err)
+	}
+	return nil
+}
+
+// VariantProductIDs devuelve los productos de las variantes, para avisar de su cambio de disponibilidad
+func (r *MySQLRepository) VariantProductIDs(ctx context.Context, variantIDs []int64) ([]int64, error) {
+	if len(variantIDs) == 0 {
+		return nil, nil
+	}
+
+	args := make([]interface{}, len(variantIDs))
+	for i, id := range variantIDs {
+		args[i] = id
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(variantIDs)), ",")
+
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT DISTINCT id_product FROM product_variants WHERE id IN ("+placeholders+")", args...)
+	if err != nil {
+		return nil, errors.NewMysqlError(err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
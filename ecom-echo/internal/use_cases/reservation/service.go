@@ -0,0 +1,126 @@
+// service.go
+package reservation
+
+import (
+	"context"
+	"ecom/internal/shared/errors"
+	"time"
+
+	"ecom/internal/use_cases/product"
+)
+
+// sweepBatchSize variantes con reservas caducadas que se liberan en cada pasada
+const sweepBatchSize = 500
+
+type Service struct {
+	repo     Repository
+	ttl      time.Duration
+	listener product.ChangeListener
+}
+
+// NewService crea el servicio; ttl es lo que dura cada reserva y listener puede ser nil
+func NewService(repo Repository, ttl time.Duration, listener product.ChangeListener) *Service {
+	return &Service{repo: repo, ttl: ttl, listener: listener}
+}
+
+func (s *Service) notifyVariantsChanged(ctx context.Context, variantIDs []int64) {
+	if s.listener == nil || len(variantIDs) == 0 {
+		return
+	}
+	productIDs, err := s.repo.VariantProductIDs(ctx, variantIDs)
+	if err != nil {
+		return
+	}
+	for _, id := range productIDs {
+		s.listener.ProductChanged(ctx, id)
+	}
+}
+
+// GetCart devuelve las reservas vigentes del carrito
+func (s *Service) GetCart(ctx context.Context, cartID int64) (*CartReservations, error) {
+	if err := s.checkCart(ctx, cartID); err != nil {
+		return nil, err
+	}
+
+	reservations, err := s.repo.ListActive(ctx, cartID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CartReservations{IDCart: cartID, Reservations: reservations}
+	for i := range reservations {
+		if result.ExpiresAt == nil || reservations[i].ExpiresAt.Before(*result.ExpiresAt) {
+			result.ExpiresAt = &reservations[i].ExpiresAt
+		}
+	}
+	return result, nil
+}
+
+// ReserveLine reserva la cantidad de la línea del carrito, sustituyendo la reserva anterior y
+// renovando su caducidad. Con cantidad 0 libera la línea
+func (s *Service) ReserveLine(ctx context.Context, cartID int64, req *ReserveLineRequest) (*CartReservations, error) {
+	if err := s.checkCart(ctx, cartID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Reserve(ctx, cartID, req.IDProductVariant, req.Quantity, s.ttl); err != nil {
+		return nil, err
+	}
+	s.notifyVariantsChanged(ctx, []int64{req.IDProductVariant})
+
+	return s.GetCart(ctx, cartID)
+}
+
+// ReleaseLine libera la reserva de una línea del carrito
+func (s *Service) ReleaseLine(ctx context.Context, cartID, variantID int64) (*CartReservations, error) {
+	return s.ReserveLine(ctx, cartID, &ReserveLineRequest{IDProductVariant: variantID})
+}
+
+// ReleaseCart libera todas las reservas del carrito (carrito abandonado o vaciado)
+func (s *Service) ReleaseCart(ctx context.Context, cartID int64) error {
+	if err := s.checkCart(ctx, cartID); err != nil {
+		return err
+	}
+
+	variantIDs, err := s.repo.ReleaseCart(ctx, cartID)
+	if err != nil {
+		return err
+	}
+	s.notifyVariantsChanged(ctx, variantIDs)
+	return nil
+}
+
+// Refresh amplía la caducidad de las reservas vigentes del carrito, por ejemplo al entrar en el pago
+func (s *Service) Refresh(ctx context.Context, cartID int64) (*CartReservations, error) {
+	if err := s.checkCart(ctx, cartID); err != nil {
+		return nil, err
+	}
+
+	refreshed, err := s.repo.Refresh(ctx, cartID, s.ttl)
+	if err != nil {
+		return nil, err
+	}
+	if refreshed == 0 {
+		return nil, errors.NewConflictError("El carrito no tiene reservas vigentes; vuelve a reservar sus líneas")
+	}
+
+	return s.GetCart(ctx, cartID)
+}
+
+// ReleaseExpired libera las reservas caducadas y devuelve cuántas variantes vuelven a tener ese stock disponible
+func (s *Service) ReleaseExpired(ctx context.Context) (int, error) {
+	variantIDs, err := s.repo.ReleaseExpired(ctx, sweepBatchSize)
+	s.notifyVariantsChanged(ctx, variantIDs)
+	return len(variantIDs), err
+}
+
+func (s *Service) checkCart(ctx context.Context, cartID int64) error {
+	exists, err := s.repo.CartExists(ctx, cartID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.NewNotFoundError("Carrito no encontrado")
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+// sweeper.go
+package reservation
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Sweeper libera periódicamente las reservas de stock caducadas
+type Sweeper struct {
+	service  *Service
+	interval time.Duration
+}
+
+func NewSweeper(service *Service, interval time.Duration) *Sweeper {
+	return &Sweeper{service: service, interval: interval}
+}
+
+// Start lanza la liberación en segundo plano hasta que se cancele el contexto.
+// La primera pasada se hace al arrancar
+func (s *Sweeper) Start(ctx context.Context) {
+	if s.interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			s.sweep(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (s *Sweeper) sweep(ctx context.Context) {
+	released, err := s.service.ReleaseExpired(ctx)
+	if err != nil {
+		log.Printf("Error al liberar las reservas caducadas: %v", err)
+	}
+
+	if released > 0 {
+		log.Printf("Reservas caducadas liberadas en %d variantes", released)
+	}
+}
@@ -0,0 +1,10 @@
+// domain.go
+package resolver
+
+// Match entidad a la que apunta un slug. Redirect indica que el slug es antiguo y Slug es el actual
+type Match struct {
+	EntityType string
+	EntityID   int64
+	Slug       string
+	Redirect   bool
+}
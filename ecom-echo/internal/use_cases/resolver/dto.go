@@ -0,0 +1,12 @@
+// dto.go
+package resolver
+
+// ResolveResponse entidad actual del slug o, si el slug es antiguo, el destino de la redirección
+type ResolveResponse struct {
+	Type     string      `json:"type"` // product | category
+	ID       int64       `json:"id"`
+	Slug     string      `json:"slug"` // Slug actual
+	Redirect bool        `json:"redirect"`
+	Location string      `json:"location,omitempty"` // Sólo en redirecciones
+	Entity   interface{} `json:"entity,omitempty"`   // Sólo si el slug es el actual
+}
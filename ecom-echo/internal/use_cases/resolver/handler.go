@@ -0,0 +1,56 @@
+// handler.go
+package resolver
+
+import (
+	"ecom/internal/shared/errors"
+	"ecom/internal/shared/response"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(e *echo.Group, s *Service) {
+	h := &Handler{service: s}
+	e.GET("/:slug", h.Resolve)
+}
+
+// Resolve devuelve la entidad del slug o un 301 con Location hacia el slug actual
+func (h *Handler) Resolve(c echo.Context) error {
+	result, err := h.service.Resolve(c.Request().Context(), c.Param("slug"), c.QueryParam("currency"))
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	if result.Redirect {
+			result.Location = strings.Replace(c.Path(), ":slug", url.PathEscape(result.Slug), 1)
+			if query := c.QueryString(); query != "" {
+					result.Location += "?" + query
+			}
+			c.Response().Header().Set(echo.HeaderLocation, result.Location)
+			return c.JSON(http.StatusMovedPermanently, 
+					response.Success("El slug ha cambiado", result))
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Slug resuelto exitosamente", result))
+}
+
+func (h *Handler) handleError(c echo.Context, err error) error {
+	switch e := err.(type) {
+	case *errors.AppError:
+			return c.JSON(e.Code, response.Error(e.Message, nil))
+	default:
+			if errors.IsNotFound(err) {
+					return c.JSON(http.StatusNotFound, 
+							response.Error("Recurso no encontrado", nil))
+			}
+			return c.JSON(http.StatusInternalServerError, 
+					response.Error("Error interno del servidor", nil))
+	}
+}
@@ -0,0 +1,63 @@
+// repository.go
+package resolver
+
+import (
+	"context"
+	"database/sql"
+	"ecom/internal/shared/errors"
+)
+
+type Repository interface {
+	FindBySlug(ctx context.Context, value string) (*Match, error)
+}
+
+type MySQLRepository struct {
+	db *sql.DB
+}
+
+func NewMySQLRepository(db *sql.DB) Repository {
+	return &MySQLRepository{db: db}
+}
+
+const (
+	querySlugCurrent = `
+		SELECT 'product', id, slug FROM products WHERE slug = ? AND deleted_at IS NULL
+		UNION ALL
+		SELECT 'category', id, slug FROM categories WHERE slug = ? AND deleted_at IS NULL
+		LIMIT 1
+	`
+
+	// Slug antiguo de una entidad que sigue existiendo, con su slug actual
+	querySlugHistory = `
+		SELECT h.entity_type, h.entity_id, COALESCE(p.slug, c.slug)
+		FROM slug_history h
+		LEFT JOIN products p ON h.entity_type = 'product' AND p.id = h.entity_id AND p.deleted_at IS NULL
+		LEFT JOIN categories c ON h.entity_type = 'category' AND c.id = h.entity_id AND c.deleted_at IS NULL
+		WHERE h.slug = ? AND COALESCE(p.slug, c.slug) IS NOT NULL
+		ORDER BY h.entity_type = 'product' DESC
+		LIMIT 1
+	`
+)
+
+// FindBySlug busca el slug entre los actuales de productos y categorías (los productos primero)
+// y, si no está, en el historial de slugs antiguos
+func (r *MySQLRepository) FindBySlug(ctx context.Context, value string) (*Match, error) {
+	m := &Match{}
+	err := r.db.QueryRowContext(ctx, querySlugCurrent, value, value).Scan(&m.EntityType, &m.EntityID, &m.Slug)
+	if err == nil {
+		return m, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, errors.NewMysqlError(err)
+	}
+
+	err = r.db.QueryRowContext(ctx, querySlugHistory, value).Scan(&m.EntityType, &m.EntityID, &m.Slug)
+	if err == sql.ErrNoRows {
+		return nil, errors.NewNotFoundError("No hay ningún producto ni categoría con ese slug")
+	}
+	if err != nil {
+		return nil, errors.NewMysqlError(err)
+	}
+	m.Redirect = true
+	return m, nil
+}
@@ -0,0 +1,51 @@
+// service.go
+package resolver
+
+import (
+	"context"
+	"ecom/internal/shared/slug"
+	"ecom/internal/use_cases/category"
+	"ecom/internal/use_cases/product"
+)
+
+type Service struct {
+	repo            Repository
+	productService  *product.Service
+	categoryService *category.Service
+}
+
+func NewService(repo Repository, productService *product.Service, categoryService *category.Service) *Service {
+	return &Service{repo: repo, productService: productService, categoryService: categoryService}
+}
+
+// Resolve devuelve la entidad del slug actual o, si el slug es antiguo, el slug al que redirigir.
+// Los productos que no están visibles en la tienda dan 404 también por sus slugs antiguos.
+// currencyCode se usa para los precios del producto
+func (s *Service) Resolve(ctx context.Context, value, currencyCode string) (*ResolveResponse, error) {
+	match, err := s.repo.FindBySlug(ctx, value)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ResolveResponse{
+		Type:     match.EntityType,
+		ID:       match.EntityID,
+		Slug:     match.Slug,
+		Redirect: match.Redirect,
+	}
+
+	var entity interface{}
+	switch match.EntityType {
+	case slug.EntityProduct:
+		entity, err = s.productService.GetPublishedByID(ctx, match.EntityID, currencyCode)
+	case slug.EntityCategory:
+		entity, err = s.categoryService.GetByID(ctx, match.EntityID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !match.Redirect {
+		resp.Entity = entity
+	}
+	return resp, nil
+}
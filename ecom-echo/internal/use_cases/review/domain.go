@@ -0,0 +1,37 @@
+// domain.go
+package review
+
+import "time"
+
+// Estados de moderación de una reseña. Sólo las aprobadas son públicas y cuentan en la media
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+)
+
+type Review struct {
+	ID               int64      `json:"id"`
+	IDProduct        int64      `json:"id_product"`
+	IDCustomer       int64      `json:"id_customer"`
+	CustomerName     string     `json:"customer_name"`
+	Rating           int        `json:"rating"`
+	Title            string     `json:"title"`
+	Body             string     `json:"body"`
+	VerifiedPurchase bool       `json:"verified_purchase"` // El cliente tiene un pedido no cancelado con el producto
+	Status           string     `json:"status"`
+	ModerationNote   string     `json:"moderation_note,omitempty"`
+	ModeratedAt      *time.Time `json:"moderated_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// ReviewFilter filtros del listado de reseñas
+type ReviewFilter struct {
+	IDProduct *int64
+	Status    string
+	Rating    *int
+	Sort      string
+	Page      int
+	PerPage   int
+}
@@ -0,0 +1,48 @@
+// dto.go
+package review
+
+const (
+	SortNewest  = "newest"
+	SortOldest  = "oldest"
+	SortHighest = "highest"
+	SortLowest  = "lowest"
+)
+
+type CreateReviewRequest struct {
+	IDProduct  int64  `json:"id_product" validate:"required"`
+	IDCustomer int64  `json:"id_customer" validate:"required"`
+	Rating     int    `json:"rating" validate:"required,min=1,max=5"`
+	Title      string `json:"title" validate:"required,min=2,max=200"`
+	Body       string `json:"body" validate:"omitempty,max=5000"`
+}
+
+// ModerateReviewRequest nota opcional del moderador (por ejemplo, el motivo del rechazo)
+type ModerateReviewRequest struct {
+	Note string `json:"note" validate:"omitempty,max=255"`
+}
+
+// ProductReviewsRequest listado público de las reseñas aprobadas de un producto
+type ProductReviewsRequest struct {
+	Page    int    `query:"page" validate:"min=1"`
+	PerPage int    `query:"per_page" validate:"min=1,max=100"`
+	Rating  *int   `query:"rating" validate:"omitempty,min=1,max=5"`
+	Sort    string `query:"sort" validate:"omitempty,oneof=newest oldest highest lowest"`
+}
+
+// ModerationQueueRequest reseñas por estado; por defecto las pendientes, las más antiguas primero
+type ModerationQueueRequest struct {
+	Page      int    `query:"page" validate:"min=1"`
+	PerPage   int    `query:"per_page" validate:"min=1,max=100"`
+	Status    string `query:"status" validate:"omitempty,oneof=pending approved rejected"`
+	IDProduct *int64 `query:"id_product"`
+}
+
+type ProductReviewsResponse struct {
+	Items         []Review      `json:"items"`
+	Total         int64         `json:"total"`
+	Page          int           `json:"page"`
+	PerPage       int           `json:"per_page"`
+	AverageRating float64       `json:"average_rating"`
+	ReviewCount   int           `json:"review_count"`
+	Distribution  map[int]int64 `json:"distribution"` // Reseñas aprobadas por número de estrellas
+}
@@ -0,0 +1,188 @@
+// handler.go
+package review
+
+import (
+	"ecom/internal/shared/errors"
+	"ecom/internal/shared/response"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(e *echo.Group, s *Service) {
+	h := &Handler{service: s}
+
+	e.POST("", h.Create)
+	e.GET("/moderation", h.ModerationQueue)
+	e.GET("/product/:id", h.ListForProduct)
+	e.GET("/:id", h.GetByID)
+	e.PUT("/:id/approve", h.Approve)
+	e.PUT("/:id/reject", h.Reject)
+	e.DELETE("/:id", h.Delete)
+}
+
+func (h *Handler) Create(c echo.Context) error {
+	var req CreateReviewRequest
+	if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error al procesar la solicitud", err.Error()))
+	}
+
+	if err := c.Validate(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error de validación", err.Error()))
+	}
+
+	review, err := h.service.Create(c.Request().Context(), &req)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, 
+			response.Success("Reseña enviada, pendiente de moderación", review))
+}
+
+func (h *Handler) GetByID(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID inválido", err.Error()))
+	}
+
+	review, err := h.service.GetByID(c.Request().Context(), id)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Reseña obtenida exitosamente", review))
+}
+
+func (h *Handler) Approve(c echo.Context) error {
+	return h.moderate(c, true)
+}
+
+func (h *Handler) Reject(c echo.Context) error {
+	return h.moderate(c, false)
+}
+
+func (h *Handler) moderate(c echo.Context, approve bool) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID inválido", err.Error()))
+	}
+
+	var req ModerateReviewRequest
+	if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error al procesar la solicitud", err.Error()))
+	}
+
+	if err := c.Validate(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error de validación", err.Error()))
+	}
+
+	var review *Review
+	message := "Reseña aprobada exitosamente"
+	if approve {
+			review, err = h.service.Approve(c.Request().Context(), id, &req)
+	} else {
+			review, err = h.service.Reject(c.Request().Context(), id, &req)
+			message = "Reseña rechazada exitosamente"
+	}
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, response.Success(message, review))
+}
+
+func (h *Handler) Delete(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID inválido", err.Error()))
+	}
+
+	if err := h.service.Delete(c.Request().Context(), id); err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Reseña eliminada exitosamente", nil))
+}
+
+func (h *Handler) ListForProduct(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("ID inválido", err.Error()))
+	}
+
+	req := ProductReviewsRequest{Page: 1, PerPage: 10}
+	if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error en paginación", err.Error()))
+	}
+
+	if err := c.Validate(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error de validación", err.Error()))
+	}
+
+	result, err := h.service.ListForProduct(c.Request().Context(), id, &req)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Reseñas obtenidas exitosamente", result))
+}
+
+func (h *Handler) ModerationQueue(c echo.Context) error {
+	req := ModerationQueueRequest{Page: 1, PerPage: 20}
+	if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error en paginación", err.Error()))
+	}
+
+	if err := c.Validate(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error de validación", err.Error()))
+	}
+
+	reviews, total, err := h.service.ModerationQueue(c.Request().Context(), &req)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Reseñas obtenidas exitosamente", 
+					map[string]interface{}{
+							"items":    reviews,
+							"total":    total,
+							"page":     req.Page,
+							"per_page": req.PerPage,
+					}))
+}
+
+func (h *Handler) handleError(c echo.Context, err error) error {
+	switch e := err.(type) {
+	case *errors.AppError:
+			return c.JSON(e.Code, response.Error(e.Message, nil))
+	default:
+			if errors.IsNotFound(err) {
+					return c.JSON(http.StatusNotFound, 
+							response.Error("Recurso no encontrado", nil))
+			}
+			return c.JSON(http.StatusInternalServerError, 
+					response.Error("Error interno del servidor", nil))
+	}
+}
@@ -0,0 +1,303 @@
+// repository.go
+package review
+
+import (
+	"context"
+	"database/sql"
+	"ecom/internal/shared/errors"
+)
+
+type Repository interface {
+	Create(ctx context.Context, r *Review) error
+	GetByID(ctx context.Context, id int64) (*Review, error)
+	List(ctx context.Context, f *ReviewFilter) ([]Review, int64, error)
+	Moderate(ctx context.Context, id int64, status, note string) error
+	Delete(ctx context.Context, id int64) error
+
+	ProductExists(ctx context.Context, productID int64) (bool, error)
+	CustomerExists(ctx context.Context, customerID int64) (bool, error)
+	HasReviewed(ctx context.Context, productID, customerID int64) (bool, error)
+	HasPurchased(ctx context.Context, productID, customerID int64) (bool, error)
+	GetProductRating(ctx context.Context, productID int64) (float64, int, error)
+	GetRatingDistribution(ctx context.Context, productID int64) (map[int]int64, error)
+}
+
+type MySQLRepository struct {
+	db *sql.DB
+}
+
+func NewMySQLRepository(db *sql.DB) Repository {
+	return &MySQLRepository{db: db}
+}
+
+const (
+	querySelectReview = `
+		SELECT r.id, r.id_product, r.id_customer, TRIM(CONCAT(cu.name, ' ', COALESCE(cu.last_name, ''))),
+			r.rating, r.title, COALESCE(r.body, ''), r.verified_purchase, r.status,
+			COALESCE(r.moderation_note, ''), r.moderated_at, r.created_at, r.updated_at
+		FROM product_reviews r
+		INNER JOIN customers cu ON cu.id = r.id_customer
+	`
+
+	// Un cliente ha comprado el producto si tiene algún pedido no cancelado con una de sus variantes
+	queryHasPurchased = `
+		SELECT EXISTS(
+			SELECT 1 FROM order_items oi
+			INNER JOIN orders o ON o.id = oi.id_order
+			INNER JOIN product_variants v ON v.id = oi.id_product_variant
+			WHERE o.id_customer = ? AND v.id_product = ?
+				AND o.deleted_at IS NULL AND o.cancelled_at IS NULL
+		)
+	`
+
+	// Recalcula la media y el número de reseñas aprobadas del producto
+	queryRefreshProductRating = `
+		UPDATE products p
+		LEFT JOIN (
+			SELECT id_product, ROUND(AVG(rating), 2) AS average, COUNT(*) AS total
+			FROM product_reviews
+			WHERE id_product = ? AND status = 'approved'
+			GROUP BY id_product
+		) r ON r.id_product = p.id
+		SET p.rating_average = COALESCE(r.average, 0), p.rating_count = COALESCE(r.total, 0)
+		WHERE p.id = ?
+	`
+)
+
+func (r *MySQLRepository) Create(ctx context.Context, rv *Review) error {
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO product_reviews (id_product, id_customer, rating, title, body, verified_purchase, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
+	`, rv.IDProduct, rv.IDCustomer, rv.Rating, rv.Title, rv.Body, rv.VerifiedPurchase, rv.Status)
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return errors.NewInternalError("Error al obtener el id creado", err)
+	}
+	rv.ID = id
+	return nil
+}
+
+func (r *MySQLRepository) GetByID(ctx context.Context, id int64) (*Review, error) {
+	rv, err := scanReview(r.db.QueryRowContext(ctx, querySelectReview+" WHERE r.id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, errors.NewNotFoundError("Reseña no encontrada")
+	}
+	if err != nil {
+		return nil, errors.NewMysqlError(err)
+	}
+	return rv, nil
+}
+
+func (r *MySQLRepository) List(ctx context.Context, f *ReviewFilter) ([]Review, int64, error) {
+	where := " WHERE 1 = 1"
+	args := []interface{}{}
+
+	if f.IDProduct != nil {
+		where += " AND r.id_product = ?"
+		args = append(args, *f.IDProduct)
+	}
+	if f.Status != "" {
+		where += " AND r.status = ?"
+		args = append(args, f.Status)
+	}
+	if f.Rating != nil {
+		where += " AND r.rating = ?"
+		args = append(args, *f.Rating)
+	}
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM product_reviews r"+where, args...).Scan(&total); err != nil {
+		return nil, 0, errors.NewMysqlError(err)
+	}
+
+	query := querySelectReview + where + " ORDER BY " + reviewOrderBy(f.Sort) + " LIMIT ? OFFSET ?"
+	args = append(args, f.PerPage, (f.Page-1)*f.PerPage)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, errors.NewMysqlError(err)
+	}
+	defer rows.Close()
+
+	reviews := []Review{}
+	for rows.Next() {
+		rv, err := scanReview(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		reviews = append(reviews, *rv)
+	}
+	return reviews, total, rows.Err()
+}
+
+// Moderate cambia el estado de la reseña y recalcula la valoración del producto en la misma transacción
+func (r *MySQLRepository) Moderate(ctx context.Context, id int64, status, note string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	defer tx.Rollback()
+
+	productID, err := lockReview(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE product_reviews
+		SET status = ?, moderation_note = NULLIF(?, ''), moderated_at = NOW(), updated_at = NOW()
+		WHERE id = ?
+	`, status, note, id); err != nil {
+		return errors.NewMysqlError(err)
+	}
+
+	if _, err := tx.ExecContext(ctx, queryRefreshProductRating, productID, productID); err != nil {
+		return errors.NewMysqlError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.NewInternalError("Error al moderar la reseña", err)
+	}
+	return nil
+}
+
+func (r *MySQLRepository) Delete(ctx context.Context, id int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.NewMysqlError(err)
+	}
+	defer tx.Rollback()
+
+	productID, err := lockReview(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM product_reviews WHERE id = ?", id); err != nil {
+		return errors.NewMysqlError(err)
+	}
+
+	if _, err := tx.ExecContext(ctx, queryRefreshProductRating, productID, productID); err != nil {
+		return errors.NewMysqlError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.NewInternalError("Error al eliminar la reseña", err)
+	}
+	return nil
+}
+
+func (r *MySQLRepository) ProductExists(ctx context.Context, productID int64) (bool, error) {
+	return r.exists(ctx, "SELECT EXISTS(SELECT 1 FROM products WHERE id = ? AND deleted_at IS NULL)", productID)
+}
+
+func (r *MySQLRepository) CustomerExists(ctx context.Context, customerID int64) (bool, error) {
+	return r.exists(ctx, "SELECT EXISTS(SELECT 1 FROM customers WHERE id = ? AND deleted_at IS NULL)", customerID)
+}
+
+func (r *MySQLRepository) HasReviewed(ctx context.Context, productID, customerID int64) (bool, error) {
+	return r.exists(ctx, "SELECT EXISTS(SELECT 1 FROM product_reviews WHERE id_product = ? AND id_customer = ?)", productID, customerID)
+}
+
+func (r *MySQLRepository) HasPurchased(ctx context.Context, productID, customerID int64) (bool, error) {
+	return r.exists(ctx, queryHasPurchased, customerID, productID)
+}
+
+// GetProductRating devuelve la media y el número de reseñas aprobadas del producto
+func (r *MySQLRepository) GetProductRating(ctx context.Context, productID int64) (float64, int, error) {
+	var average float64
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT rating_average, rating_count FROM products WHERE id = ? AND deleted_at IS NULL
+	`, productID).Scan(&average, &count)
+	if err == sql.ErrNoRows {
+		return 0, 0, errors.NewNotFoundError("Producto no encontrado")
+	}
+	if err != nil {
+		return 0, 0, errors.NewMysqlError(err)
+	}
+	return average, count, nil
+}
+
+// GetRatingDistribution devuelve el número de reseñas aprobadas por estrellas, de 1 a 5
+func (r *MySQLRepository) GetRatingDistribution(ctx context.Context, productID int64) (map[int]int64, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT rating, COUNT(*) FROM product_reviews
+		WHERE id_product = ? AND status = 'approved'
+		GROUP BY rating
+	`, productID)
+	if err != nil {
+		return nil, errors.NewMysqlError(err)
+	}
+	defer rows.Close()
+
+	distribution := map[int]int64{1: 0, 2: 0, 3: 0, 4: 0, 5: 0}
+	for rows.Next() {
+		var rating int
+		var count int64
+		if err := rows.Scan(&rating, &count); err != nil {
+			return nil, err
+		}
+		distribution[rating] = count
+	}
+	return distribution, rows.Err()
+}
+
+func (r *MySQLRepository) exists(ctx context.Context, query string, args ...interface{}) (bool, error) {
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&exists); err != nil {
+		return false, errors.NewMysqlError(err)
+	}
+	return exists, nil
+}
+
+// lockReview bloquea la reseña dentro de la transacción y devuelve su producto
+func lockReview(ctx context.Context, tx *sql.Tx, id int64) (int64, error) {
+	var productID int64
+	err := tx.QueryRowContext(ctx, "SELECT id_product FROM product_reviews WHERE id = ? FOR UPDATE", id).Scan(&productID)
+	if err == sql.ErrNoRows {
+		return 0, errors.NewNotFoundError("Reseña no encontrada")
+	}
+	if err != nil {
+		return 0, errors.NewMysqlError(err)
+	}
+	return productID, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanReview(row rowScanner) (*Review, error) {
+	rv := &Review{}
+	var moderatedAt sql.NullTime
+	err := row.Scan(
+		&rv.ID, &rv.IDProduct, &rv.IDCustomer, &rv.CustomerName,
+		&rv.Rating, &rv.Title, &rv.Body, &rv.VerifiedPurchase, &rv.Status,
+		&rv.ModerationNote, &moderatedAt, &rv.CreatedAt, &rv.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if moderatedAt.Valid {
+		rv.ModeratedAt = &moderatedAt.Time
+	}
+	return rv, nil
+}
+
+func reviewOrderBy(sort string) string {
+	switch sort {
+	case SortOldest:
+		return "r.created_at ASC, r.id ASC"
+	case SortHighest:
+		return "r.rating DESC, r.created_at DESC"
+	case SortLowest:
+		return "r.rating ASC, r.created_at DESC"
+	default:
+		return "r.created_at DESC, r.id DESC"
+	}
+}
@@ -0,0 +1,139 @@
+// service.go
+package review
+
+import (
+	"context"
+	"ecom/internal/shared/errors"
+)
+
+type Service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Create registra la reseña pendiente de moderación. La compra verificada se deduce
+// de los pedidos del cliente
+func (s *Service) Create(ctx context.Context, req *CreateReviewRequest) (*Review, error) {
+	exists, err := s.repo.ProductExists(ctx, req.IDProduct)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFoundError("Producto no encontrado")
+	}
+
+	exists, err = s.repo.CustomerExists(ctx, req.IDCustomer)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFoundError("Cliente no encontrado")
+	}
+
+	reviewed, err := s.repo.HasReviewed(ctx, req.IDProduct, req.IDCustomer)
+	if err != nil {
+		return nil, err
+	}
+	if reviewed {
+		return nil, errors.NewConflictError("El cliente ya ha valorado este producto")
+	}
+
+	verified, err := s.repo.HasPurchased(ctx, req.IDProduct, req.IDCustomer)
+	if err != nil {
+		return nil, err
+	}
+
+	review := &Review{
+		IDProduct:        req.IDProduct,
+		IDCustomer:       req.IDCustomer,
+		Rating:           req.Rating,
+		Title:            req.Title,
+		Body:             req.Body,
+		VerifiedPurchase: verified,
+		Status:           StatusPending,
+	}
+	if err := s.repo.Create(ctx, review); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(ctx, review.ID)
+}
+
+func (s *Service) GetByID(ctx context.Context, id int64) (*Review, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// Approve publica la reseña y la incluye en la valoración del producto
+func (s *Service) Approve(ctx context.Context, id int64, req *ModerateReviewRequest) (*Review, error) {
+	return s.moderate(ctx, id, StatusApproved, req.Note)
+}
+
+// Reject oculta la reseña y la excluye de la valoración del producto
+func (s *Service) Reject(ctx context.Context, id int64, req *ModerateReviewRequest) (*Review, error) {
+	return s.moderate(ctx, id, StatusRejected, req.Note)
+}
+
+func (s *Service) moderate(ctx context.Context, id int64, status, note string) (*Review, error) {
+	if err := s.repo.Moderate(ctx, id, status, note); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *Service) Delete(ctx context.Context, id int64) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// ListForProduct devuelve las reseñas aprobadas del producto con su valoración agregada
+func (s *Service) ListForProduct(ctx context.Context, productID int64, req *ProductReviewsRequest) (*ProductReviewsResponse, error) {
+	average, count, err := s.repo.GetProductRating(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	reviews, total, err := s.repo.List(ctx, &ReviewFilter{
+		IDProduct: &productID,
+		Status:    StatusApproved,
+		Rating:    req.Rating,
+		Sort:      req.Sort,
+		Page:      req.Page,
+		PerPage:   req.PerPage,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	distribution, err := s.repo.GetRatingDistribution(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProductReviewsResponse{
+		Items:         reviews,
+		Total:         total,
+		Page:          req.Page,
+		PerPage:       req.PerPage,
+		AverageRating: average,
+		ReviewCount:   count,
+		Distribution:  distribution,
+	}, nil
+}
+
+// ModerationQueue devuelve las reseñas del estado pedido (pendientes por defecto), las más antiguas primero
+func (s *Service) ModerationQueue(ctx context.Context, req *ModerationQueueRequest) ([]Review, int64, error) {
+	status := req.Status
+	if status == "" {
+		status = StatusPending
+	}
+
+	return s.repo.List(ctx, &ReviewFilter{
+		IDProduct: req.IDProduct,
+		Status:    status,
+		Sort:      SortOldest,
+		Page:      req.Page,
+		PerPage:   req.PerPage,
+	})
+}
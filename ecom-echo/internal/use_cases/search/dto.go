@@ -0,0 +1,39 @@
+// dto.go
+package search
+
+import "ecom/internal/shared/searchindex"
+
+type SearchRequest struct {
+	Query   string `query:"q" validate:"required,max=200"`
+	Page    int    `query:"page" validate:"min=1"`
+	PerPage int    `query:"per_page" validate:"min=1,max=100"`
+}
+
+type SuggestRequest struct {
+	Query string `query:"q" validate:"required,max=200"`
+	Limit int    `query:"limit" validate:"min=1,max=20"`
+}
+
+type SearchItem struct {
+	ID    int64   `json:"id"`
+	Name  string  `json:"name"`
+	Slug  string  `json:"slug"`
+	Score float64 `json:"score"`
+}
+
+type SearchResponse struct {
+	Items       []SearchItem      `json:"items"`
+	Total       int               `json:"total"`
+	Page        int               `json:"page"`
+	PerPage     int               `json:"per_page"`
+	Corrections map[string]string `json:"corrections,omitempty"` // término escrito -> término usado
+}
+
+type SuggestResponse struct {
+	Suggestions []searchindex.Suggestion `json:"suggestions"`
+}
+
+type RebuildResponse struct {
+	Indexed  int    `json:"indexed"`
+	Duration string `json:"duration"`
+}
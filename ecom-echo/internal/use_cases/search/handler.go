@@ -0,0 +1,83 @@
+// handler.go
+package search
+
+import (
+	"ecom/internal/shared/errors"
+	"ecom/internal/shared/response"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(e *echo.Group, s *Service) {
+	h := &Handler{service: s}
+	e.GET("", h.Search)
+	e.GET("/suggest", h.Suggest)
+	e.POST("/rebuild", h.Rebuild)
+}
+
+func (h *Handler) Search(c echo.Context) error {
+	req := SearchRequest{Page: 1, PerPage: 20}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, 
+					response.Error("Error al procesar la solicitud", err.Error()))
+	}
+
+	if err := c.Validate(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error de validación", err.Error()))
+	}
+
+	result, err := h.service.Search(c.Request().Context(), &req)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Búsqueda realizada exitosamente", result))
+}
+
+func (h *Handler) Suggest(c echo.Context) error {
+	req := SuggestRequest{Limit: 5}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, 
+					response.Error("Error al procesar la solicitud", err.Error()))
+	}
+
+	if err := c.Validate(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, 
+					response.Error("Error de validación", err.Error()))
+	}
+
+	result, err := h.service.Suggest(c.Request().Context(), &req)
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Sugerencias obtenidas exitosamente", result))
+}
+
+func (h *Handler) Rebuild(c echo.Context) error {
+	result, err := h.service.Rebuild(c.Request().Context())
+	if err != nil {
+			return h.handleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, 
+			response.Success("Índice de búsqueda reconstruido exitosamente", result))
+}
+
+func (h *Handler) handleError(c echo.Context, err error) error {
+	switch e := err.(type) {
+	case *errors.AppError:
+			return c.JSON(e.Code, response.Error(e.Message, nil))
+	default:
+			return c.JSON(http.StatusInternalServerError, 
+					response.Error("Error interno del servidor", nil))
+	}
+}
@@ -0,0 +1,294 @@
+// service.go
+package search
+
+import (
+	"context"
+	"ecom/internal/shared/errors"
+	"ecom/internal/shared/searchindex"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	category "ecom/internal/use_cases/category"
+	product "ecom/internal/use_cases/product"
+)
+
+// Service mantiene el índice de búsqueda del catálogo sincronizado con la base de datos.
+// Implementa los ChangeListener de productos, categorías y tags
+type Service struct {
+	index        searchindex.SearchIndex
+	productRepo  product.Repository
+	categoryRepo category.Repository
+
+	rebuildMu sync.Mutex // Una sola reconstrucción a la vez
+
+	// Mientras se reconstruye, los productos que cambian se anotan en pending y se vuelven a
+	// indexar después de Replace, que si no los sobrescribiría con lo leído antes del cambio
+	pendingMu  sync.Mutex
+	rebuilding bool
+	pending    map[int64]bool
+}
+
+func NewService(index searchindex.SearchIndex, productRepo product.Repository, categoryRepo category.Repository) *Service {
+	return &Service{index: index, productRepo: productRepo, categoryRepo: categoryRepo}
+}
+
+func (s *Service) Search(ctx context.Context, req *SearchRequest) (*SearchResponse, error) {
+	result, err := s.index.Search(req.Query, req.PerPage, (req.Page-1)*req.PerPage)
+	if err != nil {
+		return nil, errors.NewInternalError("Error al buscar en el índice", err)
+	}
+
+	// Los productos cuya retirada ya pasó salen del índice cuando el programador de publicación
+	// los archiva; hasta entonces se descartan aquí
+	now := time.Now()
+	items := make([]SearchItem, 0, len(result.Hits))
+	for _, h := range result.Hits {
+		if expired(h.Stored["unpublish_at"], now) {
+			result.Total--
+			continue
+		}
+		items = append(items, SearchItem{ID: h.ID, Name: h.Title, Slug: h.Stored["slug"], Score: h.Score})
+	}
+
+	return &SearchResponse{
+		Items:       items,
+		Total:       result.Total,
+		Page:        req.Page,
+		PerPage:     req.PerPage,
+		Corrections: result.Corrections,
+	}, nil
+}
+
+func (s *Service) Suggest(ctx context.Context, req *SuggestRequest) (*SuggestResponse, error) {
+	suggestions, err := s.index.Suggest(req.Query, req.Limit)
+	if err != nil {
+		return nil, errors.NewInternalError("Error al obtener sugerencias", err)
+	}
+	return &SuggestResponse{Suggestions: suggestions}, nil
+}
+
+// Rebuild vuelve a indexar todos los productos visibles en la tienda
+func (s *Service) Rebuild(ctx context.Context) (*RebuildResponse, error) {
+	s.rebuildMu.Lock()
+	defer s.rebuildMu.Unlock()
+
+	start := time.Now()
+	s.pendingMu.Lock()
+	s.rebuilding = true
+	s.pending = make(map[int64]bool)
+	s.pendingMu.Unlock()
+
+	docs, err := s.snapshot(ctx)
+	if err == nil {
+		if err = s.index.Replace(docs); err != nil {
+			err = errors.NewInternalError("Error al reconstruir el índice de búsqueda", err)
+		}
+	}
+
+	s.pendingMu.Lock()
+	s.rebuilding = false
+	pending := make([]int64, 0, len(s.pending))
+	for id := range s.pending {
+		pending = append(pending, id)
+	}
+	s.pending = nil
+	s.pendingMu.Unlock()
+
+	// Los cambios llegados durante la reconstrucción ya se aplicaron al índice anterior;
+	// se vuelven a leer para que Replace no los deje atrás
+	s.reindex(ctx, pending)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RebuildResponse{Indexed: len(docs), Duration: time.Since(start).Round(time.Millisecond).String()}, nil
+}
+
+// RebuildIfEmpty reconstruye el índice si está vacío (primer arranque o cambio de formato)
+func (s *Service) RebuildIfEmpty(ctx context.Context) {
+	if s.index.Count() > 0 {
+		return
+	}
+
+	go func() {
+		result, err := s.Rebuild(ctx)
+		if err != nil {
+			log.Printf("Error al construir el índice de búsqueda: %v", err)
+			return
+		}
+		log.Printf("Índice de búsqueda construido: %d productos en %s", result.Indexed, result.Duration)
+	}()
+}
+
+// snapshot construye los documentos de todos los productos para reconstruir el índice
+func (s *Service) snapshot(ctx context.Context) ([]searchindex.Document, error) {
+	ids, err := s.productRepo.ListProductIDs(ctx, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	docs, _, err := s.buildDocuments(ctx, ids)
+	return docs, err
+}
+
+// track anota los productos que cambian mientras se reconstruye el índice
+func (s *Service) track(ids ...int64) {
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+	if !s.rebuilding {
+		return
+	}
+	for _, id := range ids {
+		s.pending[id] = true
+	}
+}
+
+func (s *Service) ProductChanged(ctx context.Context, id int64) {
+	s.reindex(ctx, []int64{id})
+}
+
+func (s *Service) ProductDeleted(ctx context.Context, id int64) {
+	s.track(id)
+	if err := s.index.Delete(id); err != nil {
+		log.Printf("Error al retirar el producto %d del índice de búsqueda: %v", id, err)
+	}
+}
+
+// CategoryChanged reindexa los productos de la categoría y de sus subcategorías,
+// ya que la ruta de categorías forma parte del documento
+func (s *Service) CategoryChanged(ctx context.Context, id int64) {
+	categories, err := s.categoryRepo.ListAll(ctx)
+	if err != nil {
+		log.Printf("Error al reindexar la categoría %d: %v", id, err)
+		return
+	}
+
+	children := make(map[int64][]int64)
+	for _, c := range categories {
+		if c.ParentID != nil {
+			children[*c.ParentID] = append(children[*c.ParentID], c.ID)
+		}
+	}
+	subtree := []int64{id}
+	for i := 0; i < len(subtree); i++ {
+		subtree = append(subtree, children[subtree[i]]...)
+	}
+
+	ids, err := s.productRepo.ListProductIDs(ctx, subtree, nil)
+	if err != nil {
+		log.Printf("Error al reindexar la categoría %d: %v", id, err)
+		return
+	}
+	s.reindex(ctx, ids)
+}
+
+func (s *Service) TagChanged(ctx context.Context, id int64) {
+	ids, err := s.productRepo.ListProductIDs(ctx, nil, &id)
+	if err != nil {
+		log.Printf("Error al reindexar el tag %d: %v", id, err)
+		return
+	}
+	s.reindex(ctx, ids)
+}
+
+// reindex actualiza los productos indicados. Los errores se registran pero no se
+// propagan: la operación en la base de datos ya se completó
+func (s *Service) reindex(ctx context.Context, ids []int64) {
+	if len(ids) == 0 {
+		return
+	}
+	s.track(ids...)
+
+	docs, removed, err := s.buildDocuments(ctx, ids)
+	if err != nil {
+		log.Printf("Error al preparar los productos %v para el índice de búsqueda: %v", ids, err)
+		return
+	}
+	if len(docs) > 0 {
+		if err := s.index.Index(docs...); err != nil {
+			log.Printf("Error al indexar los productos %v: %v", ids, err)
+		}
+	}
+	if len(removed) > 0 {
+		if err := s.index.Delete(removed...); err != nil {
+			log.Printf("Error al retirar los productos %v del índice de búsqueda: %v", removed, err)
+		}
+	}
+}
+
+// buildDocuments carga los productos y devuelve los documentos de los visibles en la tienda
+// y los ids de los que ya no deben aparecer en el índice
+func (s *Service) buildDocuments(ctx context.Context, ids []int64) ([]searchindex.Document, []int64, error) {
+	now := time.Now()
+	var docs []searchindex.Document
+	var removed []int64
+	paths := make(map[int64][]string)
+
+	for _, id := range ids {
+		p, err := s.productRepo.GetByID(ctx, id)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				removed = append(removed, id)
+				continue
+			}
+			return nil, nil, err
+		}
+		// Mismo criterio que la tienda: publicado y dentro de sus fechas de publicación
+		if !p.IsPublishedAt(now) {
+			removed = append(removed, id)
+			continue
+		}
+
+		path, ok := paths[p.IDCategory]
+		if !ok && p.IDCategory != 0 {
+			ancestors, err := s.categoryRepo.GetAncestors(ctx, p.IDCategory)
+			if err != nil && !errors.IsNotFound(err) {
+				return nil, nil, err
+			}
+			for _, a := range ancestors {
+				path = append(path, a.Name)
+			}
+			paths[p.IDCategory] = path
+		}
+
+		docs = append(docs, newDocument(p, path))
+	}
+	return docs, removed, nil
+}
+
+func newDocument(p *product.Product, categoryPath []string) searchindex.Document {
+	doc := searchindex.Document{
+		ID:       p.ID,
+		Title:    p.Name,
+		Body:     p.Description,
+		Keywords: append([]string{}, categoryPath...),
+		Stored:   map[string]string{"slug": p.Slug},
+	}
+	if p.UnpublishAt != nil {
+		doc.Stored["unpublish_at"] = p.UnpublishAt.UTC().Format(time.RFC3339)
+	}
+
+	for _, t := range p.Tags {
+		doc.Keywords = append(doc.Keywords, t.Name)
+	}
+	for _, v := range p.Variants {
+		doc.Codes = append(doc.Codes, v.SKU)
+		if !strings.EqualFold(v.Name, p.Name) {
+			doc.Keywords = append(doc.Keywords, v.Name)
+		}
+		for _, av := range v.AttributeValues {
+			doc.Keywords = append(doc.Keywords, av.Name)
+		}
+	}
+	return doc
+}
+
+// expired indica si la fecha de retirada guardada en el documento ya pasó
+func expired(unpublishAt string, now time.Time) bool {
+	if unpublishAt == "" {
+		return false
+	}
+	t, err := time.Parse(time.RFC3339, unpublishAt)
+	return err == nil && !t.After(now)
+}
@@ -6,12 +6,25 @@ import (
 	"time"
 )
 
+// ChangeListener recibe los cambios de tags que afectan a los productos que los usan
+type ChangeListener interface {
+	TagChanged(ctx context.Context, id int64)
+}
+
 type Service struct {
-	repo Repository
+	repo     Repository
+	listener ChangeListener
+}
+
+// NewService crea el servicio; listener puede ser nil
+func NewService(repo Repository, listener ChangeListener) *Service {
+	return &Service{repo: repo, listener: listener}
 }
 
-func NewService(repo Repository) *Service {
-	return &Service{repo: repo}
+func (s *Service) notifyChanged(ctx context.Context, id int64) {
+	if s.listener != nil {
+		s.listener.TagChanged(ctx, id)
+	}
 }
 
 func (s *Service) Create(ctx context.Context, req *CreateTagRequest) (*TagResponse, error) {
@@ -73,6 +86,7 @@ func (s *Service) Update(ctx context.Context, id int64, req *UpdateTagRequest) (
 	if err := s.repo.Update(ctx, tag); err != nil {
 		return nil, err
 	}
+	s.notifyChanged(ctx, id)
 
 	return &TagResponse{
 			ID:        tag.ID,
@@ -85,7 +99,11 @@ func (s *Service) Update(ctx context.Context, id int64, req *UpdateTagRequest) (
 }
 
 func (s *Service) Delete(ctx context.Context, id int64) error {
-	return s.repo.Delete(ctx, id)
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.notifyChanged(ctx, id)
+	return nil
 }
 
 func (s *Service) List(ctx context.Context, p *Pagination) ([]TagResponse, int64, error) {
@@ -1,13 +1,16 @@
 // domain.go
 package taxrate
 
-import "time"
+import (
+	"ecom/internal/shared/money"
+	"time"
+)
 
 type TaxRate struct {
     ID             int64      `json:"id"`
     IDTaxCategory  int64      `json:"id_tax_category"`
     IDTaxZone      int64      `json:"id_tax_zone"`
-    Percentage     float64    `json:"percentage"`
+    Percentage     money.Decimal    `json:"percentage"`
     IsDefault      bool       `json:"is_default"`
     Status         bool       `json:"status"`
     Name           string     `json:"name"`
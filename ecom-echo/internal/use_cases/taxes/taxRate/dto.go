@@ -1,12 +1,15 @@
 // dto.go
 package taxrate
 
-import "time"
+import (
+	"ecom/internal/shared/money"
+	"time"
+)
 
 type CreateTaxRateRequest struct {
 	IDTaxCategory int64   `json:"id_tax_category" validate:"required"`
 	IDTaxZone     int64   `json:"id_tax_zone" validate:"required"`
-	Percentage    float64 `json:"percentage" validate:"required,min=0,max=100"`
+	Percentage    money.Decimal `json:"percentage" validate:"required,min=0,max=100"`
 	IsDefault     bool    `json:"is_default"`
 	Status        bool    `json:"status"`
 	Name          string  `json:"name" validate:"required,min=2,name"`
@@ -16,7 +19,7 @@ type TaxRateResponse struct {
 	ID            int64       `json:"id"`
 	IDTaxCategory int64       `json:"id_tax_category"`
 	IDTaxZone     int64       `json:"id_tax_zone"`
-	Percentage    float64     `json:"percentage"`
+	Percentage    money.Decimal     `json:"percentage"`
 	IsDefault     bool        `json:"is_default"`
 	Status        bool        `json:"status"`
 	Name          string      `json:"name"`
@@ -29,7 +32,7 @@ type TaxRateResponse struct {
 type UpdateTaxRateRequest struct {
 	IDTaxCategory *int64   `json:"id_tax_category,omitempty"`
 	IDTaxZone     *int64   `json:"id_tax_zone,omitempty"`
-	Percentage    *float64 `json:"percentage,omitempty" validate:"omitempty,min=0,max=100"`
+	Percentage    *money.Decimal `json:"percentage,omitempty" validate:"omitempty,min=0,max=100"`
 	IsDefault     *bool    `json:"is_default,omitempty"`
 	Status        *bool    `json:"status,omitempty"`
 	Name          *string  `json:"name,omitempty" validate:"omitempty,min=2,name"`
@@ -1,14 +1,17 @@
 // dto.go
 package taxrule
 
-import "time"
+import (
+	"ecom/internal/shared/money"
+	"time"
+)
 
 type CreateTaxRuleRequest struct {
 	IDTaxRate  int64    `json:"id_tax_rate" validate:"required"`
 	Priority   int      `json:"priority"`
 	Status     bool     `json:"status"`
-	MinAmount  *float64 `json:"min_amount" validate:"omitempty,min=0"`
-	MaxAmount  *float64 `json:"max_amount" validate:"omitempty,min=0,gtfield=MinAmount"`
+	MinAmount  *money.Decimal `json:"min_amount" validate:"omitempty,min=0"`
+	MaxAmount  *money.Decimal `json:"max_amount" validate:"omitempty,min=0,gtfield=MinAmount"`
 }
 
 type TaxRuleResponse struct {
@@ -16,8 +19,8 @@ type TaxRuleResponse struct {
 	IDTaxRate  int64     `json:"id_tax_rate"`
 	Priority   int       `json:"priority"`
 	Status     bool      `json:"status"`
-	MinAmount  *float64  `json:"min_amount"`
-	MaxAmount  *float64  `json:"max_amount"`
+	MinAmount  *money.Decimal  `json:"min_amount"`
+	MaxAmount  *money.Decimal  `json:"max_amount"`
 	TaxRate    *TaxRate  `json:"tax_rate,omitempty"`
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
@@ -27,8 +30,8 @@ type UpdateTaxRuleRequest struct {
 	IDTaxRate  *int64   `json:"id_tax_rate,omitempty"`
 	Priority   *int     `json:"priority,omitempty"`
 	Status     *bool    `json:"status,omitempty"`
-	MinAmount  *float64 `json:"min_amount,omitempty" validate:"omitempty,min=0"`
-	MaxAmount  *float64 `json:"max_amount,omitempty" validate:"omitempty,min=0"`
+	MinAmount  *money.Decimal `json:"min_amount,omitempty" validate:"omitempty,min=0"`
+	MaxAmount  *money.Decimal `json:"max_amount,omitempty" validate:"omitempty,min=0"`
 }
 
 type Pagination struct {
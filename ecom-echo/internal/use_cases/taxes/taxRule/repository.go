@@ -5,6 +5,7 @@ import (
 	"context"
 	"database/sql"
 	"ecom/internal/shared/errors"
+	"ecom/internal/shared/money"
 )
 
 type Repository interface {
@@ -55,7 +56,7 @@ func (r *MySQLRepository) GetByID(ctx context.Context, id int64) (*TaxRule, erro
 	`
 	tr := &TaxRule{TaxRate: &TaxRate{}}
 	
-	var minAmount, maxAmount sql.NullFloat64
+	var minAmount, maxAmount money.NullDecimal
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 			&tr.ID, &tr.IDTaxRate, &tr.Priority, &tr.Status,
 			&minAmount, &maxAmount, &tr.CreatedAt, &tr.UpdatedAt,
@@ -70,10 +71,10 @@ func (r *MySQLRepository) GetByID(ctx context.Context, id int64) (*TaxRule, erro
 	}
 
 	if minAmount.Valid {
-			tr.MinAmount = &minAmount.Float64
+			tr.MinAmount = &minAmount.Decimal
 	}
 	if maxAmount.Valid {
-			tr.MaxAmount = &maxAmount.Float64
+			tr.MaxAmount = &maxAmount.Decimal
 	}
 	
 	return tr, nil
@@ -177,7 +178,7 @@ func (r *MySQLRepository) List(ctx context.Context, p *Pagination) ([]TaxRule, i
 	var rules []TaxRule
 	for rows.Next() {
 			tr := TaxRule{TaxRate: &TaxRate{}}
-			var minAmount, maxAmount sql.NullFloat64
+			var minAmount, maxAmount money.NullDecimal
 			
 			err := rows.Scan(
 					&tr.ID, &tr.IDTaxRate, &tr.Priority, &tr.Status,
@@ -189,10 +190,10 @@ func (r *MySQLRepository) List(ctx context.Context, p *Pagination) ([]TaxRule, i
 			}
 
 			if minAmount.Valid {
-					tr.MinAmount = &minAmount.Float64
+					tr.MinAmount = &minAmount.Decimal
 			}
 			if maxAmount.Valid {
-					tr.MaxAmount = &maxAmount.Float64
+					tr.MaxAmount = &maxAmount.Decimal
 			}
 
 			rules = append(rules, tr)